@@ -5,10 +5,13 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"multifish/middleware"
+	"multifish/scheduler"
+	"multifish/storage"
 	"multifish/utility"
 )
 
@@ -23,6 +26,17 @@ type Config struct {
 	RateLimitBurst    int                       `yaml:"rate_limit_burst" json:"rate_limit_burst"`       // Maximum burst size
 	RateLimitEnabled  bool                      `yaml:"rate_limit_enabled" json:"rate_limit_enabled"`   // Enable/disable rate limiting
 	Auth              *middleware.AuthConfig    `yaml:"auth" json:"auth"`                               // Authentication configuration
+	StorageBackend    string                    `yaml:"storage_backend" json:"storage_backend"`         // Persistence backend: "memory" or "file"
+	DataDir           string                    `yaml:"data_dir" json:"data_dir"`                       // Directory for persisted state (file backend)
+	ExecutionHistoryLimit      int              `yaml:"execution_history_limit" json:"execution_history_limit"`             // Executions retained per job
+	ExecutionHistoryMaxAgeDays int              `yaml:"execution_history_max_age_days" json:"execution_history_max_age_days"` // Days executions are retained (0 = no age limit)
+	MisfirePolicy              string           `yaml:"misfire_policy" json:"misfire_policy"`                               // Late queued runs: "Run", "Coalesce" or "Drop"
+	MisfireThresholdSeconds    int              `yaml:"misfire_threshold_seconds" json:"misfire_threshold_seconds"`         // Queue wait before a run counts as misfired (0 = never)
+	LockPolicy                 string           `yaml:"lock_policy" json:"lock_policy"`                                     // Operations on a locked machine: "Wait", "FailFast" or "Queue"
+	LockWaitTimeoutSeconds     int              `yaml:"lock_wait_timeout_seconds" json:"lock_wait_timeout_seconds"`         // How long the Wait policy waits for a lock (0 = no limit)
+	LockScope                  string           `yaml:"lock_scope" json:"lock_scope"`                                       // What a lock covers: "Machine" or "Manager"
+	ConflictWindowSeconds      int              `yaml:"conflict_window_seconds" json:"conflict_window_seconds"`             // Runs of different jobs on a machine this close conflict
+	ConflictHorizonDays        int              `yaml:"conflict_horizon_days" json:"conflict_horizon_days"`                 // How far ahead RejectConflicts checks a new job (1-31)
 }
 
 // DefaultConfig returns default configuration values
@@ -37,6 +51,17 @@ func DefaultConfig() *Config {
 		RateLimitBurst:   20,    // Allow bursts up to 20 requests
 		RateLimitEnabled: true,  // Rate limiting enabled by default
 		Auth:             middleware.DefaultAuthConfig(), // Authentication disabled by default
+		StorageBackend:   storage.BackendMemory, // Nothing persisted by default
+		DataDir:          "./data",
+		ExecutionHistoryLimit:      50, // Last 50 runs of each job
+		ExecutionHistoryMaxAgeDays: 0,  // No age limit by default
+		MisfirePolicy:              string(scheduler.DefaultMisfirePolicy),
+		MisfireThresholdSeconds:    int(scheduler.DefaultMisfireThreshold / time.Second),
+		LockPolicy:                 string(scheduler.DefaultLockPolicy),
+		LockWaitTimeoutSeconds:     int(scheduler.DefaultLockWaitTimeout / time.Second),
+		LockScope:                  string(scheduler.DefaultLockScope),
+		ConflictWindowSeconds:      int(scheduler.DefaultConflictWindow / time.Second),
+		ConflictHorizonDays:        int(scheduler.DefaultConflictHorizon / (24 * time.Hour)),
 	}
 }
 
@@ -141,6 +166,73 @@ func (c *Config) loadFromEnv() {
 		c.RateLimitEnabled = strings.ToLower(rateLimitEnabled) == "true"
 	}
 
+	// STORAGE_BACKEND
+	if storageBackend := os.Getenv("STORAGE_BACKEND"); storageBackend != "" {
+		c.StorageBackend = storageBackend
+	}
+
+	// DATA_DIR
+	if dataDir := os.Getenv("DATA_DIR"); dataDir != "" {
+		c.DataDir = dataDir
+	}
+
+	// EXECUTION_HISTORY_LIMIT
+	if historyLimit := os.Getenv("EXECUTION_HISTORY_LIMIT"); historyLimit != "" {
+		if l, err := strconv.Atoi(historyLimit); err == nil {
+			c.ExecutionHistoryLimit = l
+		}
+	}
+
+	// EXECUTION_HISTORY_MAX_AGE_DAYS
+	if historyMaxAge := os.Getenv("EXECUTION_HISTORY_MAX_AGE_DAYS"); historyMaxAge != "" {
+		if d, err := strconv.Atoi(historyMaxAge); err == nil {
+			c.ExecutionHistoryMaxAgeDays = d
+		}
+	}
+
+	// MISFIRE_POLICY
+	if misfirePolicy := os.Getenv("MISFIRE_POLICY"); misfirePolicy != "" {
+		c.MisfirePolicy = misfirePolicy
+	}
+
+	// MISFIRE_THRESHOLD_SECONDS
+	if misfireThreshold := os.Getenv("MISFIRE_THRESHOLD_SECONDS"); misfireThreshold != "" {
+		if s, err := strconv.Atoi(misfireThreshold); err == nil {
+			c.MisfireThresholdSeconds = s
+		}
+	}
+
+	// LOCK_POLICY
+	if lockPolicy := os.Getenv("LOCK_POLICY"); lockPolicy != "" {
+		c.LockPolicy = lockPolicy
+	}
+
+	// LOCK_WAIT_TIMEOUT_SECONDS
+	if lockWaitTimeout := os.Getenv("LOCK_WAIT_TIMEOUT_SECONDS"); lockWaitTimeout != "" {
+		if s, err := strconv.Atoi(lockWaitTimeout); err == nil {
+			c.LockWaitTimeoutSeconds = s
+		}
+	}
+
+	// LOCK_SCOPE
+	if lockScope := os.Getenv("LOCK_SCOPE"); lockScope != "" {
+		c.LockScope = lockScope
+	}
+
+	// CONFLICT_WINDOW_SECONDS
+	if conflictWindow := os.Getenv("CONFLICT_WINDOW_SECONDS"); conflictWindow != "" {
+		if s, err := strconv.Atoi(conflictWindow); err == nil {
+			c.ConflictWindowSeconds = s
+		}
+	}
+
+	// CONFLICT_HORIZON_DAYS
+	if conflictHorizon := os.Getenv("CONFLICT_HORIZON_DAYS"); conflictHorizon != "" {
+		if d, err := strconv.Atoi(conflictHorizon); err == nil {
+			c.ConflictHorizonDays = d
+		}
+	}
+
 	// Ensure Auth config exists before setting values
 	if c.Auth == nil {
 		c.Auth = middleware.DefaultAuthConfig()
@@ -236,6 +328,79 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate storage settings (an unset backend falls back to in-memory)
+	if c.StorageBackend == "" {
+		c.StorageBackend = storage.BackendMemory
+	}
+	if !contains(storage.ValidBackends, strings.ToLower(c.StorageBackend)) {
+		log.Error().Msgf("Invalid storage backend: %s", c.StorageBackend)
+		return fmt.Errorf("configuration validation failed: storage_backend must be one of %v, got '%s'. Update 'storage_backend' in config file", storage.ValidBackends, c.StorageBackend)
+	}
+	if strings.ToLower(c.StorageBackend) == storage.BackendFile && c.DataDir == "" {
+		log.Error().Msg("data_dir cannot be empty when storage_backend is 'file'")
+		return fmt.Errorf("configuration validation failed: data_dir cannot be empty when storage_backend is 'file'. Specify a directory path (e.g., './data') in config file")
+	}
+
+	// Validate execution history retention (an unset limit falls back to the default)
+	if c.ExecutionHistoryLimit == 0 {
+		c.ExecutionHistoryLimit = DefaultConfig().ExecutionHistoryLimit
+	}
+	if c.ExecutionHistoryLimit < 1 {
+		log.Error().Msgf("Invalid execution history limit: %d", c.ExecutionHistoryLimit)
+		return fmt.Errorf("configuration validation failed: execution_history_limit must be at least 1, got %d. Increase 'execution_history_limit' in config file", c.ExecutionHistoryLimit)
+	}
+	if c.ExecutionHistoryMaxAgeDays < 0 {
+		log.Error().Msgf("Invalid execution history max age: %d", c.ExecutionHistoryMaxAgeDays)
+		return fmt.Errorf("configuration validation failed: execution_history_max_age_days must not be negative, got %d. Use 0 to keep executions regardless of age", c.ExecutionHistoryMaxAgeDays)
+	}
+
+	// Validate run queue misfire handling (an unset policy falls back to the default)
+	if c.MisfirePolicy == "" {
+		c.MisfirePolicy = DefaultConfig().MisfirePolicy
+	}
+	if !contains(scheduler.ValidMisfirePolicies, c.MisfirePolicy) {
+		log.Error().Msgf("Invalid misfire policy: %s", c.MisfirePolicy)
+		return fmt.Errorf("configuration validation failed: misfire_policy must be one of %v, got '%s'. Update 'misfire_policy' in config file", scheduler.ValidMisfirePolicies, c.MisfirePolicy)
+	}
+	if c.MisfireThresholdSeconds < 0 {
+		log.Error().Msgf("Invalid misfire threshold: %d", c.MisfireThresholdSeconds)
+		return fmt.Errorf("configuration validation failed: misfire_threshold_seconds must not be negative, got %d. Use 0 to never treat queued runs as misfired", c.MisfireThresholdSeconds)
+	}
+
+	// Validate machine locking (unset values fall back to the defaults)
+	if c.LockPolicy == "" {
+		c.LockPolicy = DefaultConfig().LockPolicy
+	}
+	if !contains(scheduler.ValidLockPolicies, c.LockPolicy) {
+		log.Error().Msgf("Invalid lock policy: %s", c.LockPolicy)
+		return fmt.Errorf("configuration validation failed: lock_policy must be one of %v, got '%s'. Update 'lock_policy' in config file", scheduler.ValidLockPolicies, c.LockPolicy)
+	}
+	if c.LockWaitTimeoutSeconds < 0 {
+		log.Error().Msgf("Invalid lock wait timeout: %d", c.LockWaitTimeoutSeconds)
+		return fmt.Errorf("configuration validation failed: lock_wait_timeout_seconds must not be negative, got %d. Use 0 to wait for locks without a limit", c.LockWaitTimeoutSeconds)
+	}
+	if c.LockScope == "" {
+		c.LockScope = DefaultConfig().LockScope
+	}
+	if !contains(scheduler.ValidLockScopes, c.LockScope) {
+		log.Error().Msgf("Invalid lock scope: %s", c.LockScope)
+		return fmt.Errorf("configuration validation failed: lock_scope must be one of %v, got '%s'. Update 'lock_scope' in config file", scheduler.ValidLockScopes, c.LockScope)
+	}
+
+	// Validate schedule conflict detection (an unset horizon falls back to the default)
+	if c.ConflictWindowSeconds < 0 {
+		log.Error().Msgf("Invalid conflict window: %d", c.ConflictWindowSeconds)
+		return fmt.Errorf("configuration validation failed: conflict_window_seconds must not be negative, got %d. Use 0 to flag only runs at the same time", c.ConflictWindowSeconds)
+	}
+	if c.ConflictHorizonDays == 0 {
+		c.ConflictHorizonDays = DefaultConfig().ConflictHorizonDays
+	}
+	maxHorizonDays := int(scheduler.MaxScheduleRange / (24 * time.Hour))
+	if c.ConflictHorizonDays < 1 || c.ConflictHorizonDays > maxHorizonDays {
+		log.Error().Msgf("Invalid conflict horizon: %d", c.ConflictHorizonDays)
+		return fmt.Errorf("configuration validation failed: conflict_horizon_days must be between 1 and %d, got %d. Update 'conflict_horizon_days' in config file", maxHorizonDays, c.ConflictHorizonDays)
+	}
+
 	// Ensure Auth config exists
 	if c.Auth == nil {
 		c.Auth = middleware.DefaultAuthConfig()
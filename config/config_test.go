@@ -17,6 +17,17 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 99, cfg.WorkerPoolSize)
 	assert.Equal(t, "./logs", cfg.LogsDir)
 	assert.Equal(t, 30, cfg.ShutdownTimeout)
+	assert.Equal(t, "memory", cfg.StorageBackend)
+	assert.Equal(t, "./data", cfg.DataDir)
+	assert.Equal(t, 50, cfg.ExecutionHistoryLimit)
+	assert.Equal(t, 0, cfg.ExecutionHistoryMaxAgeDays)
+	assert.Equal(t, "Coalesce", cfg.MisfirePolicy)
+	assert.Equal(t, 60, cfg.MisfireThresholdSeconds)
+	assert.Equal(t, "Wait", cfg.LockPolicy)
+	assert.Equal(t, 30, cfg.LockWaitTimeoutSeconds)
+	assert.Equal(t, "Machine", cfg.LockScope)
+	assert.Equal(t, 60, cfg.ConflictWindowSeconds)
+	assert.Equal(t, 7, cfg.ConflictHorizonDays)
 }
 
 func TestLoadConfigFromEnv(t *testing.T) {
@@ -278,6 +289,185 @@ func TestValidateShutdownTimeout(t *testing.T) {
 	}
 }
 
+func TestValidateStorageBackend(t *testing.T) {
+	tests := []struct {
+		name      string
+		backend   string
+		dataDir   string
+		expectErr bool
+	}{
+		{"Memory backend", "memory", "./data", false},
+		{"File backend", "file", "./data", false},
+		{"Backend is case insensitive", "File", "./data", false},
+		{"Memory backend without data dir", "memory", "", false},
+		{"File backend without data dir", "file", "", true},
+		{"Unknown backend", "sqlite", "./data", true},
+		{"Empty backend defaults to memory", "", "./data", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.StorageBackend = tt.backend
+			cfg.DataDir = tt.dataDir
+
+			err := cfg.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadStorageConfigFromEnv(t *testing.T) {
+	os.Setenv("STORAGE_BACKEND", "file")
+	os.Setenv("DATA_DIR", "/var/lib/multifish")
+	defer func() {
+		os.Unsetenv("STORAGE_BACKEND")
+		os.Unsetenv("DATA_DIR")
+	}()
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+
+	assert.Equal(t, "file", cfg.StorageBackend)
+	assert.Equal(t, "/var/lib/multifish", cfg.DataDir)
+}
+
+func TestValidateExecutionHistoryRetention(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      int
+		maxAgeDays int
+		expectErr  bool
+	}{
+		{"Valid retention", 100, 30, false},
+		{"No age limit", 10, 0, false},
+		{"Unset limit defaults", 0, 0, false},
+		{"Negative limit", -1, 0, true},
+		{"Negative max age", 10, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.ExecutionHistoryLimit = tt.limit
+			cfg.ExecutionHistoryMaxAgeDays = tt.maxAgeDays
+
+			err := cfg.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "execution_history")
+			} else {
+				assert.NoError(t, err)
+				assert.Greater(t, cfg.ExecutionHistoryLimit, 0)
+			}
+		})
+	}
+}
+
+func TestValidateMisfirePolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		threshold int
+		expectErr bool
+	}{
+		{"Run", "Run", 60, false},
+		{"Drop without threshold", "Drop", 0, false},
+		{"Unset policy defaults", "", 60, false},
+		{"Unknown policy", "Skip", 60, true},
+		{"Negative threshold", "Coalesce", -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.MisfirePolicy = tt.policy
+			cfg.MisfireThresholdSeconds = tt.threshold
+
+			err := cfg.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "misfire")
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, cfg.MisfirePolicy)
+			}
+		})
+	}
+}
+
+func TestValidateLockSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		timeout   int
+		scope     string
+		expectErr bool
+	}{
+		{"FailFast", "FailFast", 30, "Machine", false},
+		{"Queue per manager", "Queue", 0, "Manager", false},
+		{"Unset values default", "", 30, "", false},
+		{"Unknown policy", "Block", 30, "Machine", true},
+		{"Negative timeout", "Wait", -1, "Machine", true},
+		{"Unknown scope", "Wait", 30, "Rack", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.LockPolicy = tt.policy
+			cfg.LockWaitTimeoutSeconds = tt.timeout
+			cfg.LockScope = tt.scope
+
+			err := cfg.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "lock_")
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, cfg.LockPolicy)
+				assert.NotEmpty(t, cfg.LockScope)
+			}
+		})
+	}
+}
+
+func TestValidateConflictSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		window    int
+		horizon   int
+		expectErr bool
+	}{
+		{"Same time only", 0, 7, false},
+		{"Unset horizon defaults", 60, 0, false},
+		{"Longest horizon", 60, 31, false},
+		{"Negative window", -1, 7, true},
+		{"Horizon too long", 60, 32, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.ConflictWindowSeconds = tt.window
+			cfg.ConflictHorizonDays = tt.horizon
+
+			err := cfg.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "conflict_")
+			} else {
+				assert.NoError(t, err)
+				assert.NotZero(t, cfg.ConflictHorizonDays)
+			}
+		})
+	}
+}
+
 func TestSaveToFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.yaml")
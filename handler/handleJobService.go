@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"multifish/config"
 	"multifish/scheduler"
+	"multifish/storage"
 	"multifish/utility"
 )
 
@@ -30,6 +36,31 @@ func formatJobResponse(job *scheduler.Job) gin.H {
 		"Status":         job.Status,
 		"CreatedTime":    job.CreatedTime.Format("2006-01-02T15:04:05Z07:00"),
 		"ExecutionCount": job.ExecutionCount,
+		"Executions": gin.H{
+			"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s/Executions", job.ID),
+		},
+	}
+
+	if len(job.Steps) > 0 {
+		response["Steps"] = job.Steps
+	}
+
+	// Dependent jobs have no schedule, they run when their parent jobs finish
+	if len(job.DependsOn) > 0 {
+		delete(response, "Schedule")
+		dependencies := make([]gin.H, len(job.DependsOn))
+		for i, dependency := range job.DependsOn {
+			condition := dependency.Condition
+			if condition == "" {
+				condition = scheduler.DependencyOnSuccess
+			}
+			dependencies[i] = gin.H{
+				"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s", dependency.JobID),
+				"JobId":     dependency.JobID,
+				"Condition": condition,
+			}
+		}
+		response["DependsOn"] = dependencies
 	}
 
 	if job.LastRunTime != nil {
@@ -40,6 +71,182 @@ func formatJobResponse(job *scheduler.Job) gin.H {
 		response["NextRunTime"] = job.NextRunTime.Format("2006-01-02T15:04:05Z07:00")
 	}
 
+	if len(job.Calendars) > 0 {
+		calendars := make([]gin.H, len(job.Calendars))
+		for i, calendarID := range job.Calendars {
+			calendars[i] = gin.H{
+				"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Calendars/%s", calendarID),
+			}
+		}
+		response["Calendars"] = calendars
+	}
+
+	if job.BlackoutPolicy != "" {
+		response["BlackoutPolicy"] = job.BlackoutPolicy
+	}
+
+	if job.RetryPolicy != nil {
+		response["RetryPolicy"] = job.RetryPolicy
+	}
+
+	if job.Timeout != "" {
+		response["Timeout"] = job.Timeout
+	}
+
+	if job.Rollout != nil {
+		response["Rollout"] = job.Rollout
+	}
+
+	if job.Priority != 0 {
+		response["Priority"] = job.Priority
+	}
+
+	if job.MisfirePolicy != "" {
+		response["MisfirePolicy"] = job.MisfirePolicy
+	}
+
+	if job.LockPolicy != "" {
+		response["LockPolicy"] = job.LockPolicy
+	}
+
+	if job.Template != nil {
+		response["Template"] = gin.H{
+			"@odata.id":  fmt.Sprintf("/MultiFish/v1/JobService/JobTemplates/%s", job.Template.TemplateID),
+			"TemplateId": job.Template.TemplateID,
+			"Parameters": job.Template.Parameters,
+		}
+	}
+
+	if job.RevertAfter != "" {
+		response["RevertAfter"] = job.RevertAfter
+	}
+
+	if job.Verify {
+		response["Verify"] = true
+		if job.VerifyTolerance != nil {
+			response["VerifyTolerance"] = *job.VerifyTolerance
+		}
+	}
+
+	if job.PendingRevert != nil {
+		machines := make([]string, len(job.PendingRevert.Machines))
+		for i, machine := range job.PendingRevert.Machines {
+			machines[i] = machine.MachineID
+		}
+		response["PendingRevert"] = gin.H{
+			"RevertTime": job.PendingRevert.RevertTime.Format("2006-01-02T15:04:05Z07:00"),
+			"Executions": executionLinks(job.ID, job.PendingRevert.Executions),
+			"Machines":   machines,
+		}
+	}
+
+	return response
+}
+
+// executionLinks returns the @odata.id links of executions of a job
+func executionLinks(jobID string, executionIDs []string) []gin.H {
+	links := make([]gin.H, len(executionIDs))
+	for i, executionID := range executionIDs {
+		links[i] = gin.H{
+			"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s/Executions/%s", jobID, executionID),
+		}
+	}
+	return links
+}
+
+// formatExecutionResponse formats a job execution for the API response
+func formatExecutionResponse(execution *scheduler.ExecutionHistory) gin.H {
+	results := execution.Results
+	if results == nil {
+		results = []scheduler.MachineExecutionResult{}
+	}
+
+	response := gin.H{
+		"@odata.type":   "#JobExecution.v1_0_0.JobExecution",
+		"@odata.id":     fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s/Executions/%s", execution.JobID, execution.ID),
+		"Id":            execution.ID,
+		"JobId":         execution.JobID,
+		"ExecutionTime": execution.ExecutionTime.Format("2006-01-02T15:04:05Z07:00"),
+		"Status":        execution.Status,
+		"Results":       results,
+	}
+
+	if execution.Message != "" {
+		response["Message"] = execution.Message
+	}
+
+	if execution.Manual {
+		response["Manual"] = true
+	}
+
+	if len(execution.RevertOf) > 0 {
+		response["RevertOf"] = executionLinks(execution.JobID, execution.RevertOf)
+	}
+
+	if execution.TriggeredBy != "" {
+		response["TriggeredBy"] = gin.H{
+			"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s", execution.TriggeredBy),
+		}
+	}
+
+	return response
+}
+
+// formatTemplateResponse formats a job template for the API response
+func formatTemplateResponse(template *scheduler.JobTemplate) gin.H {
+	response := gin.H{
+		"@odata.type": "#JobTemplate.v1_0_0.JobTemplate",
+		"@odata.id":   fmt.Sprintf("/MultiFish/v1/JobService/JobTemplates/%s", template.ID),
+		"Id":          template.ID,
+		"Name":        template.Name,
+		"Parameters":  template.Parameters,
+		"Job":         template.Job,
+		"CreatedTime": template.CreatedTime.Format("2006-01-02T15:04:05Z07:00"),
+		"Actions": gin.H{
+			"#JobTemplate.Instantiate": gin.H{
+				"target": fmt.Sprintf("/MultiFish/v1/JobService/JobTemplates/%s/Actions/Instantiate", template.ID),
+			},
+		},
+	}
+
+	if template.Description != "" {
+		response["Description"] = template.Description
+	}
+
+	return response
+}
+
+// formatCalendarResponse formats a blackout calendar for the API response
+func formatCalendarResponse(calendar *scheduler.BlackoutCalendar) gin.H {
+	windows := make([]gin.H, len(calendar.Windows))
+	for i, window := range calendar.Windows {
+		formatted := gin.H{}
+		if window.Start != nil {
+			formatted["Start"] = window.Start.Format("2006-01-02T15:04:05Z07:00")
+		}
+		if window.End != nil {
+			formatted["End"] = window.End.Format("2006-01-02T15:04:05Z07:00")
+		}
+		if window.Recurrence != nil {
+			formatted["Recurrence"] = window.Recurrence
+		}
+		windows[i] = formatted
+	}
+
+	response := gin.H{
+		"@odata.type": "#BlackoutCalendar.v1_0_0.BlackoutCalendar",
+		"@odata.id":   fmt.Sprintf("/MultiFish/v1/JobService/Calendars/%s", calendar.ID),
+		"Id":          calendar.ID,
+		"Name":        calendar.Name,
+		"Global":      calendar.Global,
+		"Windows":     windows,
+		"CreatedTime": calendar.CreatedTime.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	if calendar.TimeZone != "" {
+		response["TimeZone"] = calendar.TimeZone
+	}
+
 	return response
 }
 
@@ -47,7 +254,38 @@ func formatJobResponse(job *scheduler.Job) gin.H {
 func formatSchedule(schedule scheduler.Schedule) gin.H {
 	result := gin.H{
 		"Type": schedule.Type,
-		"Time": schedule.Time,
+	}
+
+	if schedule.Time != "" {
+		result["Time"] = schedule.Time
+	}
+
+	if schedule.Cron != "" {
+		result["Cron"] = schedule.Cron
+	}
+
+	if schedule.Interval != "" {
+		result["Interval"] = schedule.Interval
+	}
+
+	if schedule.StartTime != nil {
+		result["StartTime"] = schedule.StartTime.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	if schedule.EndTime != nil {
+		result["EndTime"] = schedule.EndTime.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	if schedule.MaxExecutions > 0 {
+		result["MaxExecutions"] = schedule.MaxExecutions
+	}
+
+	if schedule.TimeZone != "" {
+		result["TimeZone"] = schedule.TimeZone
+	}
+
+	if schedule.Immediate {
+		result["Immediate"] = true
 	}
 
 	if schedule.Period != nil {
@@ -71,6 +309,10 @@ func formatSchedule(schedule scheduler.Schedule) gin.H {
 			period["DaysOfMonth"] = *schedule.Period.DaysOfMonth
 		}
 
+		if schedule.Period.DayMatch != "" {
+			period["DayMatch"] = schedule.Period.DayMatch
+		}
+
 		result["Period"] = period
 	}
 
@@ -79,6 +321,11 @@ func formatSchedule(schedule scheduler.Schedule) gin.H {
 
 // GET /MultiFish/v1/JobService - Get JobService root
 func getJobServiceRoot(c *gin.Context) {
+	misfirePolicy, misfireThreshold := JobService.GetMisfirePolicy()
+	queue := JobService.GetQueueStats()
+	lockPolicy, lockWaitTimeout, lockScope := MachineLocks.GetSettings()
+	conflictWindow, conflictHorizon := JobService.GetConflictSettings()
+
 	c.JSON(http.StatusOK, gin.H{
 		"@odata.type": "#JobService.v1_0_0.JobService",
 		"@odata.id":   "/MultiFish/v1/JobService",
@@ -87,12 +334,45 @@ func getJobServiceRoot(c *gin.Context) {
 		"Jobs": gin.H{
 			"@odata.id": "/MultiFish/v1/JobService/Jobs",
 		},
+		"Calendars": gin.H{
+			"@odata.id": "/MultiFish/v1/JobService/Calendars",
+		},
+		"Locks": gin.H{
+			"@odata.id": "/MultiFish/v1/JobService/Locks",
+		},
+		"Schedule": gin.H{
+			"@odata.id": "/MultiFish/v1/JobService/Schedule",
+		},
+		"JobTemplates": gin.H{
+			"@odata.id": "/MultiFish/v1/JobService/JobTemplates",
+		},
+		"Actions": gin.H{
+			"#JobService.PreviewSchedule": gin.H{
+				"target": "/MultiFish/v1/JobService/Actions/PreviewSchedule",
+			},
+		},
 		"ServiceCapabilities": gin.H{
-			"WorkerPoolSize":    JobService.GetWorkerPoolSize(),
-			"ActiveWorkers":     JobService.GetActiveWorkers(),
-			"AvailableWorkers":  JobService.GetAvailableWorkers(),
-			"TotalJobs":         JobService.GetJobCount(),
-			"RunningJobs":       JobService.GetRunningJobsCount(),
+			"WorkerPoolSize":        JobService.GetWorkerPoolSize(),
+			"ActiveWorkers":         JobService.GetActiveWorkers(),
+			"AvailableWorkers":      JobService.GetAvailableWorkers(),
+			"TotalJobs":             JobService.GetJobCount(),
+			"RunningJobs":           JobService.GetRunningJobsCount(),
+			"ExecutionHistoryLimit": JobService.GetExecutionHistoryLimit(),
+			"MisfirePolicy":         misfirePolicy,
+			"MisfireThreshold":      misfireThreshold.String(),
+			"LockPolicy":            lockPolicy,
+			"LockWaitTimeout":       lockWaitTimeout.String(),
+			"LockScope":             lockScope,
+			"ConflictWindow":        conflictWindow.String(),
+			"ConflictHorizon":       conflictHorizon.String(),
+			"RunQueue": gin.H{
+				"Depth":       queue.Depth,
+				"OldestWait":  queue.OldestWait.String(),
+				"AverageWait": queue.AverageWait.String(),
+				"MaxWait":     queue.MaxWait.String(),
+				"Dispatched":  queue.Dispatched,
+				"Misfires":    queue.Misfires,
+			},
 		},
 	})
 }
@@ -101,7 +381,14 @@ func getJobServiceRoot(c *gin.Context) {
 func patchJobServiceRoot(c *gin.Context) {
 	var req struct {
 		ServiceCapabilities *struct {
-			WorkerPoolSize *int `json:"WorkerPoolSize"`
+			WorkerPoolSize   *int                     `json:"WorkerPoolSize"`
+			MisfirePolicy    *scheduler.MisfirePolicy `json:"MisfirePolicy"`
+			MisfireThreshold *string                  `json:"MisfireThreshold"`
+			LockPolicy       *scheduler.LockPolicy    `json:"LockPolicy"`
+			LockWaitTimeout  *string                  `json:"LockWaitTimeout"`
+			LockScope        *scheduler.LockScope     `json:"LockScope"`
+			ConflictWindow   *string                  `json:"ConflictWindow"`
+			ConflictHorizon  *string                  `json:"ConflictHorizon"`
 		} `json:"ServiceCapabilities"`
 	}
 
@@ -124,10 +411,278 @@ func patchJobServiceRoot(c *gin.Context) {
 		}
 	}
 
+	// Check if the misfire policy or threshold was provided
+	if req.ServiceCapabilities != nil && (req.ServiceCapabilities.MisfirePolicy != nil || req.ServiceCapabilities.MisfireThreshold != nil) {
+		policy, threshold := JobService.GetMisfirePolicy()
+		if req.ServiceCapabilities.MisfirePolicy != nil {
+			policy = *req.ServiceCapabilities.MisfirePolicy
+		}
+		if req.ServiceCapabilities.MisfireThreshold != nil {
+			parsed, err := time.ParseDuration(*req.ServiceCapabilities.MisfireThreshold)
+			if err != nil {
+				utility.RedfishError(c, http.StatusBadRequest,
+					fmt.Sprintf("Invalid MisfireThreshold: %v. Use a Go duration such as '30s' or '5m'", err),
+					"PropertyValueFormatError")
+				return
+			}
+			threshold = parsed
+		}
+
+		if err := JobService.SetMisfirePolicy(policy, threshold); err != nil {
+			utility.RedfishError(c, http.StatusBadRequest,
+				fmt.Sprintf("Invalid misfire configuration: %v", err),
+				"PropertyValueNotInList")
+			return
+		}
+	}
+
+	// Check if the lock policy, wait timeout or scope was provided
+	if req.ServiceCapabilities != nil && (req.ServiceCapabilities.LockPolicy != nil || req.ServiceCapabilities.LockWaitTimeout != nil || req.ServiceCapabilities.LockScope != nil) {
+		policy, waitTimeout, scope := MachineLocks.GetSettings()
+		if req.ServiceCapabilities.LockPolicy != nil {
+			policy = *req.ServiceCapabilities.LockPolicy
+		}
+		if req.ServiceCapabilities.LockWaitTimeout != nil {
+			parsed, err := time.ParseDuration(*req.ServiceCapabilities.LockWaitTimeout)
+			if err != nil {
+				utility.RedfishError(c, http.StatusBadRequest,
+					fmt.Sprintf("Invalid LockWaitTimeout: %v. Use a Go duration such as '30s' or '5m'", err),
+					"PropertyValueFormatError")
+				return
+			}
+			waitTimeout = parsed
+		}
+		if req.ServiceCapabilities.LockScope != nil {
+			scope = *req.ServiceCapabilities.LockScope
+		}
+
+		if err := MachineLocks.SetSettings(policy, waitTimeout, scope); err != nil {
+			if errors.Is(err, scheduler.ErrLockScopeInUse) {
+				utility.RedfishError(c, http.StatusConflict, err.Error(), "ResourceInUse")
+				return
+			}
+			utility.RedfishError(c, http.StatusBadRequest,
+				fmt.Sprintf("Invalid lock configuration: %v", err),
+				"PropertyValueNotInList")
+			return
+		}
+	}
+
+	// Check if the conflict window or horizon was provided
+	if req.ServiceCapabilities != nil && (req.ServiceCapabilities.ConflictWindow != nil || req.ServiceCapabilities.ConflictHorizon != nil) {
+		window, horizon := JobService.GetConflictSettings()
+		if req.ServiceCapabilities.ConflictWindow != nil {
+			parsed, err := time.ParseDuration(*req.ServiceCapabilities.ConflictWindow)
+			if err != nil {
+				utility.RedfishError(c, http.StatusBadRequest,
+					fmt.Sprintf("Invalid ConflictWindow: %v. Use a Go duration such as '30s' or '5m'", err),
+					"PropertyValueFormatError")
+				return
+			}
+			window = parsed
+		}
+		if req.ServiceCapabilities.ConflictHorizon != nil {
+			parsed, err := time.ParseDuration(*req.ServiceCapabilities.ConflictHorizon)
+			if err != nil {
+				utility.RedfishError(c, http.StatusBadRequest,
+					fmt.Sprintf("Invalid ConflictHorizon: %v. Use a Go duration such as '72h' or '168h'", err),
+					"PropertyValueFormatError")
+				return
+			}
+			horizon = parsed
+		}
+
+		if err := JobService.SetConflictSettings(window, horizon); err != nil {
+			utility.RedfishError(c, http.StatusBadRequest,
+				fmt.Sprintf("Invalid conflict configuration: %v", err),
+				"PropertyValueNotInList")
+			return
+		}
+	}
+
 	// Return updated JobService root
 	getJobServiceRoot(c)
 }
 
+// GET /MultiFish/v1/JobService/Locks - Get held machine locks and their waiters
+func getLocksCollection(c *gin.Context) {
+	locks := MachineLocks.GetLocks()
+
+	members := make([]gin.H, len(locks))
+	for i, lock := range locks {
+		member := gin.H{
+			"Resource":     lock.Resource,
+			"MachineId":    lock.MachineID,
+			"Owner":        lock.Owner,
+			"AcquiredTime": lock.AcquiredTime,
+			"Waiting":      lock.Waiting,
+		}
+		if lock.ManagerID != "" {
+			member["ManagerId"] = lock.ManagerID
+		}
+		if lock.Owner.JobID != "" {
+			member["Job"] = gin.H{"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s", lock.Owner.JobID)}
+		}
+		members[i] = member
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"@odata.type":         "#LockCollection.LockCollection",
+		"@odata.id":           "/MultiFish/v1/JobService/Locks",
+		"Name":                "Machine Lock Collection",
+		"Members":             members,
+		"Members@odata.count": len(members),
+	})
+}
+
+// GET /MultiFish/v1/JobService/Schedule?from=&to=&window= - Get the upcoming
+// runs of all jobs and the conflicts between them. from defaults to now, to to
+// one day after from and window to the service conflict window.
+func getSchedule(c *gin.Context) {
+	from := JobService.Now()
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utility.RedfishError(c, http.StatusBadRequest,
+				fmt.Sprintf("Invalid from query parameter '%s': use an RFC 3339 time such as '2026-02-10T00:00:00Z'", value),
+				"QueryParameterValueFormatError")
+			return
+		}
+		from = parsed
+	}
+
+	to := from.Add(24 * time.Hour)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utility.RedfishError(c, http.StatusBadRequest,
+				fmt.Sprintf("Invalid to query parameter '%s': use an RFC 3339 time such as '2026-02-11T00:00:00Z'", value),
+				"QueryParameterValueFormatError")
+			return
+		}
+		to = parsed
+	}
+
+	window, _ := JobService.GetConflictSettings()
+	if value := c.Query("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			utility.RedfishError(c, http.StatusBadRequest,
+				fmt.Sprintf("Invalid window query parameter '%s': use a Go duration such as '30s' or '5m'", value),
+				"QueryParameterValueFormatError")
+			return
+		}
+		window = parsed
+	}
+
+	view, err := JobService.GetSchedule(from, to, window)
+	if err != nil {
+		utility.RedfishError(c, http.StatusBadRequest, err.Error(), "QueryParameterOutOfRange")
+		return
+	}
+
+	runs := make([]gin.H, len(view.Runs))
+	for i, run := range view.Runs {
+		runs[i] = formatScheduledRun(run)
+	}
+	conflicts := make([]gin.H, len(view.Conflicts))
+	for i, conflict := range view.Conflicts {
+		entry := gin.H{
+			"Resource":  conflict.Resource,
+			"MachineId": conflict.MachineID,
+			"Runs":      []gin.H{formatScheduledRun(conflict.Runs[0]), formatScheduledRun(conflict.Runs[1])},
+			"Apart":     conflict.Apart,
+		}
+		if conflict.ManagerID != "" {
+			entry["ManagerId"] = conflict.ManagerID
+		}
+		conflicts[i] = entry
+	}
+
+	response := gin.H{
+		"@odata.type":           "#JobSchedule.JobSchedule",
+		"@odata.id":             "/MultiFish/v1/JobService/Schedule",
+		"Name":                  "Job Schedule",
+		"From":                  view.From,
+		"To":                    view.To,
+		"ConflictWindow":        view.ConflictWindow.String(),
+		"Runs":                  runs,
+		"Runs@odata.count":      len(runs),
+		"Conflicts":             conflicts,
+		"Conflicts@odata.count": len(conflicts),
+	}
+	if len(view.TruncatedJobs) > 0 {
+		response["TruncatedJobs"] = view.TruncatedJobs
+	}
+	if view.ConflictsTruncated {
+		response["ConflictsTruncated"] = true
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// formatScheduledRun formats an upcoming run with a link to its job
+func formatScheduledRun(run scheduler.ScheduledRun) gin.H {
+	response := gin.H{
+		"Job":       gin.H{"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s", run.JobID)},
+		"JobId":     run.JobID,
+		"RunTime":   run.RunTime,
+		"Action":    run.Action,
+		"Machines":  run.Machines,
+		"Resources": run.Resources,
+	}
+	if run.JobName != "" {
+		response["JobName"] = run.JobName
+	}
+	if run.BlackoutCalendar != "" {
+		response["BlackoutCalendar"] = run.BlackoutCalendar
+	}
+	return response
+}
+
+// POST /MultiFish/v1/JobService/Actions/PreviewSchedule - Compute the next run
+// times of a schedule without creating a job
+func previewSchedule(c *gin.Context) {
+	var req struct {
+		Schedule      *scheduler.Schedule `json:"Schedule"`
+		TimeZone      string              `json:"TimeZone"`
+		ReferenceTime *time.Time          `json:"ReferenceTime"`
+		Count         int                 `json:"Count"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utility.RedfishError(c, http.StatusBadRequest,
+			fmt.Sprintf("Invalid request body: %v", err),
+			"InvalidJSON")
+		return
+	}
+	if req.Schedule == nil {
+		utility.RedfishError(c, http.StatusBadRequest,
+			"Schedule is required",
+			"PropertyMissing")
+		return
+	}
+
+	var reference time.Time
+	if req.ReferenceTime != nil {
+		reference = *req.ReferenceTime
+	}
+
+	preview, validationResp, err := JobService.PreviewSchedule(*req.Schedule, req.TimeZone, reference, req.Count)
+	if err != nil {
+		jobValidationError(c, err, validationResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"@odata.type":          "#SchedulePreview.SchedulePreview",
+		"Schedule":             preview.Schedule,
+		"TimeZone":             preview.TimeZone,
+		"ReferenceTime":        preview.ReferenceTime,
+		"RunTimes":             preview.RunTimes,
+		"RunTimes@odata.count": len(preview.RunTimes),
+	})
+}
+
 // GET /MultiFish/v1/JobService/Jobs - Get jobs collection
 func getJobsCollection(c *gin.Context) {
 	jobs := JobService.ListJobs()
@@ -164,28 +719,11 @@ func createJob(c *gin.Context) {
 
 	// If validation failed, return detailed error
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "JobValidationFailed",
-				"message": err.Error(),
-				"@Message.ExtendedInfo": []gin.H{
-					{
-						"MessageId": "Base.1.0.JobValidationFailed",
-						"Message":   validationResp.Message,
-						"Severity":  "Critical",
-						"ValidationDetails": gin.H{
-							"ScheduleValid":  validationResp.ScheduleValid,
-							"ScheduleErrors": validationResp.ScheduleErrors,
-							"ActionValid":    validationResp.ActionValid,
-							"ActionErrors":   validationResp.ActionErrors,
-							"PayloadValid":   validationResp.PayloadValid,
-							"PayloadErrors":  validationResp.PayloadErrors,
-							"MachineResults": validationResp.MachineResults,
-						},
-					},
-				},
-			},
-		})
+		if validationResp == nil {
+			utility.RedfishError(c, http.StatusInternalServerError, err.Error(), "InternalError")
+			return
+		}
+		jobValidationError(c, err, validationResp)
 		return
 	}
 
@@ -193,6 +731,32 @@ func createJob(c *gin.Context) {
 	c.JSON(http.StatusCreated, formatJobResponse(job))
 }
 
+// jobValidationError writes a 400 response with the details of a failed job validation
+func jobValidationError(c *gin.Context, err error, validationResp *scheduler.JobValidationResponse) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": gin.H{
+			"code":    "JobValidationFailed",
+			"message": err.Error(),
+			"@Message.ExtendedInfo": []gin.H{
+				{
+					"MessageId": "Base.1.0.JobValidationFailed",
+					"Message":   validationResp.Message,
+					"Severity":  "Critical",
+					"ValidationDetails": gin.H{
+						"ScheduleValid":  validationResp.ScheduleValid,
+						"ScheduleErrors": validationResp.ScheduleErrors,
+						"ActionValid":    validationResp.ActionValid,
+						"ActionErrors":   validationResp.ActionErrors,
+						"PayloadValid":   validationResp.PayloadValid,
+						"PayloadErrors":  validationResp.PayloadErrors,
+						"MachineResults": validationResp.MachineResults,
+					},
+				},
+			},
+		},
+	})
+}
+
 // GET /MultiFish/v1/JobService/Jobs/:jobId - Get a specific job
 func getJob(c *gin.Context) {
 	jobID := c.Param("jobId")
@@ -208,11 +772,61 @@ func getJob(c *gin.Context) {
 	c.JSON(http.StatusOK, formatJobResponse(job))
 }
 
+// PATCH /MultiFish/v1/JobService/Jobs/:jobId - Update a job with a partial job definition
+func patchJob(c *gin.Context) {
+	jobID := c.Param("jobId")
+
+	body, err := c.GetRawData()
+	if err != nil {
+		utility.RedfishError(c, http.StatusBadRequest,
+			fmt.Sprintf("Invalid request body: %v", err),
+			"InvalidJSON")
+		return
+	}
+
+	job, validationResp, err := JobService.UpdateJob(jobID, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrInvalidJobUpdate):
+			utility.RedfishError(c, http.StatusBadRequest,
+				fmt.Sprintf("Invalid request body: %v", err),
+				"InvalidJSON")
+		case validationResp != nil:
+			jobValidationError(c, err, validationResp)
+		default:
+			jobActionError(c, jobID, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, formatJobResponse(job))
+}
+
 // DELETE /MultiFish/v1/JobService/Jobs/:jobId - Delete a job
 func deleteJob(c *gin.Context) {
 	jobID := c.Param("jobId")
 
-	if err := JobService.DeleteJob(jobID); err != nil {
+	discardRevert := false
+	if value := c.Query("discardRevert"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utility.RedfishError(c, http.StatusBadRequest, fmt.Sprintf("invalid discardRevert query parameter '%s': use true or false", value), "QueryParameterValueFormatError")
+			return
+		}
+		discardRevert = parsed
+	}
+
+	var err error
+	if discardRevert {
+		err = JobService.DeleteJobDiscardingRevert(jobID)
+	} else {
+		err = JobService.DeleteJob(jobID)
+	}
+	if errors.Is(err, scheduler.ErrJobHasDependents) || errors.Is(err, scheduler.ErrJobHasPendingRevert) {
+		utility.RedfishError(c, http.StatusConflict, err.Error(), "ResourceInUse")
+		return
+	}
+	if err != nil {
 		utility.RedfishError(c, http.StatusNotFound,
 			fmt.Sprintf("Job not found: %s", jobID),
 			"ResourceNotFound")
@@ -240,11 +854,349 @@ func cancelJob(c *gin.Context) {
 	})
 }
 
+// jobActionError writes the response for a failed job action
+func jobActionError(c *gin.Context, jobID string, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		utility.RedfishError(c, http.StatusNotFound,
+			fmt.Sprintf("Job not found: %s", jobID),
+			"ResourceNotFound")
+	case errors.Is(err, scheduler.ErrJobRunning):
+		utility.RedfishError(c, http.StatusConflict, err.Error(), "ResourceInUse")
+	case errors.Is(err, scheduler.ErrInvalidJobState):
+		utility.RedfishError(c, http.StatusConflict, err.Error(), "ActionNotSupported")
+	default:
+		utility.RedfishError(c, http.StatusInternalServerError, err.Error(), "InternalError")
+	}
+}
+
+// POST /MultiFish/v1/JobService/Jobs/:jobId/Actions/Pause - Suspend scheduled executions
+func pauseJob(c *gin.Context) {
+	jobID := c.Param("jobId")
+
+	if err := JobService.PauseJob(jobID); err != nil {
+		jobActionError(c, jobID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Job %s paused successfully", jobID),
+	})
+}
+
+// POST /MultiFish/v1/JobService/Jobs/:jobId/Actions/Resume - Resume a paused job
+func resumeJob(c *gin.Context) {
+	jobID := c.Param("jobId")
+
+	if err := JobService.ResumeJob(jobID); err != nil {
+		jobActionError(c, jobID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Job %s resumed successfully", jobID),
+	})
+}
+
+// POST /MultiFish/v1/JobService/Jobs/:jobId/Actions/Run - Queue an execution now, outside the schedule
+func runJob(c *gin.Context) {
+	jobID := c.Param("jobId")
+
+	if err := JobService.RunJob(jobID); err != nil {
+		jobActionError(c, jobID, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": fmt.Sprintf("Job %s execution queued", jobID),
+		"Executions": gin.H{
+			"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s/Executions", jobID),
+		},
+	})
+}
+
+// GET /MultiFish/v1/JobService/Jobs/:jobId/Executions - Get a job's execution history
+func getJobExecutions(c *gin.Context) {
+	jobID := c.Param("jobId")
+
+	executions, err := JobService.GetExecutions(jobID)
+	if err != nil {
+		utility.RedfishError(c, http.StatusNotFound,
+			fmt.Sprintf("Job not found: %s", jobID),
+			"ResourceNotFound")
+		return
+	}
+
+	// Newest execution first, each with its per-machine results
+	members := make([]gin.H, len(executions))
+	for i, execution := range executions {
+		members[len(executions)-1-i] = formatExecutionResponse(execution)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"@odata.type":         "#JobExecutionCollection.JobExecutionCollection",
+		"@odata.id":           fmt.Sprintf("/MultiFish/v1/JobService/Jobs/%s/Executions", jobID),
+		"Name":                "Job Execution Collection",
+		"Members":             members,
+		"Members@odata.count": len(members),
+	})
+}
+
+// GET /MultiFish/v1/JobService/Jobs/:jobId/Executions/:executionId - Get a single execution
+func getJobExecution(c *gin.Context) {
+	jobID := c.Param("jobId")
+	executionID := c.Param("executionId")
+
+	execution, err := JobService.GetExecution(jobID, executionID)
+	if err != nil {
+		utility.RedfishError(c, http.StatusNotFound,
+			fmt.Sprintf("Execution not found: %v", err),
+			"ResourceNotFound")
+		return
+	}
+
+	c.JSON(http.StatusOK, formatExecutionResponse(execution))
+}
+
+// GET /MultiFish/v1/JobService/Calendars - Get blackout calendars collection
+func getCalendarsCollection(c *gin.Context) {
+	calendars := JobService.ListCalendars()
+
+	members := make([]gin.H, len(calendars))
+	for i, calendar := range calendars {
+		members[i] = gin.H{
+			"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/Calendars/%s", calendar.ID),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"@odata.type":         "#BlackoutCalendarCollection.BlackoutCalendarCollection",
+		"@odata.id":           "/MultiFish/v1/JobService/Calendars",
+		"Name":                "Blackout Calendar Collection",
+		"Members":             members,
+		"Members@odata.count": len(members),
+	})
+}
+
+// POST /MultiFish/v1/JobService/Calendars - Create a blackout calendar
+func createCalendar(c *gin.Context) {
+	var calendar scheduler.BlackoutCalendar
+
+	if err := c.ShouldBindJSON(&calendar); err != nil {
+		utility.RedfishError(c, http.StatusBadRequest,
+			fmt.Sprintf("Invalid request body: %v", err),
+			"InvalidJSON")
+		return
+	}
+
+	validationErrors, err := JobService.CreateCalendar(&calendar)
+	if len(validationErrors) > 0 {
+		utility.RedfishError(c, http.StatusBadRequest,
+			fmt.Sprintf("Calendar validation failed: %s", strings.Join(validationErrors, "; ")),
+			"PropertyValueNotInList")
+		return
+	}
+	if errors.Is(err, scheduler.ErrCalendarExists) {
+		utility.RedfishError(c, http.StatusConflict, err.Error(), "ResourceAlreadyExists")
+		return
+	}
+	if err != nil {
+		utility.RedfishError(c, http.StatusInternalServerError, err.Error(), "InternalError")
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatCalendarResponse(&calendar))
+}
+
+// GET /MultiFish/v1/JobService/Calendars/:calendarId - Get a blackout calendar
+func getCalendar(c *gin.Context) {
+	calendarID := c.Param("calendarId")
+
+	calendar, err := JobService.GetCalendar(calendarID)
+	if err != nil {
+		utility.RedfishError(c, http.StatusNotFound,
+			fmt.Sprintf("Calendar not found: %s", calendarID),
+			"ResourceNotFound")
+		return
+	}
+
+	c.JSON(http.StatusOK, formatCalendarResponse(calendar))
+}
+
+// DELETE /MultiFish/v1/JobService/Calendars/:calendarId - Delete a blackout calendar
+func deleteCalendar(c *gin.Context) {
+	calendarID := c.Param("calendarId")
+
+	err := JobService.DeleteCalendar(calendarID)
+	if errors.Is(err, scheduler.ErrCalendarInUse) {
+		utility.RedfishError(c, http.StatusConflict, err.Error(), "ResourceInUse")
+		return
+	}
+	if err != nil {
+		utility.RedfishError(c, http.StatusNotFound,
+			fmt.Sprintf("Calendar not found: %s", calendarID),
+			"ResourceNotFound")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Calendar %s deleted successfully", calendarID),
+	})
+}
+
+// GET /MultiFish/v1/JobService/JobTemplates - Get job templates collection
+func getTemplatesCollection(c *gin.Context) {
+	templates := JobService.ListTemplates()
+
+	members := make([]gin.H, len(templates))
+	for i, template := range templates {
+		members[i] = gin.H{
+			"@odata.id": fmt.Sprintf("/MultiFish/v1/JobService/JobTemplates/%s", template.ID),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"@odata.type":         "#JobTemplateCollection.JobTemplateCollection",
+		"@odata.id":           "/MultiFish/v1/JobService/JobTemplates",
+		"Name":                "Job Template Collection",
+		"Members":             members,
+		"Members@odata.count": len(members),
+	})
+}
+
+// POST /MultiFish/v1/JobService/JobTemplates - Create a job template
+func createTemplate(c *gin.Context) {
+	var template scheduler.JobTemplate
+
+	if err := c.ShouldBindJSON(&template); err != nil {
+		utility.RedfishError(c, http.StatusBadRequest,
+			fmt.Sprintf("Invalid request body: %v", err),
+			"InvalidJSON")
+		return
+	}
+
+	validationErrors, err := JobService.CreateTemplate(&template)
+	if len(validationErrors) > 0 {
+		utility.RedfishError(c, http.StatusBadRequest,
+			fmt.Sprintf("Template validation failed: %s", strings.Join(validationErrors, "; ")),
+			"PropertyValueError")
+		return
+	}
+	if errors.Is(err, scheduler.ErrTemplateExists) {
+		utility.RedfishError(c, http.StatusConflict, err.Error(), "ResourceAlreadyExists")
+		return
+	}
+	if err != nil {
+		utility.RedfishError(c, http.StatusInternalServerError, err.Error(), "InternalError")
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatTemplateResponse(&template))
+}
+
+// GET /MultiFish/v1/JobService/JobTemplates/:templateId - Get a job template
+func getTemplate(c *gin.Context) {
+	templateID := c.Param("templateId")
+
+	template, err := JobService.GetTemplate(templateID)
+	if err != nil {
+		utility.RedfishError(c, http.StatusNotFound,
+			fmt.Sprintf("Template not found: %s", templateID),
+			"ResourceNotFound")
+		return
+	}
+
+	c.JSON(http.StatusOK, formatTemplateResponse(template))
+}
+
+// DELETE /MultiFish/v1/JobService/JobTemplates/:templateId - Delete a job template
+func deleteTemplate(c *gin.Context) {
+	templateID := c.Param("templateId")
+
+	err := JobService.DeleteTemplate(templateID)
+	if errors.Is(err, scheduler.ErrTemplateNotFound) {
+		utility.RedfishError(c, http.StatusNotFound,
+			fmt.Sprintf("Template not found: %s", templateID),
+			"ResourceNotFound")
+		return
+	}
+	if err != nil {
+		utility.RedfishError(c, http.StatusInternalServerError, err.Error(), "InternalError")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Template %s deleted successfully", templateID),
+	})
+}
+
+// POST /MultiFish/v1/JobService/JobTemplates/:templateId/Actions/Instantiate -
+// Create a job from a template. Parameters left out take their defaults.
+func instantiateTemplate(c *gin.Context) {
+	templateID := c.Param("templateId")
+
+	// The body is optional when every parameter has a default
+	var req struct {
+		Parameters map[string]json.RawMessage `json:"Parameters"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utility.RedfishError(c, http.StatusBadRequest,
+				fmt.Sprintf("Invalid request body: %v", err),
+				"InvalidJSON")
+			return
+		}
+	}
+
+	job, validationResp, err := JobService.InstantiateTemplate(templateID, req.Parameters)
+	if errors.Is(err, scheduler.ErrTemplateNotFound) {
+		utility.RedfishError(c, http.StatusNotFound,
+			fmt.Sprintf("Template not found: %s", templateID),
+			"ResourceNotFound")
+		return
+	}
+	if errors.Is(err, scheduler.ErrInvalidTemplateParameters) {
+		utility.RedfishError(c, http.StatusBadRequest, err.Error(), "ActionParameterValueError")
+		return
+	}
+	if err != nil {
+		if validationResp == nil {
+			utility.RedfishError(c, http.StatusInternalServerError, err.Error(), "InternalError")
+			return
+		}
+		jobValidationError(c, err, validationResp)
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatJobResponse(job))
+}
+
 // ========== Job Service Initialization ==========
 
+// NewJobStore creates the job store selected by the storage configuration.
+// The memory backend keeps nothing on disk and returns a nil store.
+func NewJobStore(cfg *config.Config) (scheduler.JobStore, error) {
+	switch strings.ToLower(cfg.StorageBackend) {
+	case storage.BackendFile:
+		fileStore, err := storage.NewFileStore(cfg.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		return scheduler.NewFileJobStore(fileStore), nil
+	case storage.BackendMemory, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage backend '%s'. Valid backends: %v", cfg.StorageBackend, storage.ValidBackends)
+	}
+}
+
 // JobService is the global job service instance
 var JobService *scheduler.JobService
 
+// MachineLocks serializes the jobs and manager PATCH requests that change the
+// same machine
+var MachineLocks = scheduler.NewMachineLockManager()
+
 // InitJobService initializes the job service
 func InitJobService(cfg *config.Config) {
 	log := utility.GetLogger()
@@ -258,18 +1210,49 @@ func InitJobService(cfg *config.Config) {
 	platformAdapter := NewPlatformManagerAdapter(PlatformMgr)
 	
 	// Create validator and executor
-	validator := scheduler.NewPlatformValidator(platformAdapter)
 	machineExecutorAdapter := &MachineActionExecutorAdapter{}
+	validator := scheduler.NewPlatformValidatorWithExecutor(platformAdapter, machineExecutorAdapter)
 	actionExecutor := scheduler.NewDefaultActionExecutor(machineExecutorAdapter)
-	executor := scheduler.NewPlatformExecutor(platformAdapter, actionExecutor)
+	executor := scheduler.NewPlatformExecutorWithLocks(platformAdapter, actionExecutor, MachineLocks)
+
+	// Create the job store selected by the storage configuration
+	jobStore, err := NewJobStore(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to initialize job store, jobs will not be persisted")
+	}
 
-	// Create job service with configured worker pool size
-	JobService = scheduler.NewJobService(validator, executor)
+	// Create job service with configured worker pool size (persisted jobs are loaded here)
+	JobService = scheduler.NewJobServiceWithStore(validator, executor, jobStore)
 	
 	// Set worker pool size from configuration
 	if err := JobService.SetWorkerPoolSize(cfg.WorkerPoolSize); err != nil {
 		log.Warn().Err(err).Msg("Failed to set worker pool size")
 	}
+
+	// Set execution history retention from configuration
+	maxAge := time.Duration(cfg.ExecutionHistoryMaxAgeDays) * 24 * time.Hour
+	if err := JobService.SetExecutionHistoryRetention(cfg.ExecutionHistoryLimit, maxAge); err != nil {
+		log.Warn().Err(err).Msg("Failed to set execution history retention")
+	}
+
+	// Set run queue misfire handling from configuration
+	misfireThreshold := time.Duration(cfg.MisfireThresholdSeconds) * time.Second
+	if err := JobService.SetMisfirePolicy(scheduler.MisfirePolicy(cfg.MisfirePolicy), misfireThreshold); err != nil {
+		log.Warn().Err(err).Msg("Failed to set misfire policy")
+	}
+
+	// Set machine locking from configuration
+	lockWaitTimeout := time.Duration(cfg.LockWaitTimeoutSeconds) * time.Second
+	if err := MachineLocks.SetSettings(scheduler.LockPolicy(cfg.LockPolicy), lockWaitTimeout, scheduler.LockScope(cfg.LockScope)); err != nil {
+		log.Warn().Err(err).Msg("Failed to set machine lock settings")
+	}
+
+	// Set schedule conflict detection from configuration
+	conflictWindow := time.Duration(cfg.ConflictWindowSeconds) * time.Second
+	conflictHorizon := time.Duration(cfg.ConflictHorizonDays) * 24 * time.Hour
+	if err := JobService.SetConflictSettings(conflictWindow, conflictHorizon); err != nil {
+		log.Warn().Err(err).Msg("Failed to set conflict detection settings")
+	}
 }
 
 // ========== Job Service Routes ==========
@@ -281,6 +1264,7 @@ func JobServiceRoutes(router *gin.Engine, cfg *config.Config) {
 	// JobService root
 	router.GET("/MultiFish/v1/JobService", getJobServiceRoot)
 	router.PATCH("/MultiFish/v1/JobService", patchJobServiceRoot)
+	router.POST("/MultiFish/v1/JobService/Actions/PreviewSchedule", previewSchedule)
 
 	// Jobs collection
 	router.GET("/MultiFish/v1/JobService/Jobs", getJobsCollection)
@@ -288,6 +1272,33 @@ func JobServiceRoutes(router *gin.Engine, cfg *config.Config) {
 
 	// Individual job
 	router.GET("/MultiFish/v1/JobService/Jobs/:jobId", getJob)
+	router.PATCH("/MultiFish/v1/JobService/Jobs/:jobId", patchJob)
 	router.DELETE("/MultiFish/v1/JobService/Jobs/:jobId", deleteJob)
 	router.POST("/MultiFish/v1/JobService/Jobs/:jobId/Actions/Cancel", cancelJob)
+	router.POST("/MultiFish/v1/JobService/Jobs/:jobId/Actions/Pause", pauseJob)
+	router.POST("/MultiFish/v1/JobService/Jobs/:jobId/Actions/Resume", resumeJob)
+	router.POST("/MultiFish/v1/JobService/Jobs/:jobId/Actions/Run", runJob)
+
+	// Job execution history
+	router.GET("/MultiFish/v1/JobService/Jobs/:jobId/Executions", getJobExecutions)
+	router.GET("/MultiFish/v1/JobService/Jobs/:jobId/Executions/:executionId", getJobExecution)
+
+	// Blackout calendars
+	router.GET("/MultiFish/v1/JobService/Calendars", getCalendarsCollection)
+	router.POST("/MultiFish/v1/JobService/Calendars", createCalendar)
+	router.GET("/MultiFish/v1/JobService/Calendars/:calendarId", getCalendar)
+	router.DELETE("/MultiFish/v1/JobService/Calendars/:calendarId", deleteCalendar)
+
+	// Job templates
+	router.GET("/MultiFish/v1/JobService/JobTemplates", getTemplatesCollection)
+	router.POST("/MultiFish/v1/JobService/JobTemplates", createTemplate)
+	router.GET("/MultiFish/v1/JobService/JobTemplates/:templateId", getTemplate)
+	router.DELETE("/MultiFish/v1/JobService/JobTemplates/:templateId", deleteTemplate)
+	router.POST("/MultiFish/v1/JobService/JobTemplates/:templateId/Actions/Instantiate", instantiateTemplate)
+
+	// Machine locks
+	router.GET("/MultiFish/v1/JobService/Locks", getLocksCollection)
+
+	// Upcoming runs and conflicts
+	router.GET("/MultiFish/v1/JobService/Schedule", getSchedule)
 }
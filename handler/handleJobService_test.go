@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -85,6 +86,7 @@ func TestGetJobServiceRoot(t *testing.T) {
 	assert.Equal(t, float64(99), serviceCapabilities["AvailableWorkers"])
 	assert.Equal(t, float64(0), serviceCapabilities["TotalJobs"])
 	assert.Equal(t, float64(0), serviceCapabilities["RunningJobs"])
+	assert.Equal(t, float64(50), serviceCapabilities["ExecutionHistoryLimit"])
 }
 
 func TestPatchJobServiceRoot(t *testing.T) {
@@ -466,6 +468,265 @@ func TestCancelNonExistentJob(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+func TestPatchNonExistentJob(t *testing.T) {
+	router := setupJobServiceTestRouter()
+
+	req, _ := http.NewRequest("PATCH", "/MultiFish/v1/JobService/Jobs/non-existent-job",
+		bytes.NewBufferString(`{"Name": "Renamed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestJobActionsNonExistentJob(t *testing.T) {
+	router := setupJobServiceTestRouter()
+
+	for _, action := range []string{"Pause", "Resume", "Run"} {
+		req, _ := http.NewRequest("POST", "/MultiFish/v1/JobService/Jobs/non-existent-job/Actions/"+action, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, action)
+	}
+}
+
+func TestGetExecutionsNonExistentJob(t *testing.T) {
+	router := setupJobServiceTestRouter()
+
+	req, _ := http.NewRequest("GET", "/MultiFish/v1/JobService/Jobs/non-existent-job/Executions", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("GET", "/MultiFish/v1/JobService/Jobs/non-existent-job/Executions/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFormatExecutionResponse(t *testing.T) {
+	execution := &scheduler.ExecutionHistory{
+		ID:            "3",
+		JobID:         "Job-1",
+		ExecutionTime: time.Date(2026, 2, 9, 15, 43, 49, 0, time.UTC),
+		Status:        scheduler.JobStatusFailed,
+		Results: []scheduler.MachineExecutionResult{
+			{MachineID: "machine-1", Success: true, Duration: "1s"},
+			{MachineID: "machine-2", Success: false, Error: "connection refused", Duration: "2s"},
+		},
+	}
+
+	response := formatExecutionResponse(execution)
+
+	assert.Equal(t, "/MultiFish/v1/JobService/Jobs/Job-1/Executions/3", response["@odata.id"])
+	assert.Equal(t, "3", response["Id"])
+	assert.Equal(t, "Job-1", response["JobId"])
+	assert.Equal(t, "2026-02-09T15:43:49Z", response["ExecutionTime"])
+	assert.Equal(t, scheduler.JobStatusFailed, response["Status"])
+	assert.Len(t, response["Results"], 2)
+
+	// An execution without results still returns an empty list
+	execution.Results = nil
+	response = formatExecutionResponse(execution)
+	assert.NotNil(t, response["Results"])
+}
+
+func TestCalendarsCRUD(t *testing.T) {
+	router := setupJobServiceTestRouter()
+
+	body := `{
+		"Id": "maintenance",
+		"Name": "Maintenance freeze",
+		"Global": true,
+		"TimeZone": "UTC",
+		"Windows": [
+			{"Start": "2026-12-24T00:00:00Z", "End": "2026-12-27T00:00:00Z"},
+			{"Recurrence": {"DaysOfWeek": ["Saturday", "Sunday"]}}
+		]
+	}`
+	req, _ := http.NewRequest("POST", "/MultiFish/v1/JobService/Calendars", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "/MultiFish/v1/JobService/Calendars/maintenance", created["@odata.id"])
+	assert.Equal(t, true, created["Global"])
+	assert.Len(t, created["Windows"], 2)
+
+	// Duplicate IDs conflict
+	req, _ = http.NewRequest("POST", "/MultiFish/v1/JobService/Calendars", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Invalid windows are rejected
+	req, _ = http.NewRequest("POST", "/MultiFish/v1/JobService/Calendars",
+		bytes.NewBufferString(`{"Windows": [{"Recurrence": {"StartTime": "18:00:00", "EndTime": "06:00:00"}}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest("GET", "/MultiFish/v1/JobService/Calendars", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var collection map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &collection))
+	assert.Equal(t, float64(1), collection["Members@odata.count"])
+
+	req, _ = http.NewRequest("DELETE", "/MultiFish/v1/JobService/Calendars/maintenance", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/MultiFish/v1/JobService/Calendars/maintenance", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetSchedule(t *testing.T) {
+	router := setupJobServiceTestRouter()
+
+	req, _ := http.NewRequest("GET", "/MultiFish/v1/JobService/Schedule?from=2026-02-10T00:00:00Z&to=2026-02-11T00:00:00Z&window=5m", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "/MultiFish/v1/JobService/Schedule", response["@odata.id"])
+	assert.Equal(t, "5m0s", response["ConflictWindow"])
+	assert.Equal(t, "2026-02-10T00:00:00Z", response["From"])
+	assert.NotNil(t, response["Runs"])
+	assert.NotNil(t, response["Conflicts"])
+
+	for _, query := range []string{
+		"?from=yesterday",
+		"?window=often",
+		"?from=2026-02-11T00:00:00Z&to=2026-02-10T00:00:00Z",
+		"?from=2026-02-01T00:00:00Z&to=2026-04-01T00:00:00Z",
+	} {
+		req, _ = http.NewRequest("GET", "/MultiFish/v1/JobService/Schedule"+query, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	router := setupJobServiceTestRouter()
+
+	body := `{
+		"Schedule": {"Type": "Cron", "Cron": "0 9 * * MON-FRI"},
+		"TimeZone": "Asia/Taipei",
+		"ReferenceTime": "2026-02-13T00:00:00Z",
+		"Count": 2
+	}`
+	req, _ := http.NewRequest("POST", "/MultiFish/v1/JobService/Actions/PreviewSchedule", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Asia/Taipei", response["TimeZone"])
+	assert.Equal(t, []interface{}{"2026-02-13T09:00:00+08:00", "2026-02-16T09:00:00+08:00"}, response["RunTimes"])
+
+	for _, body := range []string{
+		`{"TimeZone": "UTC"}`,
+		`{"Schedule": {"Type": "Cron", "Cron": "not a cron"}}`,
+		`{"Schedule": {"Type": "Cron", "Cron": "0 9 * * *"}, "Count": 1000}`,
+	} {
+		req, _ = http.NewRequest("POST", "/MultiFish/v1/JobService/Actions/PreviewSchedule", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestJobTemplates(t *testing.T) {
+	router := setupJobServiceTestRouter()
+
+	body := `{
+		"Id": "rack-profile",
+		"Parameters": [
+			{"Name": "Rack", "Type": "String"},
+			{"Name": "Time", "Type": "String", "Default": "08:00:00"}
+		],
+		"Job": {
+			"Name": "Performance {{Rack}}",
+			"Machines": ["machine-1"],
+			"Action": "PatchProfile",
+			"Payload": [{"ManagerID": "bmc", "Payload": {"Profile": "Performance"}}],
+			"Schedule": {"Type": "Continuous", "Time": "{{Time}}", "Period": {"DaysOfWeek": ["Monday"]}}
+		}
+	}`
+	req, _ := http.NewRequest("POST", "/MultiFish/v1/JobService/JobTemplates", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "/MultiFish/v1/JobService/JobTemplates/rack-profile", created["@odata.id"])
+
+	// Undeclared placeholders are rejected
+	req, _ = http.NewRequest("POST", "/MultiFish/v1/JobService/JobTemplates",
+		bytes.NewBufferString(`{"Job": {"Name": "{{Rack}}"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Instantiating checks the parameter types
+	req, _ = http.NewRequest("POST", "/MultiFish/v1/JobService/JobTemplates/rack-profile/Actions/Instantiate",
+		bytes.NewBufferString(`{"Parameters": {"Rack": 12}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "expected a value of type String")
+
+	// The job goes through the normal validation, machine-1 is not connected
+	req, _ = http.NewRequest("POST", "/MultiFish/v1/JobService/JobTemplates/rack-profile/Actions/Instantiate",
+		bytes.NewBufferString(`{"Parameters": {"Rack": "R12"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "JobValidationFailed")
+
+	req, _ = http.NewRequest("POST", "/MultiFish/v1/JobService/JobTemplates/missing/Actions/Instantiate", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/MultiFish/v1/JobService/JobTemplates/rack-profile", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/MultiFish/v1/JobService/JobTemplates", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Members@odata.count":0`)
+}
+
 func TestJobValidation(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1,15 +1,21 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
 	"github.com/stmcginnis/gofish/redfish"
 
 	"multifish/utility"
 	"multifish/providers"
+	"multifish/scheduler"
 	redfishprovider "multifish/providers/redfish"
 	extendprovider "multifish/providers/extend"
 )
@@ -65,7 +71,7 @@ func getManagerByService(machine *MachineConnection, managerID string) (interfac
 		log.Error().Msgf("failed to get managers from machine %s: %v", machine.Config.ID, managersErr)
 		return nil, &utility.ResponseError{
 			StatusCode: http.StatusInternalServerError,
-			Error:      fmt.Errorf("failed to get managers: %v", managersErr),
+			Error:      fmt.Errorf("failed to get managers: %w", managersErr),
 			Message:    "InternalError",
 		}
 	}
@@ -114,57 +120,189 @@ func getManagersCallback(c *gin.Context, machine *MachineConnection, managerID s
 
 // ========= MachineActionExecutor implementation ==========
 
-func (m *MachineActionExecutorAdapter) GetManagerByService(machine interface{}, managerID string) (interface{}, error) {
+// bmcStatusError converts a provider error for the scheduler. The error keeps
+// the HTTP status code the BMC answered with, so job retries can tell transient
+// BMC failures (5xx) from requests that will never succeed (4xx). Transport
+// errors such as timeouts stay in the chain for scheduler.ClassifyError.
+func bmcStatusError(respErr *utility.ResponseError) error {
+	statusCode := respErr.StatusCode
+	var redfishErr *common.Error
+	if errors.As(respErr.Error, &redfishErr) && redfishErr.HTTPReturnedStatusCode != 0 {
+		statusCode = redfishErr.HTTPReturnedStatusCode
+	}
+	return scheduler.NewStatusError(statusCode, respErr.Error)
+}
+
+// runWithContext runs a BMC request for a job. Managers returned by
+// GetManagerByService send their requests with the job's context, so a
+// cancelled or timed out job aborts the request in flight and ctx's error is
+// returned.
+func runWithContext(ctx context.Context, request func() *utility.ResponseError) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if respErr := request(); respErr != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return bmcStatusError(respErr)
+	}
+	return nil
+}
+
+func (m *MachineActionExecutorAdapter) GetManagerByService(ctx context.Context, machine interface{}, managerID string) (interface{}, error) {
 	machineConn, ok := machine.(*MachineConnection)
 	if !ok {
 		return nil, fmt.Errorf("invalid machine type: expected *MachineConnection, got %T", machine)
 	}
 	
-	manager, respErr := getManagerByService(machineConn, managerID)
-	if respErr != nil {
-		return nil, respErr.Error
+	var manager interface{}
+	err := runWithContext(ctx, func() *utility.ResponseError {
+		var respErr *utility.ResponseError
+		manager, respErr = getManagerByService(machineConn.withContext(ctx), managerID)
+		return respErr
+	})
+	if err != nil {
+		return nil, err
 	}
 	return manager, nil
 }
 
-func (m *MachineActionExecutorAdapter) PatchManager(manager interface{}, patch interface{}) error {
-	respErr := PatchManager(manager, patch)
-	if respErr != nil {
-		return respErr.Error
+func (m *MachineActionExecutorAdapter) PatchManager(ctx context.Context, manager interface{}, patch interface{}) error {
+	return runWithContext(ctx, func() *utility.ResponseError {
+		return PatchManager(manager, patch)
+	})
+}
+
+func (m *MachineActionExecutorAdapter) PatchProfile(ctx context.Context, manager interface{}, patch extendprovider.PatchProfileType) error {
+	return runWithContext(ctx, func() *utility.ResponseError {
+		return PatchProfile(manager, patch)
+	})
+}
+
+func (m *MachineActionExecutorAdapter) PatchFanController(ctx context.Context, manager interface{}, fanControllerID string, patch *extendprovider.PatchFanControllerType) error {
+	return runWithContext(ctx, func() *utility.ResponseError {
+		return PatchFanController(manager, fanControllerID, patch)
+	})
+}
+
+func (m *MachineActionExecutorAdapter) PatchFanZone(ctx context.Context, manager interface{}, fanZoneID string, patch *extendprovider.PatchFanZoneType) error {
+	return runWithContext(ctx, func() *utility.ResponseError {
+		return PatchFanZone(manager, fanZoneID, patch)
+	})
+}
+
+func (m *MachineActionExecutorAdapter) PatchPidController(ctx context.Context, manager interface{}, pidControllerID string, patch *extendprovider.PatchPidControllerType) error {
+	return runWithContext(ctx, func() *utility.ResponseError {
+		return PatchPidController(manager, pidControllerID, patch)
+	})
+}
+
+// GetFanSettings reads the fan settings the manager was fetched with, so no BMC
+// request is made
+func (m *MachineActionExecutorAdapter) GetFanSettings(ctx context.Context, manager interface{}) (*extendprovider.OpenBmcFan, error) {
+	em, ok := manager.(*extendprovider.ExtendManager)
+	if !ok {
+		return nil, scheduler.NewStatusError(http.StatusNotImplemented, fmt.Errorf("manager type %T has no Oem.OpenBmc.Fan settings", manager))
 	}
-	return nil
+	if em.OpenBmcFan == nil {
+		return nil, scheduler.NewStatusError(http.StatusNotFound, fmt.Errorf("OpenBmcFan is not available"))
+	}
+	return em.OpenBmcFan, nil
 }
 
-func (m *MachineActionExecutorAdapter) PatchProfile(manager interface{}, patch extendprovider.PatchProfileType) error {
-	respErr := PatchProfile(manager, patch)
-	if respErr != nil {
-		return respErr.Error
+// GetManagerSettings reads the ServiceIdentification the manager was fetched with
+func (m *MachineActionExecutorAdapter) GetManagerSettings(ctx context.Context, manager interface{}) (*redfishprovider.PatchManagerType, error) {
+	switch mgr := manager.(type) {
+	case *redfish.Manager:
+		return &redfishprovider.PatchManagerType{ServiceIdentification: mgr.ServiceIdentification}, nil
+	case *extendprovider.ExtendManager:
+		return &redfishprovider.PatchManagerType{ServiceIdentification: mgr.GetManager().ServiceIdentification}, nil
+	default:
+		return nil, scheduler.NewStatusError(http.StatusNotImplemented, fmt.Errorf("unsupported manager type: %T", manager))
 	}
-	return nil
 }
 
-func (m *MachineActionExecutorAdapter) PatchFanController(manager interface{}, fanControllerID string, patch *extendprovider.PatchFanControllerType) error {
-	respErr := PatchFanController(manager, fanControllerID, patch)
-	if respErr != nil {
-		return respErr.Error
+// ========== Verification of PATCH requests ==========
+
+// verifyQuery parses the optional ?verify=true&tolerance=<number> query of a
+// PATCH request. It writes a 400 response and returns ok=false when invalid.
+func verifyQuery(c *gin.Context) (verify bool, tolerance float64, ok bool) {
+	tolerance = scheduler.DefaultVerifyTolerance
+
+	if value := c.Query("verify"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utility.RedfishError(c, http.StatusBadRequest, fmt.Sprintf("invalid verify query parameter '%s': use true or false", value), "QueryParameterValueFormatError")
+			return false, 0, false
+		}
+		verify = parsed
 	}
-	return nil
+
+	if value := c.Query("tolerance"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || math.IsNaN(parsed) {
+			utility.RedfishError(c, http.StatusBadRequest, fmt.Sprintf("invalid tolerance query parameter '%s': use a number of 0 or greater", value), "QueryParameterValueFormatError")
+			return false, 0, false
+		}
+		if !verify {
+			utility.RedfishError(c, http.StatusBadRequest, "the tolerance query parameter requires verify=true", "QueryParameterValueFormatError")
+			return false, 0, false
+		}
+		tolerance = parsed
+	}
+
+	return verify, tolerance, true
 }
 
-func (m *MachineActionExecutorAdapter) PatchFanZone(manager interface{}, fanZoneID string, patch *extendprovider.PatchFanZoneType) error {
-	respErr := PatchFanZone(manager, fanZoneID, patch)
-	if respErr != nil {
-		return respErr.Error
+// verifyPatch re-fetches the manager after a PATCH and compares the applied
+// values with the requested ones, the same way Verify jobs do. It writes a 502
+// VerificationFailed response and returns false when they differ or cannot be
+// read back.
+func verifyPatch(c *gin.Context, machine *MachineConnection, action scheduler.ActionType, payload scheduler.Payload, tolerance float64) bool {
+	actionExecutor := scheduler.NewDefaultActionExecutor(&MachineActionExecutorAdapter{})
+	err := actionExecutor.VerifyApplied(c.Request.Context(), machine, action, payload, tolerance)
+	if err == nil {
+		return true
 	}
-	return nil
+
+	message := fmt.Sprintf("the PATCH was accepted but the applied values could not be verified: %v", err)
+	var mismatch *scheduler.VerificationError
+	if errors.As(err, &mismatch) {
+		message = fmt.Sprintf("the PATCH was accepted but the %s", err)
+	}
+	utility.RedfishError(c, http.StatusBadGateway, message, "VerificationFailed")
+	return false
 }
 
-func (m *MachineActionExecutorAdapter) PatchPidController(manager interface{}, pidControllerID string, patch *extendprovider.PatchPidControllerType) error {
-	respErr := PatchPidController(manager, pidControllerID, patch)
-	if respErr != nil {
-		return respErr.Error
+// ========== Machine locks of PATCH requests ==========
+
+// lockPatch takes the machine lock for a PATCH request, so it does not change
+// a machine while a job or another request is changing it. The service lock
+// policy applies unless the request sets ?lockPolicy=Wait|FailFast|Queue. It
+// writes a 409 ResourceInUse response and returns ok=false when the machine
+// stays locked.
+func lockPatch(c *gin.Context, machineID string, managerID string) (release func(), ok bool) {
+	policy := scheduler.LockPolicy(c.Query("lockPolicy"))
+	if policy != "" {
+		if err := scheduler.ValidateLockPolicy(policy); err != nil {
+			utility.RedfishError(c, http.StatusBadRequest, fmt.Sprintf("invalid lockPolicy query parameter: %v", err), "QueryParameterValueNotInList")
+			return nil, false
+		}
 	}
-	return nil
+
+	owner := scheduler.LockOwner{Operation: fmt.Sprintf("%s %s", c.Request.Method, c.Request.URL.Path)}
+	release, err := MachineLocks.Acquire(c.Request.Context(), machineID, []string{managerID}, owner, policy)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrMachineLocked) {
+			utility.RedfishError(c, http.StatusConflict, err.Error(), "ResourceInUse")
+		} else {
+			utility.RedfishError(c, http.StatusServiceUnavailable, fmt.Sprintf("the request ended while waiting for the machine lock: %v", err), "ServiceTemporarilyUnavailable")
+		}
+		return nil, false
+	}
+	return release, true
 }
 
 // ========== /MultiFish/v1/Platform/:machineId/Managers ==========
@@ -235,16 +373,36 @@ func patchManager(c *gin.Context) {
 		return
 	}
 
+	verify, tolerance, ok := verifyQuery(c)
+	if !ok {
+		return
+	}
+
+	release, ok := lockPatch(c, machineID, managerID)
+	if !ok {
+		return
+	}
+	defer release()
+
 	getManagersCallback(c, machine, managerID, func(targetManager interface{}) {
 
 		if respErr := ManagerProviders.PatchManager(targetManager, &updates); respErr != nil {
 			utility.RedfishError(c, respErr.StatusCode, respErr.Error.Error(), respErr.Message)
+			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"@odata.id": fmt.Sprintf("/MultiFish/v1/Platform/%s/Managers/%s", machineID, managerID),
 			"Message":   "Manager updated successfully",
-		})
+		}
+		if verify {
+			payload := []scheduler.ExecutePatchManagerPayload{{ManagerID: managerID, Payload: updates}}
+			if !verifyPatch(c, machine, scheduler.ActionPatchManager, payload, tolerance) {
+				return
+			}
+			response["Verified"] = true
+		}
+		c.JSON(http.StatusOK, response)
 	})
 }
 
@@ -291,6 +449,17 @@ func patchProfile(c *gin.Context) {
 		return
 	}
 
+	verify, tolerance, ok := verifyQuery(c)
+	if !ok {
+		return
+	}
+
+	release, ok := lockPatch(c, machineID, managerID)
+	if !ok {
+		return
+	}
+	defer release()
+
 	getManagersCallback(c, machine, managerID, func(targetManager interface{}) {
 
 		if respErr := ManagerProviders.PatchProfile(targetManager, updates); respErr != nil {
@@ -298,10 +467,18 @@ func patchProfile(c *gin.Context) {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"@odata.id": fmt.Sprintf("/MultiFish/v1/Platform/%s/Managers/%s/Oem/OpenBmc/Fan/Profile", machineID, managerID),
 			"Message":   "Profile updated successfully",
-		})
+		}
+		if verify {
+			payload := []scheduler.ExecutePatchProfilePayload{{ManagerID: managerID, Payload: updates}}
+			if !verifyPatch(c, machine, scheduler.ActionPatchProfile, payload, tolerance) {
+				return
+			}
+			response["Verified"] = true
+		}
+		c.JSON(http.StatusOK, response)
 	})
 }
 
@@ -376,6 +553,17 @@ func patchFanController(c *gin.Context) {
 		return
 	}
 
+	verify, tolerance, ok := verifyQuery(c)
+	if !ok {
+		return
+	}
+
+	release, ok := lockPatch(c, machineID, managerID)
+	if !ok {
+		return
+	}
+	defer release()
+
 	getManagersCallback(c, machine, managerID, func(targetManager interface{}) {
 
 		if respErr := PatchFanController(targetManager, fanControllerID, &fcPatch); respErr != nil {
@@ -383,10 +571,18 @@ func patchFanController(c *gin.Context) {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"@odata.id": fmt.Sprintf("/MultiFish/v1/Platform/%s/Managers/%s/Oem/OpenBmc/Fan/FanControllers/%s", machineID, managerID, fanControllerID),
 			"Message":   "FanController updated successfully",
-		})
+		}
+		if verify {
+			payload := []scheduler.ExecutePatchFanControllerPayload{{ManagerID: managerID, FanControllerID: fanControllerID, Payload: fcPatch}}
+			if !verifyPatch(c, machine, scheduler.ActionPatchFanController, payload, tolerance) {
+				return
+			}
+			response["Verified"] = true
+		}
+		c.JSON(http.StatusOK, response)
 	})
 }
 
@@ -461,6 +657,17 @@ func patchFanZone(c *gin.Context) {
 		return
 	}
 
+	verify, tolerance, ok := verifyQuery(c)
+	if !ok {
+		return
+	}
+
+	release, ok := lockPatch(c, machineID, managerID)
+	if !ok {
+		return
+	}
+	defer release()
+
 	getManagersCallback(c, machine, managerID, func(targetManager interface{}) {
 
 		if respErr := PatchFanZone(targetManager, fanZoneID, &fzPatch); respErr != nil {
@@ -468,10 +675,18 @@ func patchFanZone(c *gin.Context) {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"@odata.id": fmt.Sprintf("/MultiFish/v1/Platform/%s/Managers/%s/Oem/OpenBmc/Fan/FanZones/%s", machineID, managerID, fanZoneID),
 			"Message":   "FanZone updated successfully",
-		})
+		}
+		if verify {
+			payload := []scheduler.ExecutePatchFanZonePayload{{ManagerID: managerID, FanZoneID: fanZoneID, Payload: fzPatch}}
+			if !verifyPatch(c, machine, scheduler.ActionPatchFanZone, payload, tolerance) {
+				return
+			}
+			response["Verified"] = true
+		}
+		c.JSON(http.StatusOK, response)
 	})
 }
 
@@ -546,6 +761,17 @@ func patchPidController(c *gin.Context) {
 		return
 	}
 
+	verify, tolerance, ok := verifyQuery(c)
+	if !ok {
+		return
+	}
+
+	release, ok := lockPatch(c, machineID, managerID)
+	if !ok {
+		return
+	}
+	defer release()
+
 	getManagersCallback(c, machine, managerID, func(targetManager interface{}) {
 
 		if respErr := PatchPidController(targetManager, pidControllerID, &pcPatch); respErr != nil {
@@ -553,10 +779,18 @@ func patchPidController(c *gin.Context) {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"@odata.id": fmt.Sprintf("/MultiFish/v1/Platform/%s/Managers/%s/Oem/OpenBmc/Fan/PidControllers/%s", machineID, managerID, pidControllerID),
 			"Message":   "PidController updated successfully",
-		})
+		}
+		if verify {
+			payload := []scheduler.ExecutePatchPidControllerPayload{{ManagerID: managerID, PidControllerID: pidControllerID, Payload: pcPatch}}
+			if !verifyPatch(c, machine, scheduler.ActionPatchPidController, payload, tolerance) {
+				return
+			}
+			response["Verified"] = true
+		}
+		c.JSON(http.StatusOK, response)
 	})
 }
 
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"multifish/scheduler"
+	extendprovider "multifish/providers/extend"
+)
+
+func TestVerifyQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedOK   bool
+		expectVerify bool
+		expectedTol  float64
+	}{
+		{name: "no query", query: "", expectedOK: true, expectVerify: false, expectedTol: scheduler.DefaultVerifyTolerance},
+		{name: "verify", query: "?verify=true", expectedOK: true, expectVerify: true, expectedTol: scheduler.DefaultVerifyTolerance},
+		{name: "verify with tolerance", query: "?verify=true&tolerance=0.5", expectedOK: true, expectVerify: true, expectedTol: 0.5},
+		{name: "invalid verify", query: "?verify=maybe", expectedOK: false},
+		{name: "negative tolerance", query: "?verify=true&tolerance=-1", expectedOK: false},
+		{name: "tolerance without verify", query: "?tolerance=0.5", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("PATCH", "/MultiFish/v1/Platform/machine-1/Managers/bmc/Oem/OpenBmc/Fan/Profile"+tt.query, nil)
+
+			verify, tolerance, ok := verifyQuery(c)
+			assert.Equal(t, tt.expectedOK, ok)
+			if !tt.expectedOK {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+			assert.Equal(t, tt.expectVerify, verify)
+			assert.Equal(t, tt.expectedTol, tolerance)
+		})
+	}
+}
+
+func TestLockPatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(query string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("PATCH", "/MultiFish/v1/Platform/lock-machine/Managers/bmc/Oem/OpenBmc/Fan/Profile"+query, nil)
+		return c, w
+	}
+
+	c, _ := newContext("")
+	release, ok := lockPatch(c, "lock-machine", "bmc")
+	assert.True(t, ok)
+
+	locks := MachineLocks.GetLocks()
+	assert.Len(t, locks, 1)
+	assert.Equal(t, "PATCH /MultiFish/v1/Platform/lock-machine/Managers/bmc/Oem/OpenBmc/Fan/Profile", locks[0].Owner.Operation)
+
+	c, w := newContext("?lockPolicy=FailFast")
+	_, ok = lockPatch(c, "lock-machine", "bmc")
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	c, w = newContext("?lockPolicy=Block")
+	_, ok = lockPatch(c, "lock-machine", "bmc")
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	release()
+	assert.Empty(t, MachineLocks.GetLocks())
+}
+
+// newFakeBMC starts a minimal Redfish service with one OpenBMC manager "bmc".
+// PATCH requests to the manager are answered by patch.
+func newFakeBMC(t *testing.T, patch http.HandlerFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redfish/v1/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"@odata.id": "/redfish/v1/", "Managers": {"@odata.id": "/redfish/v1/Managers"},
+			"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`)
+	})
+	mux.HandleFunc("/redfish/v1/SessionService/Sessions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Auth-Token", "token")
+		w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/redfish/v1/Managers", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Members": [{"@odata.id": "/redfish/v1/Managers/bmc"}], "Members@odata.count": 1}`)
+	})
+	mux.HandleFunc("/redfish/v1/Managers/bmc", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			patch(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"@odata.id": "/redfish/v1/Managers/bmc", "Id": "bmc",
+			"Oem": {"OpenBmc": {"Fan": {"Profile": "Balanced", "Profile@Redfish.AllowableValues": ["Balanced", "Performance"]}}}}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// connectFakeBMC connects to a fake BMC as an Extend machine
+func connectFakeBMC(t *testing.T, server *httptest.Server, timeout int) *MachineConnection {
+	connection, err := connectMachine(MachineConfig{
+		ID:                "fake-bmc",
+		Type:              string(ServiceTypeExtend),
+		Endpoint:          server.URL,
+		Username:          "admin",
+		Password:          "password",
+		HTTPClientTimeout: timeout,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { closeMachineConnection(connection) })
+	return connection
+}
+
+func TestMachineActionExecutorAdapter_BMCErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		patch         http.HandlerFunc
+		expectedCode  int
+		expectedClass scheduler.ErrorClass
+	}{
+		{
+			name: "bad request is not retried",
+			patch: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": {"code": "Base.1.0.PropertyValueNotInList", "message": "bad value"}}`)
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedClass: scheduler.ErrorClassClientError,
+		},
+		{
+			name: "unavailable BMC is retried",
+			patch: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedClass: scheduler.ErrorClassServerError,
+		},
+		{
+			name: "slow BMC times out",
+			patch: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.ReadAll(r.Body)
+				select {
+				case <-r.Context().Done():
+				case <-time.After(2 * time.Second):
+				}
+			},
+			expectedClass: scheduler.ErrorClassTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeBMC(t, tt.patch)
+
+			connection := connectFakeBMC(t, server, 1)
+
+			adapter := &MachineActionExecutorAdapter{}
+			manager, err := adapter.GetManagerByService(context.Background(), connection, "bmc")
+			require.NoError(t, err)
+
+			err = adapter.PatchProfile(context.Background(), manager, extendprovider.PatchProfileType{Profile: "Performance"})
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedClass, scheduler.ClassifyError(err), "error: %v", err)
+
+			if tt.expectedCode != 0 {
+				var statusErr *scheduler.StatusError
+				require.True(t, errors.As(err, &statusErr))
+				assert.Equal(t, tt.expectedCode, statusErr.StatusCode)
+			}
+		})
+	}
+}
+
+func TestMachineActionExecutorAdapter_CancelAbortsRequest(t *testing.T) {
+	aborted := make(chan struct{})
+	server := newFakeBMC(t, func(w http.ResponseWriter, r *http.Request) {
+		// The server notices a closed connection once the body is read
+		_, _ = io.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			close(aborted)
+		case <-time.After(5 * time.Second):
+		}
+	})
+	connection := connectFakeBMC(t, server, 30)
+
+	adapter := &MachineActionExecutorAdapter{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager, err := adapter.GetManagerByService(ctx, connection, "bmc")
+	require.NoError(t, err)
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	err = adapter.PatchProfile(ctx, manager, extendprovider.PatchProfileType{Profile: "Performance"})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The BMC sees the request go away rather than completing it later
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the PATCH request was not aborted on the BMC side")
+	}
+
+	// The machine's own connection is not bound to the cancelled job
+	_, err = adapter.GetManagerByService(context.Background(), connection, "bmc")
+	assert.NoError(t, err)
+}
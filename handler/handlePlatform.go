@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
 	"sync"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stmcginnis/gofish"
 
+	"multifish/config"
 	"multifish/utility"
 	extendprovider "multifish/providers/extend"
 )
@@ -36,6 +39,13 @@ func GetService(machine *MachineConnection) (interface{}, *utility.ResponseError
 	log := utility.GetLogger()
 
 	switch {
+	case !machine.Connected():
+		log.Warn().Msgf("machine %s is disconnected: %s", machine.Config.ID, machine.ConnectionError)
+		return nil, &utility.ResponseError{
+			StatusCode: http.StatusServiceUnavailable,
+			Error:      fmt.Errorf("machine %s is disconnected: %s", machine.Config.ID, machine.ConnectionError),
+			Message:    "ServiceUnavailable",
+		}
 	case machine.Config.Type == string(ServiceTypeBase) && machine.BaseService != nil:
 		return machine.BaseService, nil
 	case machine.Config.Type == string(ServiceTypeExtend) && machine.ExtendService != nil:
@@ -86,18 +96,56 @@ const (
 
 // MachineConnection represents an active connection to a BMC
 type MachineConnection struct {
-	Config         MachineConfig
-	Client         *gofish.APIClient
-	BaseService    *gofish.Service
-	ExtendService  *extendprovider.ExtendService
+	Config          MachineConfig
+	Client          *gofish.APIClient
+	BaseService     *gofish.Service
+	ExtendService   *extendprovider.ExtendService
+	ConnectionError string // Last connection error for machines restored while their BMC was unreachable
+}
+
+// Connected reports whether the machine has an active Redfish session
+func (mc *MachineConnection) Connected() bool {
+	return mc.Client != nil
+}
+
+// withContext returns a copy of the connection whose BMC requests are bound to
+// ctx, so cancelling ctx aborts a request in flight instead of abandoning it.
+// The copy shares the session and HTTP transport of the machine.
+func (mc *MachineConnection) withContext(ctx context.Context) *MachineConnection {
+	if !mc.Connected() {
+		return mc
+	}
+
+	httpClient := *mc.Client.HTTPClient
+	httpClient.Transport = &contextTransport{ctx: ctx, base: httpClient.Transport}
+
+	client := *mc.Client
+	client.HTTPClient = &httpClient
+	service := *mc.Client.Service
+	service.SetClient(&client)
+	client.Service = &service
+
+	connection := *mc
+	connection.Client = &client
+	if mc.ExtendService != nil {
+		connection.ExtendService = extendprovider.NewExtendService(&client)
+	}
+	if mc.BaseService != nil {
+		connection.BaseService = client.Service
+	}
+	return &connection
 }
 
 // PlatformManager manages multiple BMC connections
 type PlatformManager struct {
 	machines map[string]*MachineConnection
 	mu       sync.RWMutex
+	store    MachineStore // Optional persistence backend, nil keeps machines in memory only
 }
 
+// restoreConcurrency bounds the number of BMCs contacted in parallel during startup restore
+const restoreConcurrency = 16
+
 // PlatformMgr is the global platform manager
 var PlatformMgr = &PlatformManager{
 	machines: make(map[string]*MachineConnection),
@@ -105,13 +153,53 @@ var PlatformMgr = &PlatformManager{
 
 // ========== PlatformManager Methods ==========
 
+// SetStore sets the persistence backend used to write machine changes through
+func (pm *PlatformManager) SetStore(store MachineStore) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.store = store
+}
+
+// persistMachine writes a machine configuration to the store (caller holds pm.mu)
+func (pm *PlatformManager) persistMachine(config MachineConfig) error {
+	if pm.store == nil {
+		return nil
+	}
+	return pm.store.SaveMachine(config)
+}
+
 // AddMachine adds a new machine to the platform
 func (pm *PlatformManager) AddMachine(config MachineConfig) error {
 	log := utility.GetLogger()
 
+	config, err := applyMachineDefaults(config)
+	if err != nil {
+		return err
+	}
+
+	connection, err := connectMachine(config)
+	if err != nil {
+		return err
+	}
+
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	if err := pm.persistMachine(config); err != nil {
+		log.Error().Err(err).Str("machineID", config.ID).Msg("Failed to persist machine")
+		closeMachineConnection(connection)
+		return err
+	}
+
+	pm.machines[config.ID] = connection
+
+	return nil
+}
+
+// applyMachineDefaults fills in default values and validates the machine Type
+func applyMachineDefaults(config MachineConfig) (MachineConfig, error) {
+	log := utility.GetLogger()
+
 	// Set default values
 	if config.HTTPClientTimeout == 0 {
 		config.HTTPClientTimeout = 30
@@ -135,9 +223,16 @@ func (pm *PlatformManager) AddMachine(config MachineConfig) error {
 	}
 	if !validType {
 		log.Error().Msgf("invalid Type '%s', must be one of: %v", config.Type, config.TypeAllowableValues)
-		return fmt.Errorf("machine configuration validation failed: invalid Type '%s' for endpoint '%s', must be one of: %v (check your config file)", config.Type, config.Endpoint, config.TypeAllowableValues)
+		return config, fmt.Errorf("machine configuration validation failed: invalid Type '%s' for endpoint '%s', must be one of: %v (check your config file)", config.Type, config.Endpoint, config.TypeAllowableValues)
 	}
 
+	return config, nil
+}
+
+// connectMachine opens a Redfish session to the machine's BMC
+func connectMachine(config MachineConfig) (*MachineConnection, error) {
+	log := utility.GetLogger()
+
 	// Create custom HTTP client with timeout
 	transport := &http.Transport{
 		TLSHandshakeTimeout: 10 * time.Second,
@@ -169,7 +264,7 @@ func (pm *PlatformManager) AddMachine(config MachineConfig) error {
 	client, err := gofish.Connect(clientConfig)
 	if err != nil {
 		log.Error().Msgf("failed to connect to %s: %v", config.Endpoint, err)
-		return fmt.Errorf("failed to establish Redfish connection to endpoint '%s' (user: %s, timeout: %ds, insecure: %v): %w", config.Endpoint, config.Username, config.HTTPClientTimeout, config.Insecure, err)
+		return nil, fmt.Errorf("failed to establish Redfish connection to endpoint '%s' (user: %s, timeout: %ds, insecure: %v): %w", config.Endpoint, config.Username, config.HTTPClientTimeout, config.Insecure, err)
 	}
 
 	// Create connection based on Type
@@ -193,9 +288,161 @@ func (pm *PlatformManager) AddMachine(config MachineConfig) error {
 			Str("type", "BaseService").
 			Msg("Added machine")
 	}
-	
-	pm.machines[config.ID] = connection
 
+	return connection, nil
+}
+
+// closeMachineConnection logs out and releases idle connections of a machine
+func closeMachineConnection(machine *MachineConnection) {
+	if !machine.Connected() {
+		return
+	}
+
+	// Logout to close the session
+	machine.Client.Logout()
+
+	// Close idle connections to prevent connection leak
+	if machine.Client.HTTPClient != nil && machine.Client.HTTPClient.Transport != nil {
+		if transport, ok := machine.Client.HTTPClient.Transport.(*http.Transport); ok {
+			transport.CloseIdleConnections()
+		}
+	}
+}
+
+// contextTransport sends requests with ctx in place of their own context,
+// keeping the deadline of the HTTP client timeout
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if deadline, ok := req.Context().Deadline(); ok {
+		ctx, cancel = context.WithDeadline(t.ctx, deadline)
+	} else {
+		ctx, cancel = context.WithCancel(t.ctx)
+	}
+
+	resp, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The body is read after RoundTrip returns, the context ends with it
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose ends the context of a request once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// RestoreMachines registers every persisted machine in a disconnected state and
+// returns their IDs. Call ReconnectMachines afterwards to open the BMC sessions.
+func (pm *PlatformManager) RestoreMachines() ([]string, error) {
+	log := utility.GetLogger()
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if pm.store == nil {
+		return nil, nil
+	}
+
+	configs, err := pm.store.LoadMachines()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load persisted machines")
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(configs))
+	for _, config := range configs {
+		config, err := applyMachineDefaults(config)
+		if err != nil {
+			log.Warn().Err(err).Str("machineID", config.ID).Msg("Skipping invalid persisted machine")
+			continue
+		}
+		pm.machines[config.ID] = &MachineConnection{
+			Config:          config,
+			ConnectionError: "connection not yet established after restore",
+		}
+		ids = append(ids, config.ID)
+	}
+
+	log.Info().Int("machines", len(ids)).Msg("Restored persisted machines")
+	return ids, nil
+}
+
+// ReconnectMachines reconnects the given machines in parallel. Machines whose BMC
+// is still unreachable stay registered in a disconnected state.
+func (pm *PlatformManager) ReconnectMachines(ids []string) {
+	sem := make(chan struct{}, restoreConcurrency)
+	var wg sync.WaitGroup
+
+	for _, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(machineID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			_ = pm.ReconnectMachine(machineID)
+		}(id)
+	}
+
+	wg.Wait()
+}
+
+// ReconnectMachine opens a new BMC session for a registered machine using its current configuration
+func (pm *PlatformManager) ReconnectMachine(id string) error {
+	log := utility.GetLogger()
+
+	pm.mu.RLock()
+	current, ok := pm.machines[id]
+	var config MachineConfig
+	if ok {
+		config = current.Config
+	}
+	pm.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("machine %s not found", id)
+	}
+
+	connection, err := connectMachine(config)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	// The machine was removed or replaced while we were connecting
+	if pm.machines[id] != current {
+		if connection != nil {
+			closeMachineConnection(connection)
+		}
+		return fmt.Errorf("machine %s changed during reconnection", id)
+	}
+
+	if err != nil {
+		current.ConnectionError = err.Error()
+		log.Warn().Err(err).Str("machineID", id).Msg("Machine is unreachable, keeping it registered as disconnected")
+		return err
+	}
+
+	closeMachineConnection(current)
+	pm.machines[id] = connection
 	return nil
 }
 
@@ -247,16 +494,15 @@ func (pm *PlatformManager) RemoveMachine(id string) error {
 		return fmt.Errorf("machine with ID '%s' not found in registry (available machines: %d). Verify the machine ID or check /machines endpoint", id, len(pm.machines))
 	}
 
-	// Logout to close the session
-	machine.Client.Logout()
-	
-	// Close idle connections to prevent connection leak
-	if machine.Client.HTTPClient != nil && machine.Client.HTTPClient.Transport != nil {
-		if transport, ok := machine.Client.HTTPClient.Transport.(*http.Transport); ok {
-			transport.CloseIdleConnections()
+	if pm.store != nil {
+		if err := pm.store.DeleteMachine(id); err != nil {
+			log.Error().Err(err).Str("machineID", id).Msg("Failed to delete persisted machine")
+			return err
 		}
 	}
-	
+
+	closeMachineConnection(machine)
+
 	delete(pm.machines, id)
 	log.Info().Str("machineID", id).Msg("Removed machine")
 	return nil
@@ -270,15 +516,7 @@ func (pm *PlatformManager) CleanupAll() {
 	defer pm.mu.Unlock()
 
 	for id, machine := range pm.machines {
-		// Logout to close the session
-		machine.Client.Logout()
-		
-		// Close idle connections to prevent connection leak
-		if machine.Client.HTTPClient != nil && machine.Client.HTTPClient.Transport != nil {
-			if transport, ok := machine.Client.HTTPClient.Transport.(*http.Transport); ok {
-				transport.CloseIdleConnections()
-			}
-		}
+		closeMachineConnection(machine)
 		log.Info().Str("machineID", id).Msg("Cleaned up machine")
 	}
 	
@@ -381,6 +619,7 @@ func getMachine(c *gin.Context) {
 		"Type":        machine.Config.Type,
 		"Type@Redfish.AllowableValues": machine.Config.TypeAllowableValues,
 		"Description": "BMC Machine Resource",
+		"Status":      machineStatus(machine),
 		"Connection": gin.H{
 			"Endpoint": machine.Config.Endpoint,
 			"Username": machine.Config.Username,
@@ -399,6 +638,21 @@ func getMachine(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// machineStatus reports the connection state of a machine in Redfish Status form
+func machineStatus(machine *MachineConnection) gin.H {
+	if machine.Connected() {
+		return gin.H{
+			"State":  "Enabled",
+			"Health": "OK",
+		}
+	}
+	return gin.H{
+		"State":           "UnavailableOffline",
+		"Health":          "Critical",
+		"ConnectionError": machine.ConnectionError,
+	}
+}
+
 // PATCH /MultiFish/v1/Platform/:machineId - Update machine configuration
 func updateMachine(c *gin.Context) {
 	machineID := c.Param("machineId")
@@ -425,38 +679,40 @@ func updateMachine(c *gin.Context) {
 		return
 	}
 
-	// Update configuration
+	// Update a copy of the configuration, the machine keeps its settings
+	// unless the copy is stored
 	PlatformMgr.mu.Lock()
+	config := machine.Config
 	needsReconnect := false
 	
-	if updates.Endpoint != nil && *updates.Endpoint != machine.Config.Endpoint {
-		machine.Config.Endpoint = *updates.Endpoint
+	if updates.Endpoint != nil && *updates.Endpoint != config.Endpoint {
+		config.Endpoint = *updates.Endpoint
 		needsReconnect = true
 	}
-	if updates.Username != nil && *updates.Username != machine.Config.Username {
-		machine.Config.Username = *updates.Username
+	if updates.Username != nil && *updates.Username != config.Username {
+		config.Username = *updates.Username
 		needsReconnect = true
 	}
 	if updates.Password != nil {
-		machine.Config.Password = *updates.Password
+		config.Password = *updates.Password
 		needsReconnect = true
 	}
 	if updates.HTTPClientTimeout != nil && *updates.HTTPClientTimeout > 0 {
-		machine.Config.HTTPClientTimeout = *updates.HTTPClientTimeout
+		config.HTTPClientTimeout = *updates.HTTPClientTimeout
 	}
 	// Update DisableEtagMatch setting (can be toggled without reconnection)
-	if updates.DisableEtagMatch != nil && *updates.DisableEtagMatch != machine.Config.DisableEtagMatch {
+	if updates.DisableEtagMatch != nil && *updates.DisableEtagMatch != config.DisableEtagMatch {
 		log := utility.GetLogger()
-		machine.Config.DisableEtagMatch = *updates.DisableEtagMatch
+		config.DisableEtagMatch = *updates.DisableEtagMatch
 		log.Info().
 			Str("machineID", machineID).
 			Bool("disableEtagMatch", *updates.DisableEtagMatch).
 			Msg("Updated DisableEtagMatch setting")
 	}
-	if updates.Type != nil && *updates.Type != machine.Config.Type {
+	if updates.Type != nil && *updates.Type != config.Type {
 		// Validate new Type
 		validType := false
-		for _, allowable := range machine.Config.TypeAllowableValues {
+		for _, allowable := range config.TypeAllowableValues {
 			if *updates.Type == allowable {
 				validType = true
 				break
@@ -465,18 +721,33 @@ func updateMachine(c *gin.Context) {
 		if !validType {
 			PlatformMgr.mu.Unlock()
 			utility.RedfishError(c, http.StatusBadRequest, 
-				fmt.Sprintf("Invalid Type '%s', must be one of: %v", *updates.Type, machine.Config.TypeAllowableValues),
+				fmt.Sprintf("Invalid Type '%s', must be one of: %v", *updates.Type, config.TypeAllowableValues),
 				"InvalidValue")
 			return
 		}
-		machine.Config.Type = *updates.Type
+		config.Type = *updates.Type
 		needsReconnect = true
 	}
+
+	// Write the new configuration through to the store
+	if err := PlatformMgr.persistMachine(config); err != nil {
+		PlatformMgr.mu.Unlock()
+		utility.RedfishError(c, http.StatusInternalServerError, err.Error(), "InternalError")
+		return
+	}
+	machine.Config = config
 	PlatformMgr.mu.Unlock()
 
 	// If critical settings changed, suggest reconnection
 	message := "Configuration updated successfully"
-	if needsReconnect {
+	if !machine.Connected() {
+		// Machines restored while their BMC was unreachable retry with the new settings
+		if err := PlatformMgr.ReconnectMachine(machineID); err != nil {
+			message = fmt.Sprintf("Configuration updated successfully. Machine is still disconnected: %v", err)
+		} else {
+			message = "Configuration updated successfully. Machine reconnected."
+		}
+	} else if needsReconnect {
 		message = "Configuration updated successfully. Reconnection may be required for some changes to take effect."
 	}
 
@@ -503,8 +774,35 @@ func deleteMachine(c *gin.Context) {
 
 // ========== Route Setup ==========
 
+// InitPlatformManager attaches the configured storage backend to the platform
+// manager and restores persisted machines. BMC sessions are re-established in
+// the background so an unreachable BMC does not delay startup.
+func InitPlatformManager(cfg *config.Config) {
+	log := utility.GetLogger()
+
+	store, err := NewMachineStore(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to initialize machine store, machines will not be persisted")
+		return
+	}
+	PlatformMgr.SetStore(store)
+
+	ids, err := PlatformMgr.RestoreMachines()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to restore persisted machines")
+		return
+	}
+
+	if len(ids) > 0 {
+		go PlatformMgr.ReconnectMachines(ids)
+	}
+}
+
 // PlatformRoutes sets up the platform-related routes
-func PlatformRoutes(router *gin.Engine) {
+func PlatformRoutes(router *gin.Engine, cfg *config.Config) {
+	// Initialize platform persistence with configuration
+	InitPlatformManager(cfg)
+
 	router.GET("/MultiFish/v1/Platform", getPlatform)
 	router.POST("/MultiFish/v1/Platform", addMachine)
 	router.GET("/MultiFish/v1/Platform/:machineId", getMachine)
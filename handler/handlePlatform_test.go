@@ -1,8 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestPlatformManager_AddMachine tests adding a machine to the platform
@@ -317,3 +326,147 @@ func TestServiceType_Constants(t *testing.T) {
 		t.Errorf("ServiceTypeExtend = %v, want Extend", ServiceTypeExtend)
 	}
 }
+
+// unreachableMachine returns a machine config whose BMC refuses connections
+func unreachableMachine(id string) MachineConfig {
+	return MachineConfig{
+		ID:                id,
+		Name:              "Unreachable " + id,
+		Type:              string(ServiceTypeExtend),
+		Endpoint:          "https://127.0.0.1:1",
+		Username:          "admin",
+		Password:          "password",
+		Insecure:          true,
+		HTTPClientTimeout: 1,
+	}
+}
+
+// TestPlatformManager_RestoreMachines tests that persisted machines are restored
+// in a disconnected state when their BMC is unreachable
+func TestPlatformManager_RestoreMachines(t *testing.T) {
+	store := newTestMachineStore(t)
+	require.NoError(t, store.SaveMachine(unreachableMachine("machine-1")))
+	require.NoError(t, store.SaveMachine(unreachableMachine("machine-2")))
+
+	pm := &PlatformManager{
+		machines: make(map[string]*MachineConnection),
+		store:    store,
+	}
+
+	ids, err := pm.RestoreMachines()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"machine-1", "machine-2"}, ids)
+
+	pm.ReconnectMachines(ids)
+
+	for _, id := range ids {
+		machine, err := pm.GetMachine(id)
+		require.NoError(t, err)
+		assert.False(t, machine.Connected(), "machine %s should be disconnected", id)
+		assert.NotEmpty(t, machine.ConnectionError)
+		assert.Equal(t, "Extend", machine.Config.Type)
+	}
+
+	// Service lookup reports the machine as unavailable instead of panicking
+	machine, _ := pm.GetMachine("machine-1")
+	_, respErr := GetService(machine)
+	require.NotNil(t, respErr)
+	assert.Equal(t, http.StatusServiceUnavailable, respErr.StatusCode)
+}
+
+// TestPlatformManager_RestoreMachines_NoStore tests restore without a storage backend
+func TestPlatformManager_RestoreMachines_NoStore(t *testing.T) {
+	pm := &PlatformManager{
+		machines: make(map[string]*MachineConnection),
+	}
+
+	ids, err := pm.RestoreMachines()
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+// TestPlatformManager_RemoveMachine_Persisted tests that removing a machine deletes it from the store
+func TestPlatformManager_RemoveMachine_Persisted(t *testing.T) {
+	store := newTestMachineStore(t)
+	require.NoError(t, store.SaveMachine(unreachableMachine("machine-1")))
+
+	pm := &PlatformManager{
+		machines: make(map[string]*MachineConnection),
+		store:    store,
+	}
+	_, err := pm.RestoreMachines()
+	require.NoError(t, err)
+
+	// Disconnected machines can be removed without an active session
+	require.NoError(t, pm.RemoveMachine("machine-1"))
+
+	loaded, err := store.LoadMachines()
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+}
+
+// TestGetMachine_Disconnected tests the machine resource of a disconnected machine
+func TestGetMachine_Disconnected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	PlatformMgr = &PlatformManager{
+		machines: make(map[string]*MachineConnection),
+	}
+	PlatformMgr.machines["machine-1"] = &MachineConnection{
+		Config:          unreachableMachine("machine-1"),
+		ConnectionError: "connection refused",
+	}
+
+	router := gin.New()
+	router.GET("/MultiFish/v1/Platform/:machineId", getMachine)
+
+	req, _ := http.NewRequest("GET", "/MultiFish/v1/Platform/machine-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	status, ok := response["Status"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "UnavailableOffline", status["State"])
+	assert.Equal(t, "connection refused", status["ConnectionError"])
+}
+
+// failingMachineStore is a MachineStore whose writes fail
+type failingMachineStore struct {
+	MachineStore
+}
+
+func (failingMachineStore) SaveMachine(config MachineConfig) error {
+	return errors.New("disk full")
+}
+
+// TestUpdateMachine_PersistFailureKeepsConfig tests that a configuration that
+// cannot be stored is not applied either
+func TestUpdateMachine_PersistFailureKeepsConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	PlatformMgr = &PlatformManager{
+		machines: make(map[string]*MachineConnection),
+		store:    failingMachineStore{},
+	}
+	PlatformMgr.machines["machine-1"] = &MachineConnection{
+		Config:          unreachableMachine("machine-1"),
+		ConnectionError: "connection refused",
+	}
+
+	router := gin.New()
+	router.PATCH("/MultiFish/v1/Platform/:machineId", updateMachine)
+
+	body := `{"Endpoint": "https://10.0.0.9", "Password": "changed", "DisableEtagMatch": true}`
+	req, _ := http.NewRequest("PATCH", "/MultiFish/v1/Platform/machine-1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, unreachableMachine("machine-1"), PlatformMgr.machines["machine-1"].Config)
+}
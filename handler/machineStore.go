@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"multifish/config"
+	"multifish/storage"
+	"multifish/utility"
+)
+
+// machinesCollection is the storage collection holding machine configurations
+const machinesCollection = "machines"
+
+// MachineStore persists machine configurations so the platform survives restarts
+type MachineStore interface {
+	SaveMachine(config MachineConfig) error
+	DeleteMachine(id string) error
+	LoadMachines() ([]MachineConfig, error)
+}
+
+// FileMachineStore stores each machine configuration as a JSON document in the data directory
+type FileMachineStore struct {
+	store *storage.FileStore
+}
+
+// NewFileMachineStore creates a machine store backed by the given file store
+func NewFileMachineStore(store *storage.FileStore) *FileMachineStore {
+	return &FileMachineStore{store: store}
+}
+
+// SaveMachine writes (or replaces) a machine configuration
+func (s *FileMachineStore) SaveMachine(config MachineConfig) error {
+	if err := s.store.Put(machinesCollection, config.ID, config); err != nil {
+		return fmt.Errorf("failed to persist machine '%s': %w", config.ID, err)
+	}
+	return nil
+}
+
+// DeleteMachine removes a machine configuration
+func (s *FileMachineStore) DeleteMachine(id string) error {
+	if err := s.store.Delete(machinesCollection, id); err != nil {
+		return fmt.Errorf("failed to delete persisted machine '%s': %w", id, err)
+	}
+	return nil
+}
+
+// LoadMachines returns all persisted machine configurations ordered by ID
+func (s *FileMachineStore) LoadMachines() ([]MachineConfig, error) {
+	log := utility.GetLogger()
+
+	records, err := s.store.List(machinesCollection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted machines: %w", err)
+	}
+
+	configs := make([]MachineConfig, 0, len(records))
+	for key, data := range records {
+		var config MachineConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			log.Warn().Err(err).Str("machineID", key).Msg("Skipping unreadable persisted machine")
+			continue
+		}
+		configs = append(configs, config)
+	}
+
+	sort.Slice(configs, func(i, j int) bool { return configs[i].ID < configs[j].ID })
+
+	return configs, nil
+}
+
+// NewMachineStore creates the machine store selected by the storage configuration.
+// The memory backend keeps nothing on disk and returns a nil store.
+func NewMachineStore(cfg *config.Config) (MachineStore, error) {
+	switch strings.ToLower(cfg.StorageBackend) {
+	case storage.BackendFile:
+		fileStore, err := storage.NewFileStore(cfg.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		return NewFileMachineStore(fileStore), nil
+	case storage.BackendMemory, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage backend '%s'. Valid backends: %v", cfg.StorageBackend, storage.ValidBackends)
+	}
+}
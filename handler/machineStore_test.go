@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"multifish/config"
+	"multifish/storage"
+)
+
+func newTestMachineStore(t *testing.T) *FileMachineStore {
+	fileStore, err := storage.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	return NewFileMachineStore(fileStore)
+}
+
+func TestFileMachineStore_SaveLoadDelete(t *testing.T) {
+	store := newTestMachineStore(t)
+
+	machines := []MachineConfig{
+		{ID: "machine-2", Name: "Machine 2", Type: "Base", Endpoint: "https://10.0.0.2", Username: "admin", Password: "secret2"},
+		{ID: "machine-1", Name: "Machine 1", Type: "Extend", Endpoint: "https://10.0.0.1", Username: "admin", Password: "secret1", HTTPClientTimeout: 10},
+	}
+	for _, m := range machines {
+		require.NoError(t, store.SaveMachine(m))
+	}
+
+	loaded, err := store.LoadMachines()
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+
+	// Machines are returned ordered by ID with all fields intact
+	assert.Equal(t, machines[1], loaded[0])
+	assert.Equal(t, machines[0], loaded[1])
+
+	require.NoError(t, store.DeleteMachine("machine-1"))
+	loaded, err = store.LoadMachines()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "machine-2", loaded[0].ID)
+}
+
+func TestNewMachineStore(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	// Memory backend does not persist anything
+	cfg.StorageBackend = storage.BackendMemory
+	store, err := NewMachineStore(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	// File backend stores machines in the data directory
+	cfg.StorageBackend = storage.BackendFile
+	cfg.DataDir = t.TempDir()
+	store, err = NewMachineStore(cfg)
+	require.NoError(t, err)
+	assert.IsType(t, &FileMachineStore{}, store)
+
+	// Unknown backends are rejected
+	cfg.StorageBackend = "unknown"
+	_, err = NewMachineStore(cfg)
+	assert.Error(t, err)
+}
@@ -46,6 +46,8 @@ func main() {
 		Str("logLevel", cfg.LogLevel).
 		Int("workerPoolSize", cfg.WorkerPoolSize).
 		Str("logsDir", cfg.LogsDir).
+		Str("storageBackend", cfg.StorageBackend).
+		Str("dataDir", cfg.DataDir).
 		Bool("rateLimitEnabled", cfg.RateLimitEnabled).
 		Float64("rateLimitRate", cfg.RateLimitRate).
 		Int("rateLimitBurst", cfg.RateLimitBurst).
@@ -104,8 +106,8 @@ func main() {
 		})
 	})
 
-	// Platform routes
-	handler.PlatformRoutes(router)
+	// Platform routes (restores persisted machines when a storage backend is configured)
+	handler.PlatformRoutes(router, cfg)
 
 	// Job service routes (now uses config for worker pool size)
 	handler.JobServiceRoutes(router, cfg)
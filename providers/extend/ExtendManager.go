@@ -474,7 +474,7 @@ func patchExtendProfile(em *ExtendManager, patch PatchProfileType) *utility.Resp
 		log.Error().Msgf("Failed to patch profile: %v", err)
 		return &utility.ResponseError{
 			StatusCode: http.StatusInternalServerError,
-			Error:      fmt.Errorf("failed to patch profile: %v", err),
+			Error:      fmt.Errorf("failed to patch profile: %w", err),
 			Message:    "InternalError",
 		}
 	}
@@ -535,7 +535,7 @@ func patchExtendFanController(em *ExtendManager, fanID string, fcPatch *PatchFan
 		log.Error().Msgf("Failed to patch FanController: %v", err)
 		return &utility.ResponseError{
 			StatusCode: http.StatusInternalServerError,
-			Error:      fmt.Errorf("failed to patch FanController: %v", err),
+			Error:      fmt.Errorf("failed to patch FanController: %w", err),
 			Message:    "InternalError",
 		}
 	}
@@ -596,7 +596,7 @@ func patchExtendFanZone(em *ExtendManager, zoneID string, fzPatch *PatchFanZoneT
 		log.Error().Msgf("Failed to patch FanZone: %v", err)
 		return &utility.ResponseError{
 			StatusCode: http.StatusInternalServerError,
-			Error:      fmt.Errorf("failed to patch FanZone: %v", err),
+			Error:      fmt.Errorf("failed to patch FanZone: %w", err),
 			Message:    "InternalError",
 		}
 	}
@@ -657,7 +657,7 @@ func patchExtendPidController(em *ExtendManager, pidID string, pcPatch *PatchPid
 		log.Error().Msgf("Failed to patch PidController: %v", err)
 		return &utility.ResponseError{
 			StatusCode: http.StatusInternalServerError,
-			Error:      fmt.Errorf("failed to patch PidController: %v", err),
+			Error:      fmt.Errorf("failed to patch PidController: %w", err),
 			Message:    "InternalError",
 		}
 	}
@@ -95,7 +95,7 @@ func PatchManagerData(manager *redfish.Manager, patch *PatchManagerType) *utilit
 		log.Error().Msgf("failed to patch ServiceIdentification: %v", err)
 		return &utility.ResponseError{
 			StatusCode: http.StatusInternalServerError,
-			Error:      fmt.Errorf("failed to patch ServiceIdentification: %v", err),
+			Error:      fmt.Errorf("failed to patch ServiceIdentification: %w", err),
 			Message:    "InternalError",
 		}
 	}
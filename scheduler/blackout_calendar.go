@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// BlackoutPolicy decides what happens to an execution that falls into a blackout window
+type BlackoutPolicy string
+
+const (
+	BlackoutPolicySkip  BlackoutPolicy = "Skip"  // Drop the execution and wait for the next scheduled run (default)
+	BlackoutPolicyDefer BlackoutPolicy = "Defer" // Run the execution as soon as the blackout ends
+)
+
+// maxBlackoutChain bounds how many back-to-back windows are followed when deferring
+const maxBlackoutChain = 100
+
+// BlackoutCalendar is a named set of blackout windows during which jobs must not run.
+// Global calendars apply to every job, others only to jobs that reference them.
+type BlackoutCalendar struct {
+	ID          string           `json:"Id"`
+	Name        string           `json:"Name,omitempty"`
+	Global      bool             `json:"Global"`
+	TimeZone    string           `json:"TimeZone,omitempty"` // IANA zone for recurring windows (default: server local time)
+	Windows     []BlackoutWindow `json:"Windows"`
+	CreatedTime time.Time        `json:"CreatedTime"`
+}
+
+// BlackoutWindow is either a one-off range (Start/End) or a recurring rule
+type BlackoutWindow struct {
+	Start      *time.Time          `json:"Start,omitempty"` // RFC 3339, one-off window start (inclusive)
+	End        *time.Time          `json:"End,omitempty"`   // RFC 3339, one-off window end (exclusive)
+	Recurrence *BlackoutRecurrence `json:"Recurrence,omitempty"`
+}
+
+// BlackoutRecurrence is a recurring blackout on matching days between two times of day
+type BlackoutRecurrence struct {
+	DaysOfWeek  []DayOfWeek `json:"DaysOfWeek,omitempty"`  // Empty matches every weekday
+	DaysOfMonth *string     `json:"DaysOfMonth,omitempty"` // Calendar expression, e.g. "L" or "Last Friday"
+	DayMatch    DayMatch    `json:"DayMatch,omitempty"`    // "All" (default) or "Any"
+	StartTime   string      `json:"StartTime,omitempty"`   // HH:MM:SS, default 00:00:00
+	EndTime     string      `json:"EndTime,omitempty"`     // HH:MM:SS (exclusive), default end of day
+}
+
+// Validate validates the calendar and returns all problems found
+func (c *BlackoutCalendar) Validate() []string {
+	var errors []string
+
+	if _, err := c.location(); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	if len(c.Windows) == 0 {
+		errors = append(errors, "at least one blackout window must be specified in Windows")
+	}
+
+	for i, window := range c.Windows {
+		for _, err := range window.validate() {
+			errors = append(errors, fmt.Sprintf("Windows[%d]: %s", i, err))
+		}
+	}
+
+	return errors
+}
+
+// validate validates a single blackout window
+func (w BlackoutWindow) validate() []string {
+	var errors []string
+
+	oneOff := w.Start != nil || w.End != nil
+	switch {
+	case oneOff && w.Recurrence != nil:
+		errors = append(errors, "a window is either one-off (Start/End) or recurring (Recurrence), not both")
+	case oneOff:
+		if w.Start == nil || w.End == nil {
+			errors = append(errors, "one-off windows require both Start and End (RFC 3339)")
+		} else if !w.Start.Before(*w.End) {
+			errors = append(errors, "Start must be before End")
+		}
+	case w.Recurrence != nil:
+		errors = append(errors, w.Recurrence.validate()...)
+	default:
+		errors = append(errors, "either Start/End or Recurrence is required")
+	}
+
+	return errors
+}
+
+// validate validates a recurring blackout rule
+func (r *BlackoutRecurrence) validate() []string {
+	var errors []string
+
+	validDays := map[DayOfWeek]bool{
+		Monday: true, Tuesday: true, Wednesday: true, Thursday: true,
+		Friday: true, Saturday: true, Sunday: true,
+	}
+	for _, day := range r.DaysOfWeek {
+		if !validDays[day] {
+			errors = append(errors, fmt.Sprintf("invalid day of week: %s", day))
+		}
+	}
+
+	if r.DaysOfMonth != nil && *r.DaysOfMonth != "" {
+		if _, err := ParseDaysOfMonth(*r.DaysOfMonth); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	if r.DayMatch != "" && r.DayMatch != DayMatchAll && r.DayMatch != DayMatchAny {
+		errors = append(errors, fmt.Sprintf("invalid DayMatch: %s (must be 'All' or 'Any')", r.DayMatch))
+	}
+
+	start, startErr := r.clock(r.StartTime, "StartTime")
+	if startErr != nil {
+		errors = append(errors, startErr.Error())
+	}
+	end, endErr := r.clock(r.EndTime, "EndTime")
+	if endErr != nil {
+		errors = append(errors, endErr.Error())
+	}
+	if startErr == nil && endErr == nil && r.EndTime != "" && !start.Before(end) {
+		errors = append(errors, "StartTime must be before EndTime (windows crossing midnight need two recurrences)")
+	}
+
+	return errors
+}
+
+// clock parses an optional HH:MM:SS time of day
+func (r *BlackoutRecurrence) clock(value, field string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("15:04:05", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s format: %s (expected HH:MM:SS)", field, value)
+	}
+	return t, nil
+}
+
+// location returns the time zone recurring windows are evaluated in
+func (c *BlackoutCalendar) location() (*time.Location, error) {
+	return Schedule{TimeZone: c.TimeZone}.Location()
+}
+
+// BlackoutAt reports whether t falls into one of the calendar's windows and,
+// if so, when that window ends
+func (c *BlackoutCalendar) BlackoutAt(t time.Time) (time.Time, bool) {
+	loc, err := c.location()
+	if err != nil {
+		loc = time.Local
+	}
+
+	var until time.Time
+	for _, window := range c.Windows {
+		end, blocked := window.blackoutAt(t.In(loc))
+		if blocked && end.After(until) {
+			until = end
+		}
+	}
+
+	return until, !until.IsZero()
+}
+
+// blackoutAt checks a single window, t is in the calendar's time zone
+func (w BlackoutWindow) blackoutAt(t time.Time) (time.Time, bool) {
+	if w.Recurrence == nil {
+		if w.Start == nil || w.End == nil {
+			return time.Time{}, false
+		}
+		if !t.Before(*w.Start) && t.Before(*w.End) {
+			return *w.End, true
+		}
+		return time.Time{}, false
+	}
+
+	r := w.Recurrence
+	daysOfMonth, err := parsePeriodDaysOfMonth(r.DaysOfMonth)
+	if err != nil || !periodDaysMatch(t, r.DaysOfWeek, daysOfMonth, r.DayMatch) {
+		return time.Time{}, false
+	}
+
+	startClock, _ := r.clock(r.StartTime, "StartTime")
+	start := wallClockTime(t.Year(), t.Month(), t.Day(), startClock, t.Location())
+
+	end := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	if r.EndTime != "" {
+		endClock, _ := r.clock(r.EndTime, "EndTime")
+		end = wallClockTime(t.Year(), t.Month(), t.Day(), endClock, t.Location())
+	}
+
+	if !t.Before(start) && t.Before(end) {
+		return end, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,276 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newActiveBlackoutCalendar returns a calendar with a one-off window around now
+func newActiveBlackoutCalendar(id string, global bool) *BlackoutCalendar {
+	start := time.Now().Add(-time.Hour)
+	end := time.Now().Add(time.Hour)
+	return &BlackoutCalendar{
+		ID:      id,
+		Global:  global,
+		Windows: []BlackoutWindow{{Start: &start, End: &end}},
+	}
+}
+
+// makeJobDue moves a job's next run into the past so the next check picks it up
+func makeJobDue(service *JobService, job *Job) {
+	service.mu.Lock()
+	due := time.Now().Add(-time.Second)
+	job.NextRunTime = &due
+	service.schedule.set(job)
+	service.mu.Unlock()
+}
+
+func TestBlackoutCalendar_Validate(t *testing.T) {
+	start := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		calendar      BlackoutCalendar
+		expectedValid bool
+	}{
+		{"One-off window", BlackoutCalendar{Windows: []BlackoutWindow{{Start: &start, End: &end}}}, true},
+		{"Recurring window", BlackoutCalendar{Windows: []BlackoutWindow{{Recurrence: &BlackoutRecurrence{
+			DaysOfWeek: []DayOfWeek{Saturday, Sunday}, StartTime: "22:00:00"}}}}, true},
+		{"Last Friday of the month", BlackoutCalendar{TimeZone: "Asia/Taipei", Windows: []BlackoutWindow{{Recurrence: &BlackoutRecurrence{
+			DaysOfMonth: stringPtr("Last Friday")}}}}, true},
+		{"No windows", BlackoutCalendar{}, false},
+		{"Empty window", BlackoutCalendar{Windows: []BlackoutWindow{{}}}, false},
+		{"Missing End", BlackoutCalendar{Windows: []BlackoutWindow{{Start: &start}}}, false},
+		{"End before Start", BlackoutCalendar{Windows: []BlackoutWindow{{Start: &end, End: &start}}}, false},
+		{"Both kinds", BlackoutCalendar{Windows: []BlackoutWindow{{Start: &start, End: &end, Recurrence: &BlackoutRecurrence{}}}}, false},
+		{"Crossing midnight", BlackoutCalendar{Windows: []BlackoutWindow{{Recurrence: &BlackoutRecurrence{
+			StartTime: "22:00:00", EndTime: "02:00:00"}}}}, false},
+		{"Invalid time", BlackoutCalendar{Windows: []BlackoutWindow{{Recurrence: &BlackoutRecurrence{StartTime: "25:00"}}}}, false},
+		{"Invalid day", BlackoutCalendar{Windows: []BlackoutWindow{{Recurrence: &BlackoutRecurrence{DaysOfWeek: []DayOfWeek{"Funday"}}}}}, false},
+		{"Invalid time zone", BlackoutCalendar{TimeZone: "Mars/Olympus", Windows: []BlackoutWindow{{Start: &start, End: &end}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.calendar.Validate()
+			assert.Equal(t, tt.expectedValid, len(errs) == 0, "errors: %v", errs)
+		})
+	}
+}
+
+func TestBlackoutCalendar_BlackoutAt(t *testing.T) {
+	// Tuesday to Thursday
+	start := time.Date(2026, 12, 22, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+
+	calendar := &BlackoutCalendar{
+		TimeZone: "UTC",
+		Windows: []BlackoutWindow{
+			{Start: &start, End: &end},
+			{Recurrence: &BlackoutRecurrence{DaysOfWeek: []DayOfWeek{Saturday, Sunday}}},
+			{Recurrence: &BlackoutRecurrence{DaysOfMonth: stringPtr("L"), StartTime: "18:00:00", EndTime: "23:00:00"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		blocked bool
+		until   time.Time
+	}{
+		{"Inside one-off window", time.Date(2026, 12, 23, 8, 0, 0, 0, time.UTC), true, end},
+		{"One-off end is exclusive", end, false, time.Time{}},
+		{"Weekend", time.Date(2026, 2, 14, 8, 0, 0, 0, time.UTC), true, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{"Weekday", time.Date(2026, 2, 16, 8, 0, 0, 0, time.UTC), false, time.Time{}},
+		{"Last day evening", time.Date(2026, 3, 31, 19, 0, 0, 0, time.UTC), true, time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)},
+		{"Last day morning", time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC), false, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, blocked := calendar.BlackoutAt(tt.at)
+			assert.Equal(t, tt.blocked, blocked)
+			assert.True(t, tt.until.Equal(until), "expected %s, got %s", tt.until, until)
+		})
+	}
+}
+
+func TestJobService_ActiveBlackoutFollowsAdjacentWindows(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	// Saturday and Sunday windows chain into one blackout until Monday
+	_, err := service.CreateCalendar(&BlackoutCalendar{
+		ID:       "weekend",
+		Global:   true,
+		TimeZone: "UTC",
+		Windows:  []BlackoutWindow{{Recurrence: &BlackoutRecurrence{DaysOfWeek: []DayOfWeek{Saturday, Sunday}}}},
+	})
+	require.NoError(t, err)
+
+	service.mu.Lock()
+	calendarID, until, blocked := service.activeBlackout(&Job{ID: "job"}, time.Date(2026, 2, 14, 8, 0, 0, 0, time.UTC))
+	service.mu.Unlock()
+
+	assert.True(t, blocked)
+	assert.Equal(t, "weekend", calendarID)
+	assert.True(t, time.Date(2026, 2, 16, 0, 0, 0, 0, time.UTC).Equal(until), "got %s", until)
+}
+
+func TestJobService_BlackoutSkip(t *testing.T) {
+	executor := &MockJobExecutor{}
+	service := NewJobService(&MockJobValidator{}, executor)
+	defer service.Stop()
+
+	_, err := service.CreateCalendar(newActiveBlackoutCalendar("freeze", false))
+	require.NoError(t, err)
+
+	request := newTestJobRequest()
+	request.Calendars = []string{"freeze"}
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	makeJobDue(service, job)
+	service.checkAndExecuteJobs()
+
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, JobStatusSkipped, executions[0].Status)
+	assert.Equal(t, "1", executions[0].ID)
+	assert.Contains(t, executions[0].Message, "freeze")
+
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+	assert.Equal(t, JobStatusPending, job.Status)
+	assert.Equal(t, 0, job.ExecutionCount)
+	require.NotNil(t, job.NextRunTime)
+	assert.True(t, job.NextRunTime.After(time.Now()))
+}
+
+func TestJobService_BlackoutSkipOnceJob(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	_, err := service.CreateCalendar(newActiveBlackoutCalendar("global-freeze", true))
+	require.NoError(t, err)
+
+	// Global calendars apply without being referenced
+	request := newTestJobRequest()
+	request.Schedule = Schedule{Type: ScheduleTypeOnce, Time: "08:00:00"}
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	makeJobDue(service, job)
+	service.checkAndExecuteJobs()
+
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+	assert.Equal(t, JobStatusSkipped, job.Status)
+	assert.Nil(t, job.NextRunTime)
+}
+
+func TestJobService_BlackoutDefer(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	calendar := newActiveBlackoutCalendar("freeze", false)
+	_, err := service.CreateCalendar(calendar)
+	require.NoError(t, err)
+
+	request := newTestJobRequest()
+	request.Calendars = []string{"freeze"}
+	request.BlackoutPolicy = BlackoutPolicyDefer
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	makeJobDue(service, job)
+	service.checkAndExecuteJobs()
+
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, JobStatusDeferred, executions[0].Status)
+
+	service.mu.RLock()
+	require.NotNil(t, job.NextRunTime)
+	assert.True(t, calendar.Windows[0].End.Equal(*job.NextRunTime))
+	assert.Equal(t, JobStatusPending, job.Status)
+	service.mu.RUnlock()
+
+	// The deferred run takes the next execution ID
+	runJobOnce(service, job)
+	executions, err = service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+	assert.Equal(t, "2", executions[1].ID)
+	assert.Equal(t, JobStatusCompleted, executions[1].Status)
+}
+
+func TestJobService_CalendarReferences(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	// Unknown calendars fail validation
+	request := newTestJobRequest()
+	request.Calendars = []string{"missing"}
+	_, validationResp, err := service.CreateJob(request)
+	assert.Error(t, err)
+	assert.False(t, validationResp.ScheduleValid)
+	assert.NotEmpty(t, validationResp.ScheduleErrors)
+
+	request.BlackoutPolicy = "Postpone"
+	assert.False(t, request.Validate().Valid)
+
+	_, err = service.CreateCalendar(newActiveBlackoutCalendar("freeze", false))
+	require.NoError(t, err)
+
+	_, err = service.CreateCalendar(newActiveBlackoutCalendar("freeze", false))
+	assert.ErrorIs(t, err, ErrCalendarExists)
+
+	errs, err := service.CreateCalendar(&BlackoutCalendar{})
+	assert.Error(t, err)
+	assert.NotEmpty(t, errs)
+
+	request = newTestJobRequest()
+	request.Calendars = []string{"freeze"}
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	// Referenced calendars cannot be deleted
+	assert.ErrorIs(t, service.DeleteCalendar("freeze"), ErrCalendarInUse)
+
+	require.NoError(t, service.DeleteJob(job.ID))
+	require.NoError(t, service.DeleteCalendar("freeze"))
+	assert.Empty(t, service.ListCalendars())
+	assert.Error(t, service.DeleteCalendar("freeze"))
+}
+
+func TestJobService_PersistsCalendars(t *testing.T) {
+	store := newTestJobStore(t)
+
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	_, err := service.CreateCalendar(&BlackoutCalendar{
+		Name:    "Weekends",
+		Global:  true,
+		Windows: []BlackoutWindow{{Recurrence: &BlackoutRecurrence{DaysOfWeek: []DayOfWeek{Saturday, Sunday}}}},
+	})
+	require.NoError(t, err)
+	calendars := service.ListCalendars()
+	require.Len(t, calendars, 1)
+	service.Stop()
+
+	restarted := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	defer restarted.Stop()
+
+	calendar, err := restarted.GetCalendar(calendars[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Weekends", calendar.Name)
+	assert.True(t, calendar.Global)
+	require.Len(t, calendar.Windows, 1)
+	assert.Equal(t, []DayOfWeek{Saturday, Sunday}, calendar.Windows[0].Recurrence.DaysOfWeek)
+}
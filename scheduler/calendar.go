@@ -0,0 +1,224 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DayMatch controls how DaysOfWeek and DaysOfMonth combine when both are set
+type DayMatch string
+
+const (
+	DayMatchAll DayMatch = "All" // A day must match DaysOfWeek and DaysOfMonth (default)
+	DayMatchAny DayMatch = "Any" // A day must match DaysOfWeek or DaysOfMonth
+)
+
+// dayOfMonthTermKind identifies the form of a single DaysOfMonth list entry
+type dayOfMonthTermKind int
+
+const (
+	dayRangeTerm   dayOfMonthTermKind = iota // "15" or "1-7"
+	lastDayTerm                              // "L" or "L-2"
+	nthWeekdayTerm                           // "First Monday" ... "Last Friday"
+)
+
+// dayOfMonthTerm is one entry of a DaysOfMonth list
+type dayOfMonthTerm struct {
+	kind    dayOfMonthTermKind
+	from    int          // dayRangeTerm: first day
+	to      int          // dayRangeTerm: last day
+	offset  int          // lastDayTerm: days before the last day
+	weekday time.Weekday // nthWeekdayTerm: weekday
+	ordinal int          // nthWeekdayTerm: 1-4, or -1 for the last one
+}
+
+// weekdayOrdinals maps the ordinal words of "<Ordinal> <Weekday>" entries
+var weekdayOrdinals = map[string]int{
+	"first":  1,
+	"second": 2,
+	"third":  3,
+	"fourth": 4,
+	"last":   -1,
+}
+
+// DaysOfMonthExpression is a parsed Period.DaysOfMonth calendar expression.
+//
+// The expression is a comma-separated list of:
+//   - a day of the month: "15"
+//   - a range of days: "1-7"
+//   - the last day of the month: "L", or days before it: "L-2"
+//   - a weekday occurrence: "First Monday", "Second Tuesday", "Third Wednesday",
+//     "Fourth Thursday" or "Last Friday" (weekday names may be abbreviated, "Last Fri")
+//
+// A day number the month does not have (e.g. 31 in April) does not match;
+// use "L" for the last day of every month.
+type DaysOfMonthExpression struct {
+	terms []dayOfMonthTerm
+}
+
+// ParseDaysOfMonth parses a DaysOfMonth calendar expression
+func ParseDaysOfMonth(expr string) (*DaysOfMonthExpression, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("invalid DaysOfMonth '%s': expression is empty", expr)
+	}
+
+	parsed := &DaysOfMonthExpression{}
+	for _, entry := range strings.Split(expr, ",") {
+		term, err := parseDayOfMonthTerm(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("invalid DaysOfMonth '%s': %v. Use days (\"1,15\"), ranges (\"1-7\"), \"L\" for the last day or weekday occurrences such as \"Last Friday\"", expr, err)
+		}
+		parsed.terms = append(parsed.terms, term)
+	}
+
+	return parsed, nil
+}
+
+// parseDayOfMonthTerm parses a single DaysOfMonth list entry
+func parseDayOfMonthTerm(entry string) (dayOfMonthTerm, error) {
+	if entry == "" {
+		return dayOfMonthTerm{}, fmt.Errorf("empty list entry")
+	}
+
+	// "Last Friday", "First Monday"
+	if words := strings.Fields(entry); len(words) == 2 {
+		ordinal, ok := weekdayOrdinals[strings.ToLower(words[0])]
+		if !ok {
+			return dayOfMonthTerm{}, fmt.Errorf("'%s' has unknown ordinal '%s' (expected First, Second, Third, Fourth or Last)", entry, words[0])
+		}
+		weekday, ok := parseWeekdayName(words[1])
+		if !ok {
+			return dayOfMonthTerm{}, fmt.Errorf("'%s' has unknown weekday '%s'", entry, words[1])
+		}
+		return dayOfMonthTerm{kind: nthWeekdayTerm, weekday: weekday, ordinal: ordinal}, nil
+	}
+
+	// "L", "L-2"
+	if strings.EqualFold(entry[:1], "L") {
+		if len(entry) == 1 {
+			return dayOfMonthTerm{kind: lastDayTerm}, nil
+		}
+		if entry[1] != '-' {
+			return dayOfMonthTerm{}, fmt.Errorf("'%s' is not a valid last-day entry (expected \"L\" or \"L-n\")", entry)
+		}
+		offset, err := strconv.Atoi(entry[2:])
+		if err != nil || offset < 0 || offset > 30 {
+			return dayOfMonthTerm{}, fmt.Errorf("'%s' has invalid offset (expected \"L-n\" with n between 0 and 30)", entry)
+		}
+		return dayOfMonthTerm{kind: lastDayTerm, offset: offset}, nil
+	}
+
+	// "15", "1-7"
+	bounds := strings.SplitN(entry, "-", 2)
+	from, err := parseDayNumber(bounds[0])
+	if err != nil {
+		return dayOfMonthTerm{}, err
+	}
+	to := from
+	if len(bounds) == 2 {
+		if to, err = parseDayNumber(bounds[1]); err != nil {
+			return dayOfMonthTerm{}, err
+		}
+		if from > to {
+			return dayOfMonthTerm{}, fmt.Errorf("range '%s' is descending", entry)
+		}
+	}
+
+	return dayOfMonthTerm{kind: dayRangeTerm, from: from, to: to}, nil
+}
+
+// parseDayNumber parses a day of the month between 1 and 31
+func parseDayNumber(value string) (int, error) {
+	day, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a day number", value)
+	}
+	if day < 1 || day > 31 {
+		return 0, fmt.Errorf("day %d out of range 1-31", day)
+	}
+	return day, nil
+}
+
+// parseWeekdayName parses a full ("Friday") or abbreviated ("Fri") weekday name
+func parseWeekdayName(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := day.String()
+		if strings.EqualFold(name, full) || strings.EqualFold(name, full[:3]) {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// Matches reports whether the date's calendar day matches any entry of the expression
+func (e *DaysOfMonthExpression) Matches(date time.Time) bool {
+	day := date.Day()
+	lastDay := daysInMonth(date)
+
+	for _, term := range e.terms {
+		switch term.kind {
+		case dayRangeTerm:
+			if day >= term.from && day <= term.to {
+				return true
+			}
+		case lastDayTerm:
+			if day == lastDay-term.offset {
+				return true
+			}
+		case nthWeekdayTerm:
+			if date.Weekday() != term.weekday {
+				continue
+			}
+			if term.ordinal == -1 {
+				if day+7 > lastDay {
+					return true
+				}
+			} else if (day-1)/7+1 == term.ordinal {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// daysInMonth returns the number of days in the date's month
+func daysInMonth(date time.Time) int {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// parsePeriodDaysOfMonth parses an optional DaysOfMonth field, nil when unset
+func parsePeriodDaysOfMonth(daysOfMonth *string) (*DaysOfMonthExpression, error) {
+	if daysOfMonth == nil || *daysOfMonth == "" {
+		return nil, nil
+	}
+	return ParseDaysOfMonth(*daysOfMonth)
+}
+
+// periodDaysMatch reports whether the date's calendar day matches the given
+// DaysOfWeek and parsed DaysOfMonth. Unset fields match every day; with both
+// set the day must match both unless dayMatch is "Any".
+func periodDaysMatch(date time.Time, daysOfWeek []DayOfWeek, daysOfMonth *DaysOfMonthExpression, dayMatch DayMatch) bool {
+	hasDaysOfWeek := len(daysOfWeek) > 0
+	weekdayMatches := !hasDaysOfWeek
+	for _, day := range daysOfWeek {
+		if string(day) == date.Weekday().String() {
+			weekdayMatches = true
+			break
+		}
+	}
+
+	hasDaysOfMonth := daysOfMonth != nil
+	dayOfMonthMatches := !hasDaysOfMonth
+	if hasDaysOfMonth {
+		dayOfMonthMatches = daysOfMonth.Matches(date)
+	}
+
+	if hasDaysOfWeek && hasDaysOfMonth && dayMatch == DayMatchAny {
+		return weekdayMatches || dayOfMonthMatches
+	}
+
+	return weekdayMatches && dayOfMonthMatches
+}
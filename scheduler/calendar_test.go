@@ -0,0 +1,177 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDaysOfMonth(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		expectErr bool
+	}{
+		{"Single day", "1", false},
+		{"List", "1,15,30", false},
+		{"List with spaces", "1, 15, 30", false},
+		{"Range", "1-7", false},
+		{"Last day", "L", false},
+		{"Before last day", "L-2", false},
+		{"Last weekday", "Last Friday", false},
+		{"Abbreviated weekday", "last fri", false},
+		{"Ordinal weekday", "Second Tuesday", false},
+		{"Mixed", "1,L,Last Friday,10-12", false},
+		{"Empty", "", true},
+		{"Empty entry", "1,,15", true},
+		{"Day zero", "0", true},
+		{"Day out of range", "32", true},
+		{"Descending range", "15-1", true},
+		{"Not a number", "first", true},
+		{"Bad last offset", "L-x", true},
+		{"Last offset out of range", "L-31", true},
+		{"Last without weekday", "Last", true},
+		{"Unknown ordinal", "Fifth Monday", true},
+		{"Unknown weekday", "Last Funday", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDaysOfMonth(tt.expr)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDaysOfMonthExpression_Matches(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		date    time.Time
+		matches bool
+	}{
+		{"List first entry", "1,15,30", date(2026, 2, 1), true},
+		{"List middle entry", "1,15,30", date(2026, 2, 15), true},
+		{"List no match", "1,15,30", date(2026, 2, 16), false},
+		{"Day missing from month", "30", date(2026, 2, 28), false},
+		{"Range", "1-7", date(2026, 2, 7), true},
+		{"Outside range", "1-7", date(2026, 2, 8), false},
+		{"Last day of February", "L", date(2026, 2, 28), true},
+		{"Last day of leap February", "L", date(2028, 2, 29), true},
+		{"Not last day", "L", date(2026, 3, 30), false},
+		{"Two days before last", "L-2", date(2026, 4, 28), true},
+		{"Last Friday", "Last Friday", date(2026, 2, 27), true},
+		{"Not the last Friday", "Last Friday", date(2026, 2, 20), false},
+		{"Last Friday wrong weekday", "Last Friday", date(2026, 2, 28), false},
+		{"First Monday", "First Monday", date(2026, 2, 2), true},
+		{"Second Monday is not first", "First Monday", date(2026, 2, 9), false},
+		{"Third Wednesday", "Third Wed", date(2026, 2, 18), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseDaysOfMonth(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, expr.Matches(tt.date))
+		})
+	}
+}
+
+func TestJobService_IsValidExecutionDate_DayMatch(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	firstWeek := "1-7"
+	firstWeekDays, err := ParseDaysOfMonth(firstWeek)
+	require.NoError(t, err)
+	monday := time.Date(2026, 2, 2, 8, 0, 0, 0, time.UTC)     // first Monday
+	nextMonday := time.Date(2026, 2, 9, 8, 0, 0, 0, time.UTC) // second Monday
+	tuesday := time.Date(2026, 2, 3, 8, 0, 0, 0, time.UTC)    // first week, not Monday
+	wednesday := time.Date(2026, 2, 11, 8, 0, 0, 0, time.UTC) // neither
+
+	all := &Period{DaysOfWeek: []DayOfWeek{Monday}, DaysOfMonth: &firstWeek}
+	assert.True(t, service.isValidExecutionDate(monday, all, firstWeekDays))
+	assert.False(t, service.isValidExecutionDate(nextMonday, all, firstWeekDays))
+	assert.False(t, service.isValidExecutionDate(tuesday, all, firstWeekDays))
+
+	anyDay := &Period{DaysOfWeek: []DayOfWeek{Monday}, DaysOfMonth: &firstWeek, DayMatch: DayMatchAny}
+	assert.True(t, service.isValidExecutionDate(monday, anyDay, firstWeekDays))
+	assert.True(t, service.isValidExecutionDate(nextMonday, anyDay, firstWeekDays))
+	assert.True(t, service.isValidExecutionDate(tuesday, anyDay, firstWeekDays))
+	assert.False(t, service.isValidExecutionDate(wednesday, anyDay, firstWeekDays))
+}
+
+func TestJobCreateRequestValidate_DaysOfMonth(t *testing.T) {
+	tests := []struct {
+		name          string
+		daysOfMonth   string
+		dayMatch      DayMatch
+		expectedValid bool
+	}{
+		{"List", "1,15,30", "", true},
+		{"Last Friday", "Last Friday", DayMatchAll, true},
+		{"Any", "L", DayMatchAny, true},
+		{"Invalid day", "1,45", "", false},
+		{"Invalid day match", "1", "Sometimes", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newTestJobRequest()
+			daysOfMonth := tt.daysOfMonth
+			request.Schedule.Period.DaysOfMonth = &daysOfMonth
+			request.Schedule.Period.DayMatch = tt.dayMatch
+
+			result := request.Validate()
+			assert.Equal(t, tt.expectedValid, result.Valid, "errors: %v", result.ScheduleErrors)
+			if !tt.expectedValid {
+				assert.False(t, result.ScheduleValid)
+				assert.NotEmpty(t, result.ScheduleErrors)
+			}
+		})
+	}
+}
+
+func TestJobService_CalculateNextRunTime_DaysOfMonth(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	now := time.Date(2026, 2, 16, 12, 0, 0, 0, time.UTC)
+	pastEndDay := "2026-01-31"
+
+	tests := []struct {
+		name     string
+		period   *Period
+		expected time.Time
+	}{
+		{"Next day in list", &Period{DaysOfMonth: stringPtr("1,15,30")}, time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{"Last day", &Period{DaysOfMonth: stringPtr("L")}, time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC)},
+		{"Last Friday", &Period{DaysOfMonth: stringPtr("Last Friday")}, time.Date(2026, 2, 27, 8, 0, 0, 0, time.UTC)},
+		{"Friday the 13th", &Period{DaysOfWeek: []DayOfWeek{Friday}, DaysOfMonth: stringPtr("13")}, time.Date(2026, 3, 13, 8, 0, 0, 0, time.UTC)},
+		{"EndDay passed", &Period{EndDay: &pastEndDay, DaysOfMonth: stringPtr("1")}, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &Job{ID: "dom-job", Schedule: Schedule{
+				Type: ScheduleTypeContinuous, Time: "08:00:00", TimeZone: "UTC", Period: tt.period,
+			}}
+			nextRun := service.calculateNextRunTimeFrom(job, now)
+			assert.True(t, tt.expected.Equal(nextRun), "expected %s, got %s", tt.expected, nextRun)
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,25 @@
+package scheduler
+
+import "time"
+
+// Clock tells the job service the current time. The scheduler computes run
+// times, due jobs and queue waits from it, so tests and schedule previews can
+// replace the wall clock with a fixed one.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock used by default
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+// Now returns the current wall-clock time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Now returns the current time of the job service clock
+func (js *JobService) Now() time.Time {
+	return js.clock.Now()
+}
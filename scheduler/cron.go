@@ -0,0 +1,245 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears bounds how far ahead Next searches before giving up
+// (e.g. "0 0 30 2 *" never matches)
+const cronSearchYears = 5
+
+// cronField describes the allowed range and aliases of one cron field
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronSecondField = cronField{name: "second", min: 0, max: 59}
+	cronMinuteField = cronField{name: "minute", min: 0, max: 59}
+	cronHourField   = cronField{name: "hour", min: 0, max: 23}
+	cronDomField    = cronField{name: "day-of-month", min: 1, max: 31}
+	cronMonthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// Day-of-week accepts 0-7 where both 0 and 7 are Sunday
+	cronDowField = cronField{name: "day-of-week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// CronExpression is a parsed cron schedule.
+//
+// Both the standard 5-field form (minute hour day-of-month month day-of-week)
+// and the 6-field form with a leading seconds field are accepted. Each field
+// supports '*', single values, ranges ("9-17"), steps ("*/15", "0-30/10"),
+// lists ("1,15,30") and, for month and day-of-week, three-letter names.
+type CronExpression struct {
+	seconds uint64
+	minutes uint64
+	hours   uint64
+	dom     uint64
+	months  uint64
+	dow     uint64
+
+	// When both day fields are restricted a day matches if either matches
+	// (standard cron behaviour), otherwise both must match
+	domRestricted bool
+	dowRestricted bool
+}
+
+// ParseCron parses a 5-field or 6-field cron expression
+func ParseCron(expr string) (*CronExpression, error) {
+	fields := strings.Fields(expr)
+
+	switch len(fields) {
+	case 5:
+		// No seconds field, run at second 0
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron expression '%s': expected 5 fields (minute hour day-of-month month day-of-week) or 6 fields (second minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+
+	cron := &CronExpression{}
+	targets := []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&cron.seconds, cronSecondField},
+		{&cron.minutes, cronMinuteField},
+		{&cron.hours, cronHourField},
+		{&cron.dom, cronDomField},
+		{&cron.months, cronMonthField},
+		{&cron.dow, cronDowField},
+	}
+
+	for i, target := range targets {
+		bits, err := parseCronField(fields[i], target.field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression '%s': %w", expr, err)
+		}
+		*target.bits = bits
+	}
+
+	// Sunday may be written as 0 or 7
+	if cron.dow&(1<<7) != 0 {
+		cron.dow = (cron.dow | 1) &^ (1 << 7)
+	}
+
+	cron.domRestricted = !isCronWildcard(fields[3])
+	cron.dowRestricted = !isCronWildcard(fields[5])
+
+	return cron, nil
+}
+
+// isCronWildcard reports whether a field places no restriction
+func isCronWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// parseCronField parses one comma-separated cron field into a bit set
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(value, ",") {
+		if part == "" {
+			return 0, fmt.Errorf("%s field '%s' contains an empty list entry", field.name, value)
+		}
+
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rangePart = part[:idx]
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("%s field '%s' has invalid step '%s' (must be a positive number)", field.name, value, part[idx+1:])
+			}
+			step = s
+		}
+
+		var start, end int
+		switch {
+		case isCronWildcard(rangePart):
+			start, end = field.min, field.max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], field); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(bounds[1], field); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("%s field '%s' has descending range %d-%d", field.name, value, start, end)
+			}
+		default:
+			v, err := parseCronValue(rangePart, field)
+			if err != nil {
+				return 0, err
+			}
+			start, end = v, v
+			// "5/15" means every 15 starting at 5
+			if step > 1 {
+				end = field.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue parses a single number or name within a field's range
+func parseCronValue(value string, field cronField) (int, error) {
+	if v, ok := field.names[strings.ToUpper(value)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s field has invalid value '%s'", field.name, value)
+	}
+	if v < field.min || v > field.max {
+		return 0, fmt.Errorf("%s field value %d out of range %d-%d", field.name, v, field.min, field.max)
+	}
+
+	return v, nil
+}
+
+// Next returns the first time strictly after the given time that matches the
+// expression, in the location of the given time. A zero time is returned if
+// nothing matches within the search window.
+func (c *CronExpression) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + cronSearchYears
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for c.months&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !c.dayMatches(t) {
+		month := t.Month()
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Month() != month {
+			goto wrap
+		}
+	}
+
+	// Hours, minutes and seconds step by elapsed time so DST transitions
+	// neither loop nor skip wall-clock times that exist
+	for c.hours&(1<<uint(t.Hour())) == 0 {
+		day := t.Day()
+		t = t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second).Add(time.Hour)
+		if t.Day() != day {
+			goto wrap
+		}
+	}
+
+	for c.minutes&(1<<uint(t.Minute())) == 0 {
+		hour := t.Hour()
+		t = t.Add(-time.Duration(t.Second()) * time.Second).Add(time.Minute)
+		if t.Hour() != hour {
+			goto wrap
+		}
+	}
+
+	for c.seconds&(1<<uint(t.Second())) == 0 {
+		minute := t.Minute()
+		t = t.Add(time.Second)
+		if t.Minute() != minute {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+// dayMatches applies the day-of-month / day-of-week combination rule
+func (c *CronExpression) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		expectErr bool
+	}{
+		{"Every minute", "* * * * *", false},
+		{"Business hours every 15 minutes", "*/15 9-17 * * MON-FRI", false},
+		{"With seconds", "30 0 8 * * *", false},
+		{"Lists and ranges", "0 8,12,18 1-7,15 * *", false},
+		{"Step from value", "5/20 * * * *", false},
+		{"Range with step", "0-30/10 * * * *", false},
+		{"Month names", "0 0 1 JAN,jul *", false},
+		{"Sunday as 7", "0 0 * * 7", false},
+		{"Question mark", "0 0 ? * MON", false},
+		{"Empty", "", true},
+		{"Too few fields", "* * * *", true},
+		{"Too many fields", "* * * * * * *", true},
+		{"Minute out of range", "60 * * * *", true},
+		{"Hour out of range", "0 24 * * *", true},
+		{"Day of month zero", "0 0 0 * *", true},
+		{"Month out of range", "0 0 1 13 *", true},
+		{"Day of week out of range", "0 0 * * 8", true},
+		{"Descending range", "0 17-9 * * *", true},
+		{"Zero step", "*/0 * * * *", true},
+		{"Invalid step", "*/x * * * *", true},
+		{"Unknown name", "0 0 * * FUNDAY", true},
+		{"Empty list entry", "0 8,,12 * * *", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCronExpression_Next(t *testing.T) {
+	// Monday 2026-02-09 10:07:30 UTC
+	base := time.Date(2026, 2, 9, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"Next minute", "* * * * *", base, time.Date(2026, 2, 9, 10, 8, 0, 0, time.UTC)},
+		{"Every 15 minutes", "*/15 9-17 * * MON-FRI", base, time.Date(2026, 2, 9, 10, 15, 0, 0, time.UTC)},
+		{"After business hours rolls to next weekday", "*/15 9-17 * * MON-FRI",
+			time.Date(2026, 2, 13, 17, 50, 0, 0, time.UTC), time.Date(2026, 2, 16, 9, 0, 0, 0, time.UTC)},
+		{"Seconds field", "*/20 * * * * *", base, time.Date(2026, 2, 9, 10, 7, 40, 0, time.UTC)},
+		{"Strictly after", "30 7 10 * * *", base, time.Date(2026, 2, 10, 10, 7, 30, 0, time.UTC)},
+		{"Day of month list", "0 0 1,15 * *", base, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{"Skips short months", "0 0 31 * *", base, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"Month names", "0 6 1 JUL *", base, time.Date(2026, 7, 1, 6, 0, 0, 0, time.UTC)},
+		{"Sunday as 7", "0 0 * * 7", base, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches (the 20th or a Sunday)
+		{"Day of month or day of week", "0 0 20 * SUN", base, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{"Leap day", "0 0 29 2 *", base, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"Never matches", "0 0 30 2 *", base, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cron.Next(tt.after))
+		})
+	}
+}
+
+func TestCronExpression_Next_DST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	// 2026-03-08 02:30 does not exist (clocks spring forward from 02:00 to 03:00)
+	cron, err := ParseCron("30 2 * * *")
+	require.NoError(t, err)
+	next := cron.Next(time.Date(2026, 3, 8, 1, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2026, 3, 9, 2, 30, 0, 0, loc), next)
+
+	// Hourly runs continue through the fall-back transition
+	cron, err = ParseCron("0 * * * *")
+	require.NoError(t, err)
+	next = cron.Next(time.Date(2026, 11, 1, 0, 30, 0, 0, loc))
+	assert.Equal(t, 1, next.Hour())
+	assert.True(t, cron.Next(next).After(next))
+}
+
+func TestJobCreateRequestValidate_Cron(t *testing.T) {
+	tests := []struct {
+		name          string
+		schedule      Schedule
+		expectedValid bool
+	}{
+		{"Valid cron", Schedule{Type: ScheduleTypeCron, Cron: "*/15 9-17 * * MON-FRI"}, true},
+		{"Missing expression", Schedule{Type: ScheduleTypeCron}, false},
+		{"Invalid expression", Schedule{Type: ScheduleTypeCron, Cron: "*/15 25 * * *"}, false},
+		{"Leap day", Schedule{Type: ScheduleTypeCron, Cron: "0 0 29 2 *"}, true},
+		{"Date that never occurs", Schedule{Type: ScheduleTypeCron, Cron: "0 0 30 2 *"}, false},
+		{"Time not allowed", Schedule{Type: ScheduleTypeCron, Cron: "0 8 * * *", Time: "08:00:00"}, false},
+		{"Period not allowed", Schedule{Type: ScheduleTypeCron, Cron: "0 8 * * *", Period: &Period{DaysOfWeek: []DayOfWeek{Monday}}}, false},
+		{"Cron on once schedule", Schedule{Type: ScheduleTypeOnce, Time: "08:00:00", Cron: "0 8 * * *"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newTestJobRequest()
+			request.Schedule = tt.schedule
+
+			result := request.Validate()
+			assert.Equal(t, tt.expectedValid, result.Valid, "errors: %v", result.ScheduleErrors)
+			if !tt.expectedValid {
+				assert.NotEmpty(t, result.ScheduleErrors)
+			}
+		})
+	}
+}
+
+func TestJobService_CalculateNextRunTime_Cron(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job := &Job{
+		ID:       "cron-job",
+		Schedule: Schedule{Type: ScheduleTypeCron, Cron: "*/5 * * * *"},
+	}
+
+	before := time.Now()
+	nextRun := service.calculateNextRunTime(job)
+
+	assert.True(t, nextRun.After(before))
+	assert.LessOrEqual(t, nextRun.Sub(before), 5*time.Minute)
+	assert.Equal(t, 0, nextRun.Minute()%5)
+	assert.Equal(t, 0, nextRun.Second())
+
+	// An expression without upcoming runs exhausts the schedule
+	job.Schedule.Cron = "0 0 30 2 *"
+	assert.True(t, service.calculateNextRunTime(job).IsZero())
+}
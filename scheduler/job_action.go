@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
 
 	"multifish/utility"
@@ -63,15 +64,24 @@ func (pv *PlatformValidator) validateActionSupport(machine interface{}, action A
 }
 
 // MachineActionExecutor is an interface for executing actions on machines
-// The main package will implement this to provide machine-specific operations
+// The main package will implement this to provide machine-specific operations.
+// Implementations must return promptly once ctx is cancelled or times out.
 type MachineActionExecutor interface {
 	// Manager-related actions
-	GetManagerByService(machine interface{}, managerID string) (interface{}, error)
-	PatchManager(manager interface{}, patch interface{}) error
-	PatchProfile(manager interface{}, patch extendprovider.PatchProfileType) error
-	PatchFanController(manager interface{}, fanControllerID string, patch *extendprovider.PatchFanControllerType) error
-	PatchFanZone(manager interface{}, fanZoneID string, patch *extendprovider.PatchFanZoneType) error
-	PatchPidController(manager interface{}, pidControllerID string, patch *extendprovider.PatchPidControllerType) error
+	GetManagerByService(ctx context.Context, machine interface{}, managerID string) (interface{}, error)
+	PatchManager(ctx context.Context, manager interface{}, patch interface{}) error
+	PatchProfile(ctx context.Context, manager interface{}, patch extendprovider.PatchProfileType) error
+	PatchFanController(ctx context.Context, manager interface{}, fanControllerID string, patch *extendprovider.PatchFanControllerType) error
+	PatchFanZone(ctx context.Context, manager interface{}, fanZoneID string, patch *extendprovider.PatchFanZoneType) error
+	PatchPidController(ctx context.Context, manager interface{}, pidControllerID string, patch *extendprovider.PatchPidControllerType) error
+
+	// GetFanSettings returns the current Oem.OpenBmc.Fan settings of a manager,
+	// used to capture the values a RevertAfter job restores
+	GetFanSettings(ctx context.Context, manager interface{}) (*extendprovider.OpenBmcFan, error)
+
+	// GetManagerSettings returns the current values of the manager properties
+	// PatchManager changes, used to verify a PatchManager job
+	GetManagerSettings(ctx context.Context, manager interface{}) (*redfishprovider.PatchManagerType, error)
 
 	// Add other machine-specific action methods here
 }
@@ -91,30 +101,39 @@ func NewDefaultActionExecutor(machineExecutor MachineActionExecutor) *DefaultAct
 // ActionExecutor executes specific actions on machines
 type ActionExecutor interface {
 	// Manager-related actions
-	ExecutePatchManager(machine interface{}, managerPayloads Payload) error
-	ExecutePatchProfile(machine interface{}, managerPayloads Payload) error
-	ExecutePatchFanController(machine interface{}, fanControllerPayloads Payload) error
-	ExecutePatchFanZone(machine interface{}, fanZonePayloads Payload) error
-	ExecutePatchPidController(machine interface{}, pidControllerPayloads Payload) error
+	ExecutePatchManager(ctx context.Context, machine interface{}, managerPayloads Payload) error
+	ExecutePatchProfile(ctx context.Context, machine interface{}, managerPayloads Payload) error
+	ExecutePatchFanController(ctx context.Context, machine interface{}, fanControllerPayloads Payload) error
+	ExecutePatchFanZone(ctx context.Context, machine interface{}, fanZonePayloads Payload) error
+	ExecutePatchPidController(ctx context.Context, machine interface{}, pidControllerPayloads Payload) error
+
+	// CapturePrevious returns a payload of the same action that restores the
+	// values the action is about to change on the machine
+	CapturePrevious(ctx context.Context, machine interface{}, action ActionType, payload Payload) (Payload, error)
+
+	// VerifyApplied reads back the values an action applied to the machine and
+	// returns a *VerificationError when they differ from the payload
+	VerifyApplied(ctx context.Context, machine interface{}, action ActionType, payload Payload, tolerance float64) error
 
 	// Add other action executors here
 }
 
-// ExecuteAction executes the specified action on a machine with the given payload
-func ExecuteAction(actionExecutor ActionExecutor, action ActionType, machine interface{}, payload Payload) error {
+// ExecuteAction executes the specified action on a machine with the given payload.
+// The action stops at the next BMC request once ctx is done.
+func ExecuteAction(ctx context.Context, actionExecutor ActionExecutor, action ActionType, machine interface{}, payload Payload) error {
 	switch action {
 
 	// Manager-related actions
 	case ActionPatchManager:
-		return actionExecutor.ExecutePatchManager(machine, payload)
+		return actionExecutor.ExecutePatchManager(ctx, machine, payload)
 	case ActionPatchProfile:
-		return actionExecutor.ExecutePatchProfile(machine, payload)
+		return actionExecutor.ExecutePatchProfile(ctx, machine, payload)
 	case ActionPatchFanController:
-		return actionExecutor.ExecutePatchFanController(machine, payload)
+		return actionExecutor.ExecutePatchFanController(ctx, machine, payload)
 	case ActionPatchFanZone:
-		return actionExecutor.ExecutePatchFanZone(machine, payload)
+		return actionExecutor.ExecutePatchFanZone(ctx, machine, payload)
 	case ActionPatchPidController:
-		return actionExecutor.ExecutePatchPidController(machine, payload)
+		return actionExecutor.ExecutePatchPidController(ctx, machine, payload)
 
 	// Add other actions here
 
@@ -126,7 +145,7 @@ func ExecuteAction(actionExecutor ActionExecutor, action ActionType, machine int
 // ========== Manager Action Execution ==========
 
 // ExecutePatchManager executes the PatchManager action on a machine
-func (dae *DefaultActionExecutor) ExecutePatchManager(machine interface{}, managerPayloads Payload) error {
+func (dae *DefaultActionExecutor) ExecutePatchManager(ctx context.Context, machine interface{}, managerPayloads Payload) error {
 	log := utility.GetLogger()
 
 	// Type assert payload to []ExecutePatchManagerPayload
@@ -138,13 +157,18 @@ func (dae *DefaultActionExecutor) ExecutePatchManager(machine interface{}, manag
 
 	// Iterate over each manager payload
 	for _, mp := range payloads {
+		// Stop before the next BMC request once the execution is cancelled or timed out
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		log.Debug().
 			Str("serviceIdentification", mp.Payload.ServiceIdentification).
 			Str("managerID", mp.ManagerID).
 			Msg("Executing PatchManager")
 
 		// Get the manager by service using the injected executor
-		manager, err := dae.machineExecutor.GetManagerByService(machine, mp.ManagerID)
+		manager, err := dae.machineExecutor.GetManagerByService(ctx, machine, mp.ManagerID)
 		if err != nil {
 			log.Error().Msgf("failed to get manager by service: %v", err)
 			return fmt.Errorf("failed to retrieve manager service for manager '%s': %w. Verify machine connectivity and Redfish service availability", mp.ManagerID, err)
@@ -156,7 +180,7 @@ func (dae *DefaultActionExecutor) ExecutePatchManager(machine interface{}, manag
 		}
 
 		// Use the injected PatchManager function
-		err = dae.machineExecutor.PatchManager(manager, patch)
+		err = dae.machineExecutor.PatchManager(ctx, manager, patch)
 		if err != nil {
 			log.Error().Msgf("failed to patch manager %s: %v", mp.ManagerID, err)
 			return fmt.Errorf("failed to patch manager '%s': %w. Check manager ID and payload values", mp.ManagerID, err)
@@ -167,7 +191,7 @@ func (dae *DefaultActionExecutor) ExecutePatchManager(machine interface{}, manag
 }
 
 // ExecutePatchProfile executes the PatchProfile action on a machine
-func (dae *DefaultActionExecutor) ExecutePatchProfile(machine interface{}, managerPayloads Payload) error {
+func (dae *DefaultActionExecutor) ExecutePatchProfile(ctx context.Context, machine interface{}, managerPayloads Payload) error {
 	log := utility.GetLogger()
 
 	// Type assert payload to []ExecutePatchProfilePayload
@@ -179,13 +203,18 @@ func (dae *DefaultActionExecutor) ExecutePatchProfile(machine interface{}, manag
 
 	// Iterate over each manager payload
 	for _, mp := range payloads {
+		// Stop before the next BMC request once the execution is cancelled or timed out
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		log.Debug().
 			Str("profile", mp.Payload.Profile).
 			Str("managerID", mp.ManagerID).
 			Msg("Executing PatchProfile")
 
 		// Get the manager by service using the injected executor
-		manager, err := dae.machineExecutor.GetManagerByService(machine, mp.ManagerID)
+		manager, err := dae.machineExecutor.GetManagerByService(ctx, machine, mp.ManagerID)
 		if err != nil {
 			log.Error().Msgf("failed to get manager by service: %v", err)
 			return fmt.Errorf("failed to retrieve manager service for manager '%s': %w. Verify machine connectivity and Redfish service availability", mp.ManagerID, err)
@@ -197,7 +226,7 @@ func (dae *DefaultActionExecutor) ExecutePatchProfile(machine interface{}, manag
 		}
 
 		// Use the injected PatchProfile function
-		err = dae.machineExecutor.PatchProfile(manager, patch)
+		err = dae.machineExecutor.PatchProfile(ctx, manager, patch)
 		if err != nil {
 			log.Error().Msgf("failed to patch profile for manager %s: %v", mp.ManagerID, err)
 			return fmt.Errorf("failed to patch profile '%s' for manager '%s': %w. Verify profile value is valid", mp.Payload.Profile, mp.ManagerID, err)
@@ -208,7 +237,7 @@ func (dae *DefaultActionExecutor) ExecutePatchProfile(machine interface{}, manag
 }
 
 // ExecutePatchFanController executes the PatchFanController action on a machine
-func (dae *DefaultActionExecutor) ExecutePatchFanController(machine interface{}, fanControllerPayloads Payload) error {
+func (dae *DefaultActionExecutor) ExecutePatchFanController(ctx context.Context, machine interface{}, fanControllerPayloads Payload) error {
 	log := utility.GetLogger()
 
 	// Type assert payload to []ExecutePatchFanControllerPayload
@@ -220,20 +249,25 @@ func (dae *DefaultActionExecutor) ExecutePatchFanController(machine interface{},
 	
 	// Iterate over each fan controller payload
 	for _, fp := range payloads {
+		// Stop before the next BMC request once the execution is cancelled or timed out
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		log.Debug().
 			Str("managerID", fp.ManagerID).
 			Str("fanControllerID", fp.FanControllerID).
 			Msg("Executing PatchFanController")
 		
 		// Get the manager by service using the injected executor
-		manager, err := dae.machineExecutor.GetManagerByService(machine, fp.ManagerID)
+		manager, err := dae.machineExecutor.GetManagerByService(ctx, machine, fp.ManagerID)
 		if err != nil {
 			log.Error().Msgf("failed to get manager by service: %v", err)
 			return fmt.Errorf("failed to retrieve manager service for manager '%s': %w. Verify machine connectivity and Redfish service availability", fp.ManagerID, err)
 		}
 
 		// Use the injected PatchFanController function
-		err = dae.machineExecutor.PatchFanController(manager, fp.FanControllerID, &fp.Payload)
+		err = dae.machineExecutor.PatchFanController(ctx, manager, fp.FanControllerID, &fp.Payload)
 		if err != nil {
 			log.Error().Msgf("failed to patch fan controller %s for manager %s: %v", fp.FanControllerID, fp.ManagerID, err)
 			return fmt.Errorf("failed to patch fan controller '%s' for manager '%s': %w. Check controller ID and configuration", fp.FanControllerID, fp.ManagerID, err)
@@ -243,7 +277,7 @@ func (dae *DefaultActionExecutor) ExecutePatchFanController(machine interface{},
 	return nil
 }
 
-func (dae *DefaultActionExecutor) ExecutePatchFanZone(machine interface{}, fanZonePayloads Payload) error {
+func (dae *DefaultActionExecutor) ExecutePatchFanZone(ctx context.Context, machine interface{}, fanZonePayloads Payload) error {
 	log := utility.GetLogger()
 
 	// Type assert payload to []ExecutePatchFanZonePayload
@@ -255,20 +289,25 @@ func (dae *DefaultActionExecutor) ExecutePatchFanZone(machine interface{}, fanZo
 
 	// Iterate over each fan zone payload
 	for _, fz := range payloads {
+		// Stop before the next BMC request once the execution is cancelled or timed out
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		log.Debug().
 			Str("managerID", fz.ManagerID).
 			Str("fanZoneID", fz.FanZoneID).
 			Msg("Executing PatchFanZone")
 
 		// Get the manager by service using the injected executor
-		manager, err := dae.machineExecutor.GetManagerByService(machine, fz.ManagerID)
+		manager, err := dae.machineExecutor.GetManagerByService(ctx, machine, fz.ManagerID)
 		if err != nil {
 			log.Error().Msgf("failed to get manager by service: %v", err)
 			return fmt.Errorf("failed to retrieve manager service for manager '%s': %w. Verify machine connectivity and Redfish service availability", fz.ManagerID, err)
 		}
 
 		// Use the injected PatchFanZone function
-		err = dae.machineExecutor.PatchFanZone(manager, fz.FanZoneID, &fz.Payload)
+		err = dae.machineExecutor.PatchFanZone(ctx, manager, fz.FanZoneID, &fz.Payload)
 		if err != nil {
 			log.Error().Msgf("failed to patch fan zone %s for manager %s: %v", fz.FanZoneID, fz.ManagerID, err)
 			return fmt.Errorf("failed to patch fan zone '%s' for manager '%s': %w. Verify zone ID and settings", fz.FanZoneID, fz.ManagerID, err)
@@ -278,7 +317,7 @@ func (dae *DefaultActionExecutor) ExecutePatchFanZone(machine interface{}, fanZo
 	return nil
 }
 
-func (dae *DefaultActionExecutor) ExecutePatchPidController(machine interface{}, pidControllerPayloads Payload) error {
+func (dae *DefaultActionExecutor) ExecutePatchPidController(ctx context.Context, machine interface{}, pidControllerPayloads Payload) error {
 	log := utility.GetLogger()
 
 	// Type assert payload to []ExecutePatchPidControllerPayload
@@ -290,20 +329,25 @@ func (dae *DefaultActionExecutor) ExecutePatchPidController(machine interface{},
 
 	// Iterate over each PID controller payload
 	for _, pc := range payloads {
+		// Stop before the next BMC request once the execution is cancelled or timed out
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		log.Debug().
 			Str("managerID", pc.ManagerID).
 			Str("pidControllerID", pc.PidControllerID).
 			Msg("Executing PatchPidController")
 
 		// Get the manager by service using the injected executor
-		manager, err := dae.machineExecutor.GetManagerByService(machine, pc.ManagerID)
+		manager, err := dae.machineExecutor.GetManagerByService(ctx, machine, pc.ManagerID)
 		if err != nil {
 			log.Error().Msgf("failed to get manager by service: %v", err)
 			return fmt.Errorf("failed to retrieve manager service for manager '%s': %w. Verify machine connectivity and Redfish service availability", pc.ManagerID, err)
 		}
 
 		// Use the injected PatchPidController function
-		err = dae.machineExecutor.PatchPidController(manager, pc.PidControllerID, &pc.Payload)
+		err = dae.machineExecutor.PatchPidController(ctx, manager, pc.PidControllerID, &pc.Payload)
 		if err != nil {
 			log.Error().Msgf("failed to patch PID controller %s for manager %s: %v", pc.PidControllerID, pc.ManagerID, err)
 			return fmt.Errorf("failed to patch PID controller '%s' for manager '%s': %w. Check PID parameters", pc.PidControllerID, pc.ManagerID, err)
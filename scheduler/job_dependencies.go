@@ -0,0 +1,265 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"multifish/utility"
+)
+
+// DependencyCondition selects the machines of a parent execution that satisfy a dependency
+type DependencyCondition string
+
+const (
+	DependencyOnSuccess DependencyCondition = "OnSuccess" // Machines the parent succeeded on
+	DependencyOnFailure DependencyCondition = "OnFailure" // Machines the parent failed, timed out or was cancelled on
+	DependencyAlways    DependencyCondition = "Always"    // Machines the parent ran on, whatever the outcome
+)
+
+// ValidDependencyConditions lists the accepted dependency conditions
+var ValidDependencyConditions = []DependencyCondition{DependencyOnSuccess, DependencyOnFailure, DependencyAlways}
+
+// JobDependency makes a job run after an execution of another job. The
+// dependent job runs on the machines it shares with the parent that satisfied
+// the condition in the parent's latest execution.
+type JobDependency struct {
+	JobID     string              `json:"JobId"`
+	Condition DependencyCondition `json:"Condition,omitempty"` // "OnSuccess" (default), "OnFailure" or "Always"
+}
+
+// condition returns the dependency condition, OnSuccess when none is set
+func (d JobDependency) condition() DependencyCondition {
+	if d.Condition == "" {
+		return DependencyOnSuccess
+	}
+	return d.Condition
+}
+
+// satisfiedBy reports whether a machine result of the parent satisfies the dependency
+func (d JobDependency) satisfiedBy(result MachineExecutionResult) bool {
+	// Machines skipped by an aborted rollout never ran
+	if result.Status == JobStatusSkipped {
+		return false
+	}
+
+	switch d.condition() {
+	case DependencyOnSuccess:
+		return result.Success
+	case DependencyOnFailure:
+		return !result.Success
+	default:
+		return true
+	}
+}
+
+// validateDependencies validates the dependencies of a job. A dependent job is
+// started by its parents and has no schedule of its own. Whether the parents
+// exist is checked by the job service.
+func (j *JobCreateRequest) validateDependencies() []string {
+	var errors []string
+
+	if j.Schedule != (Schedule{}) {
+		errors = append(errors, "Schedule must be omitted when DependsOn is specified, the job runs when its parent jobs finish")
+	}
+
+	seen := make(map[string]bool)
+	for i, dependency := range j.DependsOn {
+		if dependency.JobID == "" {
+			errors = append(errors, fmt.Sprintf("DependsOn[%d]: JobId must not be empty", i))
+			continue
+		}
+		if seen[dependency.JobID] {
+			errors = append(errors, fmt.Sprintf("DependsOn[%d]: duplicate dependency on job '%s'", i, dependency.JobID))
+		}
+		seen[dependency.JobID] = true
+
+		switch dependency.Condition {
+		case "", DependencyOnSuccess, DependencyOnFailure, DependencyAlways:
+		default:
+			errors = append(errors, fmt.Sprintf("DependsOn[%d]: invalid Condition: %s (must be 'OnSuccess', 'OnFailure' or 'Always')", i, dependency.Condition))
+		}
+	}
+
+	errors = append(errors, j.validateBlackout()...)
+	errors = append(errors, j.validateQueueing()...)
+
+	return errors
+}
+
+// validateDependencyReferences checks that the parent jobs exist and that
+// depending on them does not close a cycle back to jobID, which is empty for
+// a new job (caller holds js.mu)
+func (js *JobService) validateDependencyReferences(jobID string, dependencies []JobDependency) []string {
+	var errors []string
+
+	for _, dependency := range dependencies {
+		if dependency.JobID == "" {
+			continue
+		}
+		if dependency.JobID == jobID {
+			errors = append(errors, fmt.Sprintf("dependency cycle: job '%s' cannot depend on itself", jobID))
+			continue
+		}
+		if _, exists := js.jobs[dependency.JobID]; !exists {
+			errors = append(errors, fmt.Sprintf("dependency job '%s' not found. Use GET /MultiFish/v1/JobService/Jobs to list available jobs", dependency.JobID))
+			continue
+		}
+		if jobID == "" {
+			// Nothing can depend on a job that does not exist yet
+			continue
+		}
+		if path := js.dependencyPath(dependency.JobID, jobID, map[string]bool{}); path != nil {
+			cycle := append([]string{jobID}, path...)
+			errors = append(errors, fmt.Sprintf("dependency cycle: %s. Remove one of these dependencies first", strings.Join(cycle, " -> ")))
+		}
+	}
+
+	return errors
+}
+
+// dependencyPath returns the chain of dependencies leading from one job to
+// another, nil when there is none (caller holds js.mu)
+func (js *JobService) dependencyPath(from, to string, visited map[string]bool) []string {
+	if from == to {
+		return []string{to}
+	}
+	if visited[from] {
+		return nil
+	}
+	visited[from] = true
+
+	job, exists := js.jobs[from]
+	if !exists {
+		return nil
+	}
+	for _, dependency := range job.DependsOn {
+		if path := js.dependencyPath(dependency.JobID, to, visited); path != nil {
+			return append([]string{from}, path...)
+		}
+	}
+	return nil
+}
+
+// dependentsOf returns the jobs that depend on a job, sorted by ID (caller holds js.mu)
+func (js *JobService) dependentsOf(jobID string) []*Job {
+	var dependents []*Job
+	for _, job := range js.jobs {
+		for _, dependency := range job.DependsOn {
+			if dependency.JobID == jobID {
+				dependents = append(dependents, job)
+				break
+			}
+		}
+	}
+	sort.Slice(dependents, func(i, j int) bool { return dependents[i].ID < dependents[j].ID })
+	return dependents
+}
+
+// latestRun returns the newest execution of a job that ran on machines, nil
+// if the job has not run yet. Skipped and deferred executions are ignored
+// (caller holds js.mu).
+func (js *JobService) latestRun(jobID string) *ExecutionHistory {
+	executions := js.executions[jobID]
+	for i := len(executions) - 1; i >= 0; i-- {
+		switch executions[i].Status {
+		case JobStatusSkipped, JobStatusDeferred:
+			continue
+		}
+		return executions[i]
+	}
+	return nil
+}
+
+// dependencyMachines returns the machines of a dependent job that satisfy all
+// of its dependencies, in the job's machine order. ready is false while a
+// parent has not run yet (caller holds js.mu).
+func (js *JobService) dependencyMachines(job *Job) (machines []string, ready bool) {
+	eligible := make(map[string]bool, len(job.Machines))
+	for _, machineID := range job.Machines {
+		eligible[machineID] = true
+	}
+
+	for _, dependency := range job.DependsOn {
+		execution := js.latestRun(dependency.JobID)
+		if execution == nil {
+			return nil, false
+		}
+
+		satisfied := make(map[string]bool)
+		for _, result := range execution.Results {
+			if dependency.satisfiedBy(result) {
+				satisfied[result.MachineID] = true
+			}
+		}
+		for machineID := range eligible {
+			if !satisfied[machineID] {
+				delete(eligible, machineID)
+			}
+		}
+	}
+
+	machines = []string{}
+	for _, machineID := range job.Machines {
+		if eligible[machineID] {
+			machines = append(machines, machineID)
+		}
+	}
+	return machines, true
+}
+
+// triggerDependents queues a run of each job that depends on parent, limited
+// to the machines that satisfy all of the job's dependencies. A dependent whose
+// other parents have not run yet keeps waiting; one that no machine qualifies
+// for records a skipped execution (caller holds js.mu).
+func (js *JobService) triggerDependents(parent *Job, now time.Time) {
+	log := utility.GetLogger()
+
+	for _, job := range js.dependentsOf(parent.ID) {
+		if job.Status == JobStatusCancelled || job.Status == JobStatusPaused {
+			continue
+		}
+
+		machines, ready := js.dependencyMachines(job)
+		if !ready {
+			log.Debug().
+				Str("jobID", job.ID).
+				Str("parentJobID", parent.ID).
+				Msg("Dependent job waits for its other parent jobs")
+			continue
+		}
+
+		skipped := ""
+		if len(machines) == 0 {
+			skipped = fmt.Sprintf("No machine satisfied the dependencies after execution of job '%s'", parent.ID)
+		} else if js.queue.contains(job.ID) || js.isRunning(job.ID) {
+			skipped = fmt.Sprintf("Not triggered by job '%s', a run of the job was already queued or executing", parent.ID)
+		}
+		if skipped != "" {
+			js.recordExecution(job, &ExecutionHistory{
+				ExecutionTime: now,
+				Status:        JobStatusSkipped,
+				Results:       []MachineExecutionResult{},
+				Message:       skipped,
+				TriggeredBy:   parent.ID,
+			})
+			js.persistJobOrWarn(job)
+			continue
+		}
+
+		js.queue.pushTriggered(job, parent.ID, machines, now)
+		log.Info().
+			Str("jobID", job.ID).
+			Str("parentJobID", parent.ID).
+			Strs("machines", machines).
+			Msg("Dependent job triggered")
+	}
+}
+
+// isRunning reports whether a job is executing
+func (js *JobService) isRunning(jobID string) bool {
+	js.runningMu.Lock()
+	defer js.runningMu.Unlock()
+	return js.runningJobs[jobID]
+}
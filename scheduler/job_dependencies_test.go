@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDependentJobRequest returns a job request that runs after the given parents
+func newDependentJobRequest(machines []string, dependencies ...JobDependency) *JobCreateRequest {
+	request := newTestJobRequest()
+	request.Name = "Dependent Job"
+	request.Machines = machines
+	request.Schedule = Schedule{}
+	request.DependsOn = dependencies
+	return request
+}
+
+// machineOutcomeExecutor fails the machines listed in failed and records the
+// machines each job ran on
+type machineOutcomeExecutor struct {
+	mu     sync.Mutex
+	failed map[string]bool
+	ran    map[string][]string
+}
+
+func (e *machineOutcomeExecutor) executeJob(job *Job) *ExecutionHistory {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ran[job.ID] = job.Machines
+
+	history := &ExecutionHistory{JobID: job.ID, ExecutionTime: time.Now()}
+	for _, machineID := range job.Machines {
+		success := !e.failed[machineID]
+		status := JobStatusCompleted
+		if !success {
+			status = JobStatusFailed
+		}
+		history.Results = append(history.Results, MachineExecutionResult{MachineID: machineID, Success: success, Status: status})
+	}
+	return history
+}
+
+func (e *machineOutcomeExecutor) machines(jobID string) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.ran[jobID]
+}
+
+func newMachineOutcomeService(t *testing.T, failed ...string) (*JobService, *machineOutcomeExecutor) {
+	outcomes := &machineOutcomeExecutor{failed: make(map[string]bool), ran: make(map[string][]string)}
+	for _, machineID := range failed {
+		outcomes.failed[machineID] = true
+	}
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{ExecuteJobFunc: outcomes.executeJob})
+	t.Cleanup(service.Stop)
+	return service, outcomes
+}
+
+func TestJobCreateRequest_ValidateDependencies(t *testing.T) {
+	request := newDependentJobRequest([]string{"machine1"}, JobDependency{JobID: "Job-1"}, JobDependency{JobID: "Job-2", Condition: DependencyAlways})
+	result := request.Validate()
+	assert.True(t, result.Valid, "errors: %v", result.ScheduleErrors)
+
+	// A dependent job has no schedule of its own
+	scheduled := newDependentJobRequest([]string{"machine1"}, JobDependency{JobID: "Job-1"})
+	scheduled.Schedule = Schedule{Type: ScheduleTypeOnce, Immediate: true}
+	result = scheduled.Validate()
+	assert.False(t, result.ScheduleValid)
+
+	invalid := newDependentJobRequest([]string{"machine1"},
+		JobDependency{JobID: "Job-1", Condition: "OnTimeout"},
+		JobDependency{JobID: "Job-1"},
+		JobDependency{})
+	result = invalid.Validate()
+	assert.False(t, result.Valid)
+	assert.Len(t, result.ScheduleErrors, 3)
+}
+
+func TestJobService_CreateDependentJob(t *testing.T) {
+	service, _ := newMachineOutcomeService(t)
+
+	parent, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	dependent, _, err := service.CreateJob(newDependentJobRequest([]string{"machine1"}, JobDependency{JobID: parent.ID}))
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusPending, dependent.Status)
+	assert.Nil(t, dependent.NextRunTime, "dependent jobs are not scheduled")
+
+	_, result, err := service.CreateJob(newDependentJobRequest([]string{"machine1"}, JobDependency{JobID: "Job-missing"}))
+	require.Error(t, err)
+	require.Len(t, result.ScheduleErrors, 1)
+	assert.Contains(t, result.ScheduleErrors[0], "'Job-missing' not found")
+
+	// A parent with dependents cannot be deleted
+	assert.ErrorIs(t, service.DeleteJob(parent.ID), ErrJobHasDependents)
+	require.NoError(t, service.DeleteJob(dependent.ID))
+	require.NoError(t, service.DeleteJob(parent.ID))
+}
+
+func TestJobService_RejectsDependencyCycles(t *testing.T) {
+	service, _ := newMachineOutcomeService(t)
+
+	first, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	second, _, err := service.CreateJob(newDependentJobRequest([]string{"machine1"}, JobDependency{JobID: first.ID}))
+	require.NoError(t, err)
+	third, _, err := service.CreateJob(newDependentJobRequest([]string{"machine1"}, JobDependency{JobID: second.ID}))
+	require.NoError(t, err)
+
+	// first -> third -> second -> first
+	patch := []byte(`{"Schedule": null, "DependsOn": [{"JobId": "` + third.ID + `"}]}`)
+	_, result, err := service.UpdateJob(first.ID, patch)
+	require.Error(t, err)
+	require.Len(t, result.ScheduleErrors, 1)
+	assert.Contains(t, result.ScheduleErrors[0], "dependency cycle: "+strings.Join([]string{first.ID, third.ID, second.ID, first.ID}, " -> "))
+	assert.Empty(t, first.DependsOn, "a rejected update leaves the job untouched")
+
+	_, result, err = service.UpdateJob(second.ID, []byte(`{"DependsOn": [{"JobId": "`+second.ID+`"}]}`))
+	require.Error(t, err)
+	assert.Contains(t, result.ScheduleErrors[0], "cannot depend on itself")
+}
+
+func TestJobService_TriggersDependentsOnMatchingMachines(t *testing.T) {
+	service, outcomes := newMachineOutcomeService(t, "machine2")
+
+	request := newTestJobRequest()
+	request.Machines = []string{"machine1", "machine2", "machine3"}
+	parent, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	machines := []string{"machine1", "machine2", "machine4"}
+	onSuccess, _, err := service.CreateJob(newDependentJobRequest(machines, JobDependency{JobID: parent.ID}))
+	require.NoError(t, err)
+	onFailure, _, err := service.CreateJob(newDependentJobRequest(machines, JobDependency{JobID: parent.ID, Condition: DependencyOnFailure}))
+	require.NoError(t, err)
+	always, _, err := service.CreateJob(newDependentJobRequest(machines, JobDependency{JobID: parent.ID, Condition: DependencyAlways}))
+	require.NoError(t, err)
+
+	runJobOnce(service, parent)
+
+	executions := waitForExecutions(t, service, onSuccess.ID, 1)
+	assert.Equal(t, parent.ID, executions[0].TriggeredBy)
+	assert.Equal(t, []string{"machine1"}, outcomes.machines(onSuccess.ID))
+
+	waitForExecutions(t, service, onFailure.ID, 1)
+	assert.Equal(t, []string{"machine2"}, outcomes.machines(onFailure.ID))
+
+	waitForExecutions(t, service, always.ID, 1)
+	assert.Equal(t, []string{"machine1", "machine2"}, outcomes.machines(always.ID))
+
+	require.Eventually(t, func() bool {
+		service.mu.RLock()
+		defer service.mu.RUnlock()
+		return onSuccess.Status == JobStatusPending && onSuccess.ExecutionCount == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	service.mu.RLock()
+	assert.Equal(t, machines, onSuccess.Machines, "a triggered run does not change the job's machines")
+	assert.Nil(t, onSuccess.NextRunTime)
+	service.mu.RUnlock()
+}
+
+func TestJobService_DependentWaitsForAllParents(t *testing.T) {
+	service, outcomes := newMachineOutcomeService(t, "machine1")
+
+	firstRequest := newTestJobRequest()
+	firstRequest.Machines = []string{"machine1", "machine2", "machine3"}
+	first, _, err := service.CreateJob(firstRequest)
+	require.NoError(t, err)
+
+	secondRequest := newTestJobRequest()
+	secondRequest.Machines = []string{"machine2"}
+	second, _, err := service.CreateJob(secondRequest)
+	require.NoError(t, err)
+
+	dependent, _, err := service.CreateJob(newDependentJobRequest([]string{"machine1", "machine2", "machine3"},
+		JobDependency{JobID: first.ID, Condition: DependencyAlways},
+		JobDependency{JobID: second.ID}))
+	require.NoError(t, err)
+
+	// The second parent has not run yet, the dependent keeps waiting
+	runJobOnce(service, first)
+	time.Sleep(50 * time.Millisecond)
+	executions, err := service.GetExecutions(dependent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, executions)
+
+	runJobOnce(service, second)
+	executions = waitForExecutions(t, service, dependent.ID, 1)
+	assert.Equal(t, second.ID, executions[0].TriggeredBy)
+	assert.Equal(t, []string{"machine2"}, outcomes.machines(dependent.ID))
+}
+
+func TestJobService_DependentSkippedWithoutMachines(t *testing.T) {
+	service, outcomes := newMachineOutcomeService(t)
+
+	parent, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	dependent, _, err := service.CreateJob(newDependentJobRequest([]string{"machine1"}, JobDependency{JobID: parent.ID, Condition: DependencyOnFailure}))
+	require.NoError(t, err)
+
+	runJobOnce(service, parent)
+
+	executions := waitForExecutions(t, service, dependent.ID, 1)
+	assert.Equal(t, JobStatusSkipped, executions[0].Status)
+	assert.Contains(t, executions[0].Message, "No machine satisfied the dependencies")
+	assert.Nil(t, outcomes.machines(dependent.ID))
+
+	// Paused dependents are not triggered
+	require.NoError(t, service.PauseJob(dependent.ID))
+	runJobOnce(service, parent)
+	time.Sleep(50 * time.Millisecond)
+	executions, err = service.GetExecutions(dependent.ID)
+	require.NoError(t, err)
+	assert.Len(t, executions, 1)
+
+	require.NoError(t, service.ResumeJob(dependent.ID))
+	assert.Equal(t, JobStatusPending, dependent.Status)
+	assert.Nil(t, dependent.NextRunTime)
+}
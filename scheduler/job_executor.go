@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -139,7 +140,8 @@ func writeSuccessToJSON(jobID string, machineID string, action ActionType, durat
 
 // PlatformValidator validates jobs against platform machines
 type PlatformValidator struct {
-	platformMgr JobPlatformManager
+	platformMgr     JobPlatformManager
+	machineExecutor MachineActionExecutor // Reads machine resources for deep validation, may be nil
 }
 
 // NewPlatformValidator creates a new platform validator
@@ -198,6 +200,7 @@ func (pv *PlatformValidator) ValidateMachines(machineIDs []string, action Action
 type PlatformExecutor struct {
 	platformMgr JobPlatformManager
 	actionExecutor ActionExecutor
+	locks *MachineLockManager // Serializes operations per machine, may be nil
 }
 
 // NewPlatformExecutor creates a new platform executor
@@ -208,8 +211,23 @@ func NewPlatformExecutor(platformMgr JobPlatformManager, actionExecutor ActionEx
 	}
 }
 
-// ExecuteJob executes a job on all specified machines
-func (pe *PlatformExecutor) ExecuteJob(job *Job) *ExecutionHistory {
+// NewPlatformExecutorWithLocks creates a platform executor that holds the lock
+// of each machine while the job runs on it
+func NewPlatformExecutorWithLocks(platformMgr JobPlatformManager, actionExecutor ActionExecutor, locks *MachineLockManager) *PlatformExecutor {
+	return &PlatformExecutor{
+		platformMgr:    platformMgr,
+		actionExecutor: actionExecutor,
+		locks:          locks,
+	}
+}
+
+// ExecuteJob executes a job on all specified machines. Without a Rollout all
+// machines run in parallel; with one they run batch by batch and the remaining
+// batches are skipped once the canary fails or a failure threshold is reached.
+// Cancelling ctx (or its deadline passing) aborts the outstanding machine operations.
+func (pe *PlatformExecutor) ExecuteJob(ctx context.Context, job *Job) *ExecutionHistory {
+	log := utility.GetLogger()
+
 	history := &ExecutionHistory{
 		JobID:         job.ID,
 		ExecutionTime: time.Now(),
@@ -217,23 +235,126 @@ func (pe *PlatformExecutor) ExecuteJob(job *Job) *ExecutionHistory {
 		Results:       make([]MachineExecutionResult, len(job.Machines)),
 	}
 
-	// Execute on all machines in parallel
-	var wg sync.WaitGroup
-	for i, machineID := range job.Machines {
-		wg.Add(1)
-		go func(idx int, mID string) {
-			defer wg.Done()
-			history.Results[idx] = pe.executeMachine(job.ID, mID, job.Action, job.Payload)
-		}(i, machineID)
-	}
+	batches := job.Rollout.batches(len(job.Machines))
+	failed := 0
+
+	for b, batch := range batches {
+		if wait := job.Rollout.pause(); b > 0 && wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+
+		// Batches that have not started when the execution is interrupted never run
+		if err := ctx.Err(); err != nil {
+			status, reason := JobStatusCancelled, "job cancelled"
+			if err == context.DeadlineExceeded {
+				status, reason = JobStatusTimedOut, "job timeout exceeded"
+			}
+			pe.skipBatches(history, job, batches, b, status, fmt.Sprintf("Not executed, %s before batch %d", reason, b+1))
+			break
+		}
+
+		// Execute the machines of the batch in parallel
+		var wg sync.WaitGroup
+		for _, idx := range batch {
+			wg.Add(1)
+			go func(idx int) {
+				defer wg.Done()
+				history.Results[idx] = pe.runMachine(ctx, job, job.Machines[idx])
+				if job.Rollout != nil {
+					history.Results[idx].Batch = b + 1
+				}
+			}(idx)
+		}
+		wg.Wait()
+
+		batchFailed := 0
+		for _, idx := range batch {
+			if !history.Results[idx].Success {
+				batchFailed++
+			}
+		}
+		failed += batchFailed
+
+		if b == len(batches)-1 {
+			break
+		}
 
-	wg.Wait()
+		var reason string
+		switch {
+		case b == 0 && job.Rollout.hasCanary() && batchFailed > 0:
+			reason = fmt.Sprintf("canary batch failed on %d of %d machines", batchFailed, len(batch))
+		case job.Rollout.thresholdReached(failed, len(job.Machines)):
+			reason = fmt.Sprintf("%d of %d machines failed, failure threshold reached", failed, len(job.Machines))
+		}
+		if reason != "" {
+			history.Message = fmt.Sprintf("Rollout aborted after batch %d of %d: %s", b+1, len(batches), reason)
+			pe.skipBatches(history, job, batches, b+1, JobStatusSkipped, fmt.Sprintf("Not executed, rollout aborted after batch %d", b+1))
+
+			log.Warn().
+				Str("jobID", job.ID).
+				Int("batch", b+1).
+				Int("batches", len(batches)).
+				Int("failed", failed).
+				Msg("Rollout aborted, remaining batches skipped")
+			break
+		}
+	}
 
 	return history
 }
 
-// executeMachine executes the action on a single machine
-func (pe *PlatformExecutor) executeMachine(jobID string, machineID string, action ActionType, payload Payload) MachineExecutionResult {
+// runMachine runs the job on one machine while holding the machine lock, so
+// no other job or PATCH request changes the machine between the steps, the
+// capture of previous values and the verification of this run
+func (pe *PlatformExecutor) runMachine(ctx context.Context, job *Job, machineID string) MachineExecutionResult {
+	startTime := time.Now()
+	release, err := pe.lockMachine(ctx, job, machineID)
+	if err != nil {
+		return lockFailedResult(ctx, job, machineID, startTime, err)
+	}
+	defer release()
+
+	if len(job.Steps) > 0 {
+		return pe.executeSteps(ctx, job, machineID)
+	}
+	result, revert := pe.executeAction(ctx, job, machineID, job.Action, job.Payload, string(job.Action))
+	if revert != nil {
+		result.Revert = []JobStep{*revert}
+	}
+	return result
+}
+
+// skipBatches records the machines of batches[from:] as not executed
+func (pe *PlatformExecutor) skipBatches(history *ExecutionHistory, job *Job, batches [][]int, from int, status JobStatus, message string) {
+	now := time.Now()
+	for b := from; b < len(batches); b++ {
+		for _, idx := range batches[b] {
+			result := MachineExecutionResult{
+				MachineID: job.Machines[idx],
+				Status:    status,
+				Message:   message,
+				StartTime: now,
+				EndTime:   now,
+				Duration:  "0s",
+			}
+			if job.Rollout != nil {
+				result.Batch = b + 1
+			}
+			history.Results[idx] = result
+		}
+	}
+}
+
+// executeMachine executes the action on a single machine. Failed attempts are
+// retried with backoff while the retry policy allows it; every attempt is
+// recorded in the result. A machine interrupted by ctx is reported as
+// Cancelled or TimedOut and is not retried.
+func (pe *PlatformExecutor) executeMachine(ctx context.Context, jobID string, machineID string, action ActionType, payload Payload, retry *RetryPolicy) MachineExecutionResult {
 	log := utility.GetLogger()
 
 	result := MachineExecutionResult{
@@ -241,48 +362,84 @@ func (pe *PlatformExecutor) executeMachine(jobID string, machineID string, actio
 		StartTime: time.Now(),
 	}
 
-	// Get machine
-	machine, err := pe.platformMgr.GetMachine(machineID)
-	if err != nil {
-		result.Success = false
-		result.Error = fmt.Sprintf("Failed to get machine: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime).String()
-		
-		log.Error().
-			Err(err).
+	var execErr error
+	for attempt := 1; ; attempt++ {
+		record := ExecutionAttempt{
+			Attempt:   attempt,
+			StartTime: time.Now(),
+		}
+
+		execErr = pe.attemptMachine(ctx, machineID, action, payload)
+
+		record.EndTime = time.Now()
+		record.Duration = record.EndTime.Sub(record.StartTime).String()
+
+		if execErr == nil {
+			record.Success = true
+			result.Attempts = append(result.Attempts, record)
+			break
+		}
+
+		record.Error = execErr.Error()
+		record.ErrorClass = ClassifyError(execErr)
+		record.Retryable = retry.retryable(record.ErrorClass)
+		result.Attempts = append(result.Attempts, record)
+
+		if ctx.Err() != nil || !record.Retryable || attempt >= retry.maxAttempts() {
+			break
+		}
+
+		wait := retry.backoff(attempt)
+		log.Warn().
+			Err(execErr).
 			Str("jobID", jobID).
 			Str("machineID", machineID).
 			Str("action", string(action)).
-			Msg("Failed to get machine")
-		
-		// Write error to JSON file
-		if logErr := writeErrorToJSON(jobID, machineID, action, err, payload); logErr != nil {
-			log.Warn().
-				Err(logErr).
-				Str("machineID", machineID).
-				Msg("Failed to write error log")
+			Str("errorClass", string(record.ErrorClass)).
+			Int("attempt", attempt).
+			Dur("backoff", wait).
+			Msg("Action attempt failed, retrying")
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			execErr = ctx.Err()
+		}
+		if ctx.Err() != nil {
+			break
 		}
-		
-		return result
 	}
 
-	// Execute action
-	execErr := ExecuteAction(pe.actionExecutor, action, machine, payload)
-
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime).String()
 
 	if execErr != nil {
 		result.Success = false
 		result.Error = execErr.Error()
+		result.Status = JobStatusFailed
 		result.Message = fmt.Sprintf("Failed to execute %s", action)
+		if len(result.Attempts) > 1 {
+			result.Message = fmt.Sprintf("Failed to execute %s after %d attempts", action, len(result.Attempts))
+		}
+
+		// The execution was interrupted rather than failing on its own
+		switch ctx.Err() {
+		case context.Canceled:
+			result.Status = JobStatusCancelled
+			result.Message = fmt.Sprintf("%s cancelled before it completed", action)
+		case context.DeadlineExceeded:
+			result.Status = JobStatusTimedOut
+			result.Message = fmt.Sprintf("%s aborted, job timeout exceeded", action)
+		}
 		
 		log.Error().
 			Err(execErr).
 			Str("jobID", jobID).
 			Str("machineID", machineID).
 			Str("action", string(action)).
+			Int("attempts", len(result.Attempts)).
 			Msg("Failed to execute action")
 		
 		// Write error to JSON file
@@ -294,7 +451,11 @@ func (pe *PlatformExecutor) executeMachine(jobID string, machineID string, actio
 		}
 	} else {
 		result.Success = true
+		result.Status = JobStatusCompleted
 		result.Message = fmt.Sprintf("Successfully executed %s", action)
+		if len(result.Attempts) > 1 {
+			result.Message = fmt.Sprintf("Successfully executed %s after %d attempts", action, len(result.Attempts))
+		}
 		
 		log.Info().
 			Str("jobID", jobID).
@@ -314,3 +475,17 @@ func (pe *PlatformExecutor) executeMachine(jobID string, machineID string, actio
 
 	return result
 }
+
+// attemptMachine looks up the machine and executes the action on it once
+func (pe *PlatformExecutor) attemptMachine(ctx context.Context, machineID string, action ActionType, payload Payload) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	machine, err := pe.platformMgr.GetMachine(machineID)
+	if err != nil {
+		return fmt.Errorf("failed to get machine: %w", err)
+	}
+
+	return ExecuteAction(ctx, pe.actionExecutor, action, machine, payload)
+}
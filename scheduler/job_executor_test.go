@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -29,43 +30,59 @@ type MockActionExecutor struct {
 	ExecutePatchFanControllerFunc func(machine interface{}, fanControllerPayloads Payload) error
 	ExecutePatchFanZoneFunc       func(machine interface{}, fanZonePayloads Payload) error
 	ExecutePatchPidControllerFunc func(machine interface{}, pidControllerPayloads Payload) error
+	CapturePreviousFunc           func(machine interface{}, action ActionType, payload Payload) (Payload, error)
+	VerifyAppliedFunc             func(machine interface{}, action ActionType, payload Payload, tolerance float64) error
 }
 
-func (m *MockActionExecutor) ExecutePatchManager(machine interface{}, managerPayloads Payload) error {
+func (m *MockActionExecutor) ExecutePatchManager(ctx context.Context, machine interface{}, managerPayloads Payload) error {
 	if m.ExecutePatchManagerFunc != nil {
 		return m.ExecutePatchManagerFunc(machine, managerPayloads)
 	}
 	return nil
 }
 
-func (m *MockActionExecutor) ExecutePatchProfile(machine interface{}, managerPayloads Payload) error {
+func (m *MockActionExecutor) ExecutePatchProfile(ctx context.Context, machine interface{}, managerPayloads Payload) error {
 	if m.ExecutePatchProfileFunc != nil {
 		return m.ExecutePatchProfileFunc(machine, managerPayloads)
 	}
 	return nil
 }
 
-func (m *MockActionExecutor) ExecutePatchFanController(machine interface{}, fanControllerPayloads Payload) error {
+func (m *MockActionExecutor) ExecutePatchFanController(ctx context.Context, machine interface{}, fanControllerPayloads Payload) error {
 	if m.ExecutePatchFanControllerFunc != nil {
 		return m.ExecutePatchFanControllerFunc(machine, fanControllerPayloads)
 	}
 	return nil
 }
 
-func (m *MockActionExecutor) ExecutePatchFanZone(machine interface{}, fanZonePayloads Payload) error {
+func (m *MockActionExecutor) ExecutePatchFanZone(ctx context.Context, machine interface{}, fanZonePayloads Payload) error {
 	if m.ExecutePatchFanZoneFunc != nil {
 		return m.ExecutePatchFanZoneFunc(machine, fanZonePayloads)
 	}
 	return nil
 }
 
-func (m *MockActionExecutor) ExecutePatchPidController(machine interface{}, pidControllerPayloads Payload) error {
+func (m *MockActionExecutor) ExecutePatchPidController(ctx context.Context, machine interface{}, pidControllerPayloads Payload) error {
 	if m.ExecutePatchPidControllerFunc != nil {
 		return m.ExecutePatchPidControllerFunc(machine, pidControllerPayloads)
 	}
 	return nil
 }
 
+func (m *MockActionExecutor) CapturePrevious(ctx context.Context, machine interface{}, action ActionType, payload Payload) (Payload, error) {
+	if m.CapturePreviousFunc != nil {
+		return m.CapturePreviousFunc(machine, action, payload)
+	}
+	return payload, nil
+}
+
+func (m *MockActionExecutor) VerifyApplied(ctx context.Context, machine interface{}, action ActionType, payload Payload, tolerance float64) error {
+	if m.VerifyAppliedFunc != nil {
+		return m.VerifyAppliedFunc(machine, action, payload, tolerance)
+	}
+	return nil
+}
+
 // TestNewPlatformValidator tests the constructor
 func TestNewPlatformValidator(t *testing.T) {
 	mockPlatformMgr := &MockJobPlatformManager{}
@@ -266,7 +283,7 @@ func TestPlatformExecutor_ExecuteJob(t *testing.T) {
 			tt.setupAction(mockActionExecutor)
 
 			executor := NewPlatformExecutor(mockPlatformMgr, mockActionExecutor)
-			history := executor.ExecuteJob(tt.job)
+			history := executor.ExecuteJob(context.Background(), tt.job)
 
 			if history == nil {
 				t.Fatal("ExecuteJob returned nil history")
@@ -484,7 +501,7 @@ func TestPlatformExecutor_MultiMachine(t *testing.T) {
 	}
 
 	executor := NewPlatformExecutor(mockPlatformMgr, mockActionExecutor)
-	history := executor.ExecuteJob(job)
+	history := executor.ExecuteJob(context.Background(), job)
 
 	if len(history.Results) != 3 {
 		t.Errorf("Expected 3 results, got %d", len(history.Results))
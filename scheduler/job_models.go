@@ -12,8 +12,13 @@ type ScheduleType string
 const (
 	ScheduleTypeOnce       ScheduleType = "Once"
 	ScheduleTypeContinuous ScheduleType = "Continuous"
+	ScheduleTypeCron       ScheduleType = "Cron"
+	ScheduleTypeInterval   ScheduleType = "Interval"
 )
 
+// MinScheduleInterval is the shortest supported 'Interval' schedule (the scheduler tick)
+const MinScheduleInterval = time.Second
+
 // DayOfWeek represents days of the week
 type DayOfWeek string
 
@@ -32,14 +37,37 @@ type Period struct {
 	StartDay     *string     `json:"StartDay,omitempty"`     // Format: YYYY-MM-DD
 	EndDay       *string     `json:"EndDay,omitempty"`       // Format: YYYY-MM-DD
 	DaysOfWeek   []DayOfWeek `json:"DaysOfWeek,omitempty"`   // e.g., ["Monday", "Sunday"]
-	DaysOfMonth  *string     `json:"DaysOfMonth,omitempty"`  // e.g., "1" or "1,15,30"
+	DaysOfMonth  *string     `json:"DaysOfMonth,omitempty"`  // e.g., "1", "1,15,30", "1-7", "L" or "Last Friday"
+	DayMatch     DayMatch    `json:"DayMatch,omitempty"`     // "All" (default) or "Any" when both DaysOfWeek and DaysOfMonth are set
 }
 
 // Schedule represents the scheduling information
 type Schedule struct {
-	Type   ScheduleType `json:"Type"`             // "Once" or "Continuous"
-	Time   string       `json:"Time"`             // Format: HH:MM:SS
-	Period *Period      `json:"Period,omitempty"` // Required for Continuous, null for Once
+	Type          ScheduleType `json:"Type"`                    // "Once", "Continuous", "Cron" or "Interval"
+	Time          string       `json:"Time,omitempty"`          // Format: HH:MM:SS (not used by Cron and Interval)
+	Period        *Period      `json:"Period,omitempty"`        // Required for Continuous, null otherwise
+	Cron          string       `json:"Cron,omitempty"`          // Cron expression, required for Cron, e.g. "*/15 9-17 * * MON-FRI"
+	Interval      string       `json:"Interval,omitempty"`      // Duration between runs, required for Interval, e.g. "30s", "15m", "2h"
+	StartTime     *time.Time   `json:"StartTime,omitempty"`     // RFC 3339, run time of a Once job or first Interval run (default: one Interval after creation)
+	EndTime       *time.Time   `json:"EndTime,omitempty"`       // RFC 3339, no Interval runs after this time
+	MaxExecutions int          `json:"MaxExecutions,omitempty"` // Stop an Interval job after this many runs (0 = unlimited)
+	TimeZone      string       `json:"TimeZone,omitempty"`      // IANA time zone, e.g. "America/New_York" (default: server local time)
+	Immediate     bool         `json:"Immediate,omitempty"`     // Run a Once job as soon as a worker is free
+}
+
+// Location returns the time zone the schedule is evaluated in.
+// An empty TimeZone uses the server's local time zone.
+func (s Schedule) Location() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TimeZone '%s': %v. Use an IANA time zone name such as 'America/New_York', 'Asia/Taipei' or 'UTC'", s.TimeZone, err)
+	}
+
+	return loc, nil
 }
 
 // Payload represents a general payload type for job actions
@@ -55,6 +83,12 @@ const (
 	JobStatusCompleted JobStatus = "Completed"
 	JobStatusFailed    JobStatus = "Failed"
 	JobStatusCancelled JobStatus = "Cancelled"
+	JobStatusSkipped   JobStatus = "Skipped"  // Due execution dropped by a blackout calendar
+	JobStatusDeferred  JobStatus = "Deferred" // Due execution postponed until a blackout ends
+	JobStatusTimedOut  JobStatus = "TimedOut" // Execution aborted by the job's Timeout
+	JobStatusPaused    JobStatus = "Paused"   // Scheduled executions suspended until the job is resumed
+
+	JobStatusVerificationFailed JobStatus = "VerificationFailed" // Patch accepted but the values read back differ (Verify jobs)
 )
 
 // MachineValidationResult represents validation result for a single machine
@@ -67,26 +101,61 @@ type MachineValidationResult struct {
 
 // Job represents a scheduled job
 type Job struct {
-	ID           string            `json:"Id"`
-	Name         string            `json:"Name,omitempty"`
-	Machines     []string          `json:"Machines"`
-	Action       ActionType        `json:"Action"`
-	Payload      Payload           `json:"Payload"`
-	Schedule     Schedule          `json:"Schedule"`
-	Status       JobStatus         `json:"Status"`
-	CreatedTime  time.Time         `json:"CreatedTime"`
-	LastRunTime  *time.Time        `json:"LastRunTime,omitempty"`
-	NextRunTime  *time.Time        `json:"NextRunTime,omitempty"`
-	ExecutionCount int             `json:"ExecutionCount"`
+	ID              string            `json:"Id"`
+	Name            string            `json:"Name,omitempty"`
+	Machines        []string          `json:"Machines"`
+	Action          ActionType        `json:"Action"`
+	Payload         Payload           `json:"Payload"`
+	Steps           []JobStep         `json:"Steps,omitempty"` // Workflow steps run in order per machine, replaces Action and Payload
+	Schedule        Schedule          `json:"Schedule"`
+	Status          JobStatus         `json:"Status"`
+	CreatedTime     time.Time         `json:"CreatedTime"`
+	LastRunTime     *time.Time        `json:"LastRunTime,omitempty"`
+	NextRunTime     *time.Time        `json:"NextRunTime,omitempty"`
+	ExecutionCount  int               `json:"ExecutionCount"`
+	Calendars       []string          `json:"Calendars,omitempty"`       // Blackout calendar IDs, global calendars always apply
+	BlackoutPolicy  BlackoutPolicy    `json:"BlackoutPolicy,omitempty"`  // "Skip" (default) or "Defer"
+	RetryPolicy     *RetryPolicy      `json:"RetryPolicy,omitempty"`     // Per-machine retries, nil tries each machine once
+	Timeout         string            `json:"Timeout,omitempty"`         // Maximum duration of one execution, e.g. "10m" (empty = no limit)
+	Rollout         *Rollout          `json:"Rollout,omitempty"`         // Batched execution, nil runs all machines at once
+	Priority        int               `json:"Priority,omitempty"`        // Run queue priority 0-100, higher runs first
+	MisfirePolicy   MisfirePolicy     `json:"MisfirePolicy,omitempty"`   // Overrides the service misfire policy
+	LastExecutionID int               `json:"LastExecutionId,omitempty"` // Sequence number of the newest execution history entry
+	DependsOn       []JobDependency   `json:"DependsOn,omitempty"`       // Parent jobs, replaces the schedule: the job runs when they finish
+	RevertAfter     string            `json:"RevertAfter,omitempty"`     // Restore the changed values this long after a run ("9h") or at this time of day ("18:00:00")
+	PendingRevert   *PendingRevert    `json:"PendingRevert,omitempty"`   // Values captured by the last runs, waiting to be restored
+	Verify          bool              `json:"Verify,omitempty"`          // Read the values back after patching and compare them with the payload
+	VerifyTolerance *float64          `json:"VerifyTolerance,omitempty"` // Difference allowed between requested and applied numbers (default 0.01)
+	LockPolicy      LockPolicy        `json:"LockPolicy,omitempty"`      // Overrides the service lock policy when a machine is locked
+	Template        *TemplateInstance `json:"Template,omitempty"`        // Template and parameter values the job was instantiated from
 }
 
 // JobCreateRequest represents the request to create a job
 type JobCreateRequest struct {
-	Name     string     `json:"Name,omitempty"`
-	Machines []string   `json:"Machines"`
-	Action   ActionType `json:"Action"`
-	Payload  Payload    `json:"Payload"`
-	Schedule Schedule   `json:"Schedule"`
+	Name            string          `json:"Name,omitempty"`
+	Machines        []string        `json:"Machines"`
+	Action          ActionType      `json:"Action"`
+	Payload         Payload         `json:"Payload"`
+	Steps           []JobStep       `json:"Steps,omitempty"` // Workflow steps, replaces Action and Payload
+	Schedule        Schedule        `json:"Schedule"`
+	Calendars       []string        `json:"Calendars,omitempty"`       // Blackout calendar IDs
+	BlackoutPolicy  BlackoutPolicy  `json:"BlackoutPolicy,omitempty"`  // "Skip" (default) or "Defer"
+	RetryPolicy     *RetryPolicy    `json:"RetryPolicy,omitempty"`     // Per-machine retries with backoff
+	Timeout         string          `json:"Timeout,omitempty"`         // Maximum duration of one execution, e.g. "10m"
+	Rollout         *Rollout        `json:"Rollout,omitempty"`         // Batched (rolling or canary) execution
+	Priority        int             `json:"Priority,omitempty"`        // Run queue priority 0-100, higher runs first (default 0)
+	MisfirePolicy   MisfirePolicy   `json:"MisfirePolicy,omitempty"`   // "Run", "Coalesce" or "Drop" (default: service policy)
+	DependsOn       []JobDependency `json:"DependsOn,omitempty"`       // Parent jobs and conditions, the Schedule must be omitted
+	RevertAfter     string          `json:"RevertAfter,omitempty"`     // Duration ("9h") or time of day ("18:00:00") after which the changes are restored
+	Verify          bool            `json:"Verify,omitempty"`          // Verify the applied values after patching
+	VerifyTolerance *float64        `json:"VerifyTolerance,omitempty"` // Difference allowed between requested and applied numbers (default 0.01)
+	LockPolicy      LockPolicy      `json:"LockPolicy,omitempty"`      // "Wait", "FailFast" or "Queue" (default: service policy)
+	DeepValidation  bool            `json:"DeepValidation,omitempty"`  // Check the payload against the resources of each machine, not stored on the job
+	RejectConflicts bool            `json:"RejectConflicts,omitempty"` // Reject the job if its runs conflict with other jobs, not stored on the job
+
+	// scheduleUnchanged is set for an update that keeps the job's Schedule. Its
+	// StartTime and EndTime were checked on create and may have passed since.
+	scheduleUnchanged bool
 }
 
 // UnmarshalJSON custom unmarshaler for JobCreateRequest to handle dynamic Payload type based on Action
@@ -105,47 +174,84 @@ func (j *JobCreateRequest) UnmarshalJSON(data []byte) error {
 	}
 
 	// Now unmarshal Payload based on Action type
-	switch j.Action {
+	payload, err := decodePayload(j.Action, aux.Payload)
+	if err != nil {
+		return err
+	}
+	j.Payload = payload
+
+	return nil
+}
+
+// UnmarshalJSON custom unmarshaler for Job so persisted jobs get their typed Payload back
+func (j *Job) UnmarshalJSON(data []byte) error {
+	type Alias Job
+	aux := &struct {
+		Payload json.RawMessage `json:"Payload"`
+		*Alias
+	}{
+		Alias: (*Alias)(j),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	payload, err := decodePayload(j.Action, aux.Payload)
+	if err != nil {
+		return err
+	}
+	j.Payload = payload
+
+	return nil
+}
+
+// decodePayload unmarshals a raw payload into the concrete payload type of the action
+func decodePayload(action ActionType, raw json.RawMessage) (Payload, error) {
+	// A missing payload stays nil (will be caught in validation)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	switch action {
 	case ActionPatchProfile:
 		var payload []ExecutePatchProfilePayload
-		if err := json.Unmarshal(aux.Payload, &payload); err != nil {
-			return fmt.Errorf("failed to unmarshal PatchProfile payload: %w", err)
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal PatchProfile payload: %w", err)
 		}
-		j.Payload = payload
+		return payload, nil
 	case ActionPatchManager:
 		var payload []ExecutePatchManagerPayload
-		if err := json.Unmarshal(aux.Payload, &payload); err != nil {
-			return fmt.Errorf("failed to unmarshal PatchManager payload: %w", err)
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal PatchManager payload: %w", err)
 		}
-		j.Payload = payload
+		return payload, nil
 	case ActionPatchFanController:
 		var payload []ExecutePatchFanControllerPayload
-		if err := json.Unmarshal(aux.Payload, &payload); err != nil {
-			return fmt.Errorf("failed to unmarshal PatchFanController payload: %w", err)
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal PatchFanController payload: %w", err)
 		}
-		j.Payload = payload
+		return payload, nil
 	case ActionPatchFanZone:
 		var payload []ExecutePatchFanZonePayload
-		if err := json.Unmarshal(aux.Payload, &payload); err != nil {
-			return fmt.Errorf("failed to unmarshal PatchFanZone payload: %w", err)
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal PatchFanZone payload: %w", err)
 		}
-		j.Payload = payload
+		return payload, nil
 	case ActionPatchPidController:
 		var payload []ExecutePatchPidControllerPayload
-		if err := json.Unmarshal(aux.Payload, &payload); err != nil {
-			return fmt.Errorf("failed to unmarshal PatchPidController payload: %w", err)
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal PatchPidController payload: %w", err)
 		}
-		j.Payload = payload
+		return payload, nil
 	default:
 		// For unknown actions, leave as-is (will be caught in validation)
 		var payload interface{}
-		if err := json.Unmarshal(aux.Payload, &payload); err != nil {
-			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
 		}
-		j.Payload = payload
+		return payload, nil
 	}
-
-	return nil
 }
 
 // JobValidationResponse represents the validation response
@@ -163,25 +269,54 @@ type JobValidationResponse struct {
 
 // ExecutionHistory represents a single execution record
 type ExecutionHistory struct {
-	JobID         string                    `json:"JobId"`
-	ExecutionTime time.Time                 `json:"ExecutionTime"`
-	Status        JobStatus                 `json:"Status"`
-	Results       []MachineExecutionResult  `json:"Results"`
+	ID            string                   `json:"Id"`
+	JobID         string                   `json:"JobId"`
+	ExecutionTime time.Time                `json:"ExecutionTime"`
+	Status        JobStatus                `json:"Status"`
+	Results       []MachineExecutionResult `json:"Results"`
+	Message       string                   `json:"Message,omitempty"`     // Why a Skipped or Deferred execution did not run, or why a rollout was aborted
+	Manual        bool                     `json:"Manual,omitempty"`      // Started by Actions/Run instead of the schedule
+	TriggeredBy   string                   `json:"TriggeredBy,omitempty"` // Parent job whose execution started this run of a dependent job
+	RevertOf      []string                 `json:"RevertOf,omitempty"`    // Executions whose changes this run restored (RevertAfter jobs)
 }
 
 // MachineExecutionResult represents execution result for a single machine
 type MachineExecutionResult struct {
-	MachineID string    `json:"MachineId"`
-	Success   bool      `json:"Success"`
-	Message   string    `json:"Message,omitempty"`
-	Error     string    `json:"Error,omitempty"`
-	StartTime time.Time `json:"StartTime"`
-	EndTime   time.Time `json:"EndTime"`
-	Duration  string    `json:"Duration"`
+	MachineID string                `json:"MachineId"`
+	Success   bool                  `json:"Success"`
+	Status    JobStatus             `json:"Status,omitempty"` // Completed, Failed, Cancelled, TimedOut or Skipped
+	Batch     int                   `json:"Batch,omitempty"`  // Rollout batch of the machine, 1 is the first
+	Message   string                `json:"Message,omitempty"`
+	Error     string                `json:"Error,omitempty"`
+	StartTime time.Time             `json:"StartTime"`
+	EndTime   time.Time             `json:"EndTime"`
+	Duration  string                `json:"Duration"`
+	Attempts  []ExecutionAttempt    `json:"Attempts,omitempty"` // Every attempt on the machine, oldest first
+	Steps     []StepExecutionResult `json:"Steps,omitempty"`    // Per-step results of a workflow job, in step order
+	Revert    []JobStep             `json:"Revert,omitempty"`   // Restores the values changed on the machine, captured before patching (RevertAfter jobs)
+}
+
+// ExecutionAttempt records a single attempt of an action on a machine
+type ExecutionAttempt struct {
+	Attempt    int        `json:"Attempt"`
+	Success    bool       `json:"Success"`
+	Error      string     `json:"Error,omitempty"`
+	ErrorClass ErrorClass `json:"ErrorClass,omitempty"`
+	Retryable  bool       `json:"Retryable,omitempty"`
+	StartTime  time.Time  `json:"StartTime"`
+	EndTime    time.Time  `json:"EndTime"`
+	Duration   string     `json:"Duration"`
 }
 
-// Validate validates the job creation request
+// Validate validates the job creation request against the current time. The
+// JobService validates with its own clock.
 func (j *JobCreateRequest) Validate() *JobValidationResponse {
+	return j.validateAt(time.Now())
+}
+
+// validateAt validates the job creation request, checking StartTime and
+// EndTime against now
+func (j *JobCreateRequest) validateAt(now time.Time) *JobValidationResponse {
 	response := &JobValidationResponse{
 		Valid:         true,
 		ScheduleValid: true,
@@ -203,22 +338,81 @@ func (j *JobCreateRequest) Validate() *JobValidationResponse {
 		return response
 	}
 
-	// Validate action
-	if err := j.validateAction(); err != nil {
+	// Validate action, or the steps of a workflow job
+	if len(j.Steps) > 0 {
+		actionErrors, payloadErrors := j.validateSteps()
+		if len(actionErrors) > 0 {
+			response.Valid = false
+			response.ActionValid = false
+			response.ActionErrors = append(response.ActionErrors, actionErrors...)
+		}
+		if len(payloadErrors) > 0 {
+			response.Valid = false
+			response.PayloadValid = false
+			response.PayloadErrors = append(response.PayloadErrors, payloadErrors...)
+		}
+	} else if err := j.validateAction(); err != nil {
 		response.Valid = false
 		response.ActionValid = false
 		response.ActionErrors = append(response.ActionErrors, err.Error())
 	}
 
+	// Validate execution timeout
+	if j.Timeout != "" {
+		if _, err := ParseJobTimeout(j.Timeout); err != nil {
+			response.Valid = false
+			response.ActionValid = false
+			response.ActionErrors = append(response.ActionErrors, err.Error())
+		}
+	}
+
+	// Validate retry policy
+	if j.RetryPolicy != nil {
+		if errs := j.RetryPolicy.Validate(); len(errs) > 0 {
+			response.Valid = false
+			response.ActionValid = false
+			response.ActionErrors = append(response.ActionErrors, errs...)
+		}
+	}
+
+	// Validate rollout
+	if j.Rollout != nil {
+		if errs := j.Rollout.Validate(); len(errs) > 0 {
+			response.Valid = false
+			response.ActionValid = false
+			response.ActionErrors = append(response.ActionErrors, errs...)
+		}
+	}
+
+	// Validate revert window
+	if errs := j.validateRevert(); len(errs) > 0 {
+		response.Valid = false
+		response.ActionValid = false
+		response.ActionErrors = append(response.ActionErrors, errs...)
+	}
+
+	// Validate verification settings
+	if errs := j.validateVerify(); len(errs) > 0 {
+		response.Valid = false
+		response.ActionValid = false
+		response.ActionErrors = append(response.ActionErrors, errs...)
+	}
+
 	// Validate payload
-	if err := j.validatePayload(); err != nil {
+	if len(j.Steps) > 0 {
+		// Step payloads were validated with the steps
+	} else if err := j.validatePayload(); err != nil {
 		response.Valid = false
 		response.PayloadValid = false
 		response.PayloadErrors = append(response.PayloadErrors, err.Error())
 	}
 
-	// Validate schedule
-	if errs := j.validateSchedule(); len(errs) > 0 {
+	// Validate schedule, dependent jobs are started by their parents instead
+	validateSchedule := func() []string { return j.validateSchedule(now) }
+	if len(j.DependsOn) > 0 {
+		validateSchedule = j.validateDependencies
+	}
+	if errs := validateSchedule(); len(errs) > 0 {
 		response.Valid = false
 		response.ScheduleValid = false
 		response.ScheduleErrors = errs
@@ -264,12 +458,33 @@ func (j *JobCreateRequest) validateAction() error {
 }
 
 // validateSchedule validates the schedule configuration
-func (j *JobCreateRequest) validateSchedule() []string {
+func (j *JobCreateRequest) validateSchedule(now time.Time) []string {
 	var errors []string
 
 	// Validate schedule type
-	if j.Schedule.Type != ScheduleTypeOnce && j.Schedule.Type != ScheduleTypeContinuous {
-		errors = append(errors, fmt.Sprintf("invalid schedule type: %s (must be 'Once' or 'Continuous')", j.Schedule.Type))
+	switch j.Schedule.Type {
+	case ScheduleTypeOnce, ScheduleTypeContinuous, ScheduleTypeCron, ScheduleTypeInterval:
+	default:
+		errors = append(errors, fmt.Sprintf("invalid schedule type: %s (must be 'Once', 'Continuous', 'Cron' or 'Interval')", j.Schedule.Type))
+	}
+
+	// Validate time zone
+	if _, err := j.Schedule.Location(); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	errors = append(errors, j.validateScheduleFieldsForType()...)
+	errors = append(errors, j.validateBlackout()...)
+	errors = append(errors, j.validateQueueing()...)
+
+	// Cron and Interval schedules are not tied to a time of day
+	switch j.Schedule.Type {
+	case ScheduleTypeCron:
+		return append(errors, j.validateCron(now)...)
+	case ScheduleTypeInterval:
+		return append(errors, j.validateInterval(now)...)
+	case ScheduleTypeOnce:
+		return append(errors, j.validateOnce(now)...)
 	}
 
 	// Validate time format (HH:MM:SS)
@@ -278,11 +493,7 @@ func (j *JobCreateRequest) validateSchedule() []string {
 	}
 
 	// Validate period based on schedule type
-	if j.Schedule.Type == ScheduleTypeOnce {
-		if j.Schedule.Period != nil {
-			errors = append(errors, "Period must be null for 'Once' schedule type")
-		}
-	} else if j.Schedule.Type == ScheduleTypeContinuous {
+	if j.Schedule.Type == ScheduleTypeContinuous {
 		if j.Schedule.Period == nil {
 			errors = append(errors, "Period is required for 'Continuous' schedule type")
 		} else {
@@ -293,6 +504,192 @@ func (j *JobCreateRequest) validateSchedule() []string {
 	return errors
 }
 
+// validateOnce validates a 'Once' schedule, which runs at the next occurrence
+// of Time, at an absolute StartTime, or immediately
+func (j *JobCreateRequest) validateOnce(now time.Time) []string {
+	var errors []string
+	schedule := j.Schedule
+
+	modes := 0
+	if schedule.Time != "" {
+		modes++
+		if _, err := time.Parse("15:04:05", schedule.Time); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid time format: %s (expected HH:MM:SS)", schedule.Time))
+		}
+	}
+	if schedule.StartTime != nil {
+		modes++
+		if !schedule.StartTime.After(now) && !j.scheduleUnchanged {
+			errors = append(errors, fmt.Sprintf("StartTime %s is in the past. Specify a future RFC 3339 time or use Immediate to run now", schedule.StartTime.Format(time.RFC3339)))
+		}
+	}
+	if schedule.Immediate {
+		modes++
+	}
+
+	if modes == 0 {
+		errors = append(errors, "one of Time (HH:MM:SS), StartTime (RFC 3339) or Immediate is required for 'Once' schedule type")
+	} else if modes > 1 {
+		errors = append(errors, "only one of Time, StartTime or Immediate may be set for 'Once' schedule type")
+	}
+
+	if schedule.Period != nil {
+		errors = append(errors, "Period must be null for 'Once' schedule type")
+	}
+
+	return errors
+}
+
+// validateScheduleFieldsForType rejects schedule fields that do not apply to the schedule type
+func (j *JobCreateRequest) validateScheduleFieldsForType() []string {
+	var errors []string
+	schedule := j.Schedule
+
+	if schedule.Type != ScheduleTypeCron && schedule.Cron != "" {
+		errors = append(errors, fmt.Sprintf("Cron is only allowed for 'Cron' schedule type, got '%s'", schedule.Type))
+	}
+
+	if schedule.Type != ScheduleTypeInterval {
+		if schedule.Interval != "" {
+			errors = append(errors, fmt.Sprintf("Interval is only allowed for 'Interval' schedule type, got '%s'", schedule.Type))
+		}
+		if schedule.EndTime != nil || schedule.MaxExecutions != 0 {
+			errors = append(errors, fmt.Sprintf("EndTime and MaxExecutions are only allowed for 'Interval' schedule type, got '%s'", schedule.Type))
+		}
+		if schedule.StartTime != nil && schedule.Type != ScheduleTypeOnce {
+			errors = append(errors, fmt.Sprintf("StartTime is only allowed for 'Once' and 'Interval' schedule types, got '%s'", schedule.Type))
+		}
+	}
+
+	if schedule.Type != ScheduleTypeOnce && schedule.Immediate {
+		errors = append(errors, fmt.Sprintf("Immediate is only allowed for 'Once' schedule type, got '%s'", schedule.Type))
+	}
+
+	return errors
+}
+
+// validateBlackout validates the blackout calendar references and policy.
+// Whether the calendars exist is checked by the job service.
+func (j *JobCreateRequest) validateBlackout() []string {
+	var errors []string
+
+	switch j.BlackoutPolicy {
+	case "", BlackoutPolicySkip, BlackoutPolicyDefer:
+	default:
+		errors = append(errors, fmt.Sprintf("invalid BlackoutPolicy: %s (must be 'Skip' or 'Defer')", j.BlackoutPolicy))
+	}
+
+	seen := make(map[string]bool)
+	for _, calendarID := range j.Calendars {
+		if calendarID == "" {
+			errors = append(errors, "Calendars must not contain empty IDs")
+		} else if seen[calendarID] {
+			errors = append(errors, fmt.Sprintf("calendar '%s' is listed more than once in Calendars", calendarID))
+		}
+		seen[calendarID] = true
+	}
+
+	return errors
+}
+
+// validateQueueing validates the run queue priority, misfire policy and lock policy
+func (j *JobCreateRequest) validateQueueing() []string {
+	var errors []string
+
+	if j.Priority < MinJobPriority || j.Priority > MaxJobPriority {
+		errors = append(errors, fmt.Sprintf("Priority must be between %d and %d, got %d", MinJobPriority, MaxJobPriority, j.Priority))
+	}
+
+	if j.MisfirePolicy != "" {
+		if err := ValidateMisfirePolicy(j.MisfirePolicy); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	if j.LockPolicy != "" {
+		if err := ValidateLockPolicy(j.LockPolicy); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	return errors
+}
+
+// ParseJobTimeout parses the Timeout of a job. Executions are bounded to at
+// least one second so a run can reach the BMCs at all.
+func ParseJobTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Timeout '%s': %v. Use a Go duration such as '90s', '10m' or '1h'", value, err)
+	}
+	if timeout < time.Second {
+		return 0, fmt.Errorf("Timeout must be at least 1s, got %s", value)
+	}
+	return timeout, nil
+}
+
+// validateInterval validates an 'Interval' schedule
+func (j *JobCreateRequest) validateInterval(now time.Time) []string {
+	var errors []string
+	schedule := j.Schedule
+
+	if schedule.Interval == "" {
+		errors = append(errors, "Interval is required for 'Interval' schedule type (e.g. \"30s\", \"15m\", \"2h\")")
+	} else if interval, err := time.ParseDuration(schedule.Interval); err != nil {
+		errors = append(errors, fmt.Sprintf("invalid Interval: %s (expected a duration such as \"30s\", \"15m\" or \"2h\")", schedule.Interval))
+	} else if interval < MinScheduleInterval {
+		errors = append(errors, fmt.Sprintf("Interval must be at least %s, got %s", MinScheduleInterval, schedule.Interval))
+	}
+
+	if schedule.StartTime != nil && schedule.EndTime != nil && !schedule.StartTime.Before(*schedule.EndTime) {
+		errors = append(errors, "StartTime must be before EndTime")
+	}
+
+	if schedule.EndTime != nil && schedule.EndTime.Before(now) && !j.scheduleUnchanged {
+		errors = append(errors, fmt.Sprintf("EndTime %s is in the past, the job would never run", schedule.EndTime.Format(time.RFC3339)))
+	}
+
+	if schedule.MaxExecutions < 0 {
+		errors = append(errors, fmt.Sprintf("MaxExecutions must not be negative, got %d (use 0 for unlimited)", schedule.MaxExecutions))
+	}
+
+	if schedule.Time != "" {
+		errors = append(errors, "Time must be empty for 'Interval' schedule type (runs are spaced by Interval from StartTime)")
+	}
+
+	if schedule.Period != nil {
+		errors = append(errors, "Period must be null for 'Interval' schedule type")
+	}
+
+	return errors
+}
+
+// validateCron validates a 'Cron' schedule
+func (j *JobCreateRequest) validateCron(now time.Time) []string {
+	var errors []string
+
+	if j.Schedule.Cron == "" {
+		errors = append(errors, "Cron expression is required for 'Cron' schedule type (e.g. \"*/15 9-17 * * MON-FRI\")")
+	} else if cron, err := ParseCron(j.Schedule.Cron); err != nil {
+		errors = append(errors, err.Error())
+	} else {
+		// A valid expression can still name a date that never occurs, e.g. February 30
+		if loc, err := j.Schedule.Location(); err == nil && cron.Next(now.In(loc)).IsZero() {
+			errors = append(errors, fmt.Sprintf("Cron expression '%s' never matches a date in the next %d years. Check the day-of-month and month fields", j.Schedule.Cron, cronSearchYears))
+		}
+	}
+
+	if j.Schedule.Time != "" {
+		errors = append(errors, "Time must be empty for 'Cron' schedule type (the time of day is part of the cron expression)")
+	}
+
+	if j.Schedule.Period != nil {
+		errors = append(errors, "Period must be null for 'Cron' schedule type")
+	}
+
+	return errors
+}
+
 // validatePeriod validates the period configuration
 func (j *JobCreateRequest) validatePeriod() []string {
 	var errors []string
@@ -334,11 +731,16 @@ func (j *JobCreateRequest) validatePeriod() []string {
 		}
 	}
 
-	// Validate DaysOfMonth (should be numbers 1-31, comma-separated)
+	// Validate DaysOfMonth calendar expression
 	if period.DaysOfMonth != nil && *period.DaysOfMonth != "" {
-		// Simple validation - could be enhanced
-		// Format: "1" or "1,15,30"
-		// For now, just check it's not empty
+		if _, err := ParseDaysOfMonth(*period.DaysOfMonth); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	// Validate how DaysOfWeek and DaysOfMonth combine
+	if period.DayMatch != "" && period.DayMatch != DayMatchAll && period.DayMatch != DayMatchAny {
+		errors = append(errors, fmt.Sprintf("invalid DayMatch: %s (must be 'All' or 'Any')", period.DayMatch))
 	}
 
 	// Ensure at least one recurrence pattern is specified
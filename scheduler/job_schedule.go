@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// scheduleEntry is a job waiting for its NextRunTime (or its pending revert)
+type scheduleEntry struct {
+	job   *Job
+	due   time.Time // Due time of the job when the entry was last updated
+	index int
+}
+
+// scheduleHeap orders entries by due time (earliest first), then by job ID
+type scheduleHeap []*scheduleEntry
+
+func (h scheduleHeap) Len() int { return len(h) }
+
+func (h scheduleHeap) Less(i, j int) bool {
+	if !h[i].due.Equal(h[j].due) {
+		return h[i].due.Before(h[j].due)
+	}
+	return h[i].job.ID < h[j].job.ID
+}
+
+func (h scheduleHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *scheduleHeap) Push(x interface{}) {
+	entry := x.(*scheduleEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *scheduleHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*h = old[:n-1]
+	return entry
+}
+
+// scheduleIndex is a min-heap of jobs keyed by NextRunTime, so a scheduler tick
+// only touches the jobs that are due instead of scanning every job. It has its
+// own lock: the scheduler peeks at the earliest run without js.mu, and every
+// change of a job's NextRunTime (made with js.mu held) is mirrored with set.
+type scheduleIndex struct {
+	mu      sync.Mutex
+	entries scheduleHeap
+	byJob   map[string]*scheduleEntry
+}
+
+func newScheduleIndex() *scheduleIndex {
+	return &scheduleIndex{byJob: make(map[string]*scheduleEntry)}
+}
+
+// set indexes the job at its NextRunTime, a job without one is removed
+// (caller holds js.mu)
+func (s *scheduleIndex) set(job *Job) {
+	s.setAt(job, job.NextRunTime)
+}
+
+// setAt indexes the job at due, a nil due removes it (caller holds js.mu)
+func (s *scheduleIndex) setAt(job *Job, due *time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, indexed := s.byJob[job.ID]
+	if due == nil {
+		if indexed {
+			heap.Remove(&s.entries, entry.index)
+			delete(s.byJob, job.ID)
+		}
+		return
+	}
+
+	if indexed {
+		entry.job = job
+		entry.due = *due
+		heap.Fix(&s.entries, entry.index)
+		return
+	}
+
+	entry = &scheduleEntry{job: job, due: *due}
+	heap.Push(&s.entries, entry)
+	s.byJob[job.ID] = entry
+}
+
+// remove drops the job from the index, if present
+func (s *scheduleIndex) remove(jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, indexed := s.byJob[jobID]; indexed {
+		heap.Remove(&s.entries, entry.index)
+		delete(s.byJob, jobID)
+	}
+}
+
+// next returns the earliest due time, false if no job is scheduled
+func (s *scheduleIndex) next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) == 0 {
+		return time.Time{}, false
+	}
+	return s.entries[0].due, true
+}
+
+// popDue removes and returns the entries due at or before now, earliest first
+func (s *scheduleIndex) popDue(now time.Time) []*scheduleEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*scheduleEntry
+	for len(s.entries) > 0 && !now.Before(s.entries[0].due) {
+		entry := heap.Pop(&s.entries).(*scheduleEntry)
+		delete(s.byJob, entry.job.ID)
+		due = append(due, entry)
+	}
+	return due
+}
+
+func (s *scheduleIndex) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
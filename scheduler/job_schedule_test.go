@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleIndex_OrdersByNextRunTime(t *testing.T) {
+	index := newScheduleIndex()
+	now := time.Now()
+
+	at := func(id string, offset time.Duration) *Job {
+		next := now.Add(offset)
+		return &Job{ID: id, NextRunTime: &next}
+	}
+
+	later := at("later", time.Hour)
+	index.set(at("b", -time.Minute))
+	index.set(at("a", -time.Minute))
+	index.set(at("first", -time.Hour))
+	index.set(later)
+	assert.Equal(t, 4, index.len())
+
+	next, scheduled := index.next()
+	require.True(t, scheduled)
+	assert.Equal(t, now.Add(-time.Hour), next)
+
+	// Moving a job updates its position instead of adding a second entry
+	moved := now.Add(-2 * time.Hour)
+	later.NextRunTime = &moved
+	index.set(later)
+	assert.Equal(t, 4, index.len())
+
+	var ids []string
+	for _, entry := range index.popDue(now) {
+		ids = append(ids, entry.job.ID)
+	}
+	assert.Equal(t, []string{"later", "first", "a", "b"}, ids)
+	assert.Zero(t, index.len())
+
+	// A job due exactly now is due
+	index.set(at("now", 0))
+	require.Len(t, index.popDue(now), 1)
+
+	// Jobs without a NextRunTime are not indexed
+	index.set(at("x", time.Minute))
+	index.set(&Job{ID: "x"})
+	index.remove("missing")
+	_, scheduled = index.next()
+	assert.False(t, scheduled)
+}
+
+func TestJobService_ScheduleIndexTracksJobs(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, service.schedule.len())
+
+	require.NoError(t, service.PauseJob(job.ID))
+	assert.Zero(t, service.schedule.len())
+
+	require.NoError(t, service.ResumeJob(job.ID))
+	assert.Equal(t, 1, service.schedule.len())
+	next, _ := service.schedule.next()
+	assert.Equal(t, *job.NextRunTime, next)
+
+	require.NoError(t, service.CancelJob(job.ID))
+	assert.Zero(t, service.schedule.len())
+
+	other, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteJob(other.ID))
+	assert.Zero(t, service.schedule.len())
+}
+
+func TestJobService_IdleTickDoesNotLockJobs(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	_, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	// With nothing due a tick must not wait for js.mu
+	service.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		service.checkAndExecuteJobs()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("idle scheduler tick blocked on the job lock")
+	}
+	service.mu.Unlock()
+	<-done
+}
+
+func TestJobService_RescheduledAfterRun(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	makeJobDue(service, job)
+	service.checkAndExecuteJobs()
+	waitForExecutions(t, service, job.ID, 1)
+
+	require.Eventually(t, func() bool {
+		service.mu.RLock()
+		defer service.mu.RUnlock()
+		next, scheduled := service.schedule.next()
+		return scheduled && job.NextRunTime != nil && next.Equal(*job.NextRunTime) && next.After(time.Now())
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// newBenchmarkJobService returns a service holding count jobs, none of them due
+func newBenchmarkJobService(b *testing.B, count int) *JobService {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	b.Cleanup(service.Stop)
+
+	now := time.Now()
+	service.mu.Lock()
+	for i := 0; i < count; i++ {
+		next := now.Add(time.Hour + time.Duration(i)*time.Second)
+		job := &Job{
+			ID:          fmt.Sprintf("Job-%d", i),
+			Machines:    []string{fmt.Sprintf("machine%d", i)},
+			Action:      ActionPatchProfile,
+			Status:      JobStatusPending,
+			CreatedTime: now,
+			NextRunTime: &next,
+		}
+		service.jobs[job.ID] = job
+		service.schedule.set(job)
+	}
+	service.mu.Unlock()
+	return service
+}
+
+// linearScanTick is the scheduler tick before the schedule index: it takes
+// the write lock and checks every job for being due
+func linearScanTick(js *JobService) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	now := time.Now()
+	for _, job := range js.jobs {
+		if job.Status == JobStatusCancelled || job.Status == JobStatusPaused {
+			continue
+		}
+
+		js.runningMu.Lock()
+		isRunning := js.runningJobs[job.ID]
+		js.runningMu.Unlock()
+
+		if isRunning || js.queue.contains(job.ID) {
+			continue
+		}
+
+		if job.NextRunTime != nil && now.After(*job.NextRunTime) {
+			js.queue.push(job, *job.NextRunTime, false, now)
+		}
+	}
+}
+
+// BenchmarkSchedulerTick compares the cost of one idle tick
+func BenchmarkSchedulerTick(b *testing.B) {
+	for _, count := range []int{1000, 10000, 50000} {
+		service := newBenchmarkJobService(b, count)
+
+		b.Run(fmt.Sprintf("LinearScan/%d", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				linearScanTick(service)
+			}
+		})
+
+		b.Run(fmt.Sprintf("ScheduleIndex/%d", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				service.checkAndExecuteJobs()
+			}
+		})
+	}
+}
+
+// BenchmarkGetJobWhileScheduling measures API reads while the scheduler ticks
+// every millisecond in the background. The slowest read shows how long a
+// request can be held up by a tick.
+func BenchmarkGetJobWhileScheduling(b *testing.B) {
+	const count = 10000
+
+	for _, mode := range []struct {
+		name string
+		tick func(*JobService)
+	}{
+		{"LinearScan", linearScanTick},
+		{"ScheduleIndex", (*JobService).checkAndExecuteJobs},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			service := newBenchmarkJobService(b, count)
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(time.Millisecond)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-stop:
+						return
+					case <-ticker.C:
+						mode.tick(service)
+					}
+				}
+			}()
+
+			var slowest time.Duration
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				if _, err := service.GetJob(fmt.Sprintf("Job-%d", i%count)); err != nil {
+					b.Fatal(err)
+				}
+				if elapsed := time.Since(start); elapsed > slowest {
+					slowest = elapsed
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(slowest.Nanoseconds()), "max-ns/read")
+
+			close(stop)
+			wg.Wait()
+		})
+	}
+}
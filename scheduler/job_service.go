@@ -1,10 +1,14 @@
 package scheduler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,12 +17,33 @@ import (
 
 const (
 	DefaultWorkerPoolSize = 99
+
+	// maxScheduleSearchDays bounds the search for the next matching day of a Continuous schedule
+	maxScheduleSearchDays = 8 * 366
+
+	// DefaultExecutionHistoryLimit is the number of executions retained per job
+	DefaultExecutionHistoryLimit = 50
 )
 
 var (
 	LogsDir = "./logs" // Default logs directory, can be configured
 )
 
+var (
+	// ErrJobNotFound is returned when no job has the requested ID
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobRunning is returned when a job cannot be changed while it executes
+	ErrJobRunning = errors.New("job is running")
+	// ErrInvalidJobUpdate is returned when a job update is not a valid JSON job definition
+	ErrInvalidJobUpdate = errors.New("invalid job update")
+	// ErrInvalidJobState is returned when a job action is not allowed in the job's current status
+	ErrInvalidJobState = errors.New("action not allowed in the current job status")
+	// ErrJobHasDependents is returned when deleting a job that other jobs depend on
+	ErrJobHasDependents = errors.New("job has dependent jobs")
+	// ErrJobHasPendingRevert is returned when deleting a job whose changes have not been reverted yet
+	ErrJobHasPendingRevert = errors.New("job has a pending revert")
+)
+
 // JobService manages job scheduling and execution
 type JobService struct {
 	jobs           map[string]*Job
@@ -31,21 +56,54 @@ type JobService struct {
 	workerPool     chan struct{} // Semaphore for controlling concurrent job executions
 	workerPoolSize int           // Current worker pool size (dynamically adjustable)
 	runningJobs    map[string]bool
-	runningMu      sync.Mutex // Separate mutex for running jobs tracking
+	runningCancels map[string]context.CancelFunc // Aborts the in-flight execution of a running job
+	runningMu      sync.Mutex                    // Separate mutex for running jobs tracking
+	store          JobStore   // Optional persistence backend, nil keeps jobs in memory only
+	executions     map[string][]*ExecutionHistory // Retained execution history per job, oldest first
+	historyLimit   int                            // Maximum executions retained per job
+	historyMaxAge  time.Duration                  // Executions older than this are dropped (0 keeps them)
+	calendars      map[string]*BlackoutCalendar   // Blackout calendars by ID
+	queue          *runQueue                      // Due runs waiting for a worker
+	schedule       *scheduleIndex                 // Jobs ordered by NextRunTime, has its own lock
+	reverts        *scheduleIndex                 // Jobs ordered by the RevertTime of their PendingRevert
+	reverting      map[string]bool                // Jobs whose revert is executing
+	templates      map[string]*JobTemplate        // Job templates by ID
+	misfirePolicy    MisfirePolicy // Applied to queued runs that waited longer than misfireThreshold
+	misfireThreshold time.Duration // 0 disables misfire handling
+	conflictWindow   time.Duration // Runs of different jobs on a resource closer than this conflict
+	conflictHorizon  time.Duration // How far ahead RejectConflicts checks a job
+	clock            Clock         // Source of the current time for scheduling decisions
 }
 
 // JobValidator validates jobs against machines
 type JobValidator interface {
 	ValidateMachines(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult
+	// ValidateMachineResources checks the payload against the resources each
+	// machine exposes; it connects to the machines (DeepValidation)
+	ValidateMachineResources(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult
 }
 
-// JobExecutor executes jobs on machines
+// JobExecutor executes jobs on machines. Machine operations still outstanding
+// when ctx is cancelled or times out are aborted.
 type JobExecutor interface {
-	ExecuteJob(job *Job) *ExecutionHistory
+	ExecuteJob(ctx context.Context, job *Job) *ExecutionHistory
 }
 
-// NewJobService creates a new job service
+// NewJobService creates a new job service that keeps jobs in memory only
 func NewJobService(validator JobValidator, executor JobExecutor) *JobService {
+	return NewJobServiceWithStore(validator, executor, nil)
+}
+
+// NewJobServiceWithStore creates a new job service backed by a job store.
+// Persisted jobs are loaded before the scheduler starts.
+func NewJobServiceWithStore(validator JobValidator, executor JobExecutor, store JobStore) *JobService {
+	return NewJobServiceWithClock(validator, executor, store, SystemClock)
+}
+
+// NewJobServiceWithClock creates a new job service that schedules jobs by the
+// given clock instead of the wall clock. The scheduler still ticks every
+// second; each tick runs the jobs that are due by the clock.
+func NewJobServiceWithClock(validator JobValidator, executor JobExecutor, store JobStore, clock Clock) *JobService {
 	log := utility.GetLogger()
 	
 	// Create logs directory if it doesn't exist
@@ -61,34 +119,47 @@ func NewJobService(validator JobValidator, executor JobExecutor) *JobService {
 		workerPoolSize: DefaultWorkerPoolSize,
 		workerPool:     make(chan struct{}, DefaultWorkerPoolSize), // Buffered channel as semaphore
 		runningJobs:    make(map[string]bool),
+		runningCancels: make(map[string]context.CancelFunc),
+		store:          store,
+		executions:     make(map[string][]*ExecutionHistory),
+		historyLimit:   DefaultExecutionHistoryLimit,
+		calendars:      make(map[string]*BlackoutCalendar),
+		templates:      make(map[string]*JobTemplate),
+		queue:          newRunQueue(),
+		schedule:       newScheduleIndex(),
+		reverts:        newScheduleIndex(),
+		reverting:      make(map[string]bool),
+		misfirePolicy:    DefaultMisfirePolicy,
+		misfireThreshold: DefaultMisfireThreshold,
+		conflictWindow:   DefaultConflictWindow,
+		conflictHorizon:  DefaultConflictHorizon,
+		clock:            clock,
 	}
 
+	// Load persisted jobs before the first tick
+	js.loadJobs()
+
 	// Start the scheduler
 	js.startScheduler()
 
 	return js
 }
 
-// CreateJob creates a new job after validation
+// CreateJob creates a new job after validation. An error without a validation
+// response means the valid job could not be stored.
 func (js *JobService) CreateJob(req *JobCreateRequest) (*Job, *JobValidationResponse, error) {
-	js.mu.Lock()
-	defer js.mu.Unlock()
+	return js.createJob(req, nil)
+}
 
-	// Validate basic job structure
-	validationResp := req.Validate()
+// createJob creates a new job after validation, recording the template it
+// was instantiated from, if any
+func (js *JobService) createJob(req *JobCreateRequest, template *TemplateInstance) (*Job, *JobValidationResponse, error) {
+	resourceResults := js.validateMachineResources(req)
 
-	// Validate machines against the platform
-	if js.validator != nil {
-		machineResults := js.validator.ValidateMachines(req.Machines, req.Action, req.Payload)
-		validationResp.MachineResults = machineResults
+	js.mu.Lock()
+	defer js.mu.Unlock()
 
-		// Check if all machines are valid
-		for _, result := range machineResults {
-			if !result.Valid {
-				validationResp.Valid = false
-			}
-		}
-	}
+	validationResp := js.validateJobRequest("", req, resourceResults)
 
 	// If validation fails, return the validation response
 	if !validationResp.Valid {
@@ -100,23 +171,49 @@ func (js *JobService) CreateJob(req *JobCreateRequest) (*Job, *JobValidationResp
 
 	// Create the job
 	job := &Job{
-		ID:             jobID,
-		Name:           req.Name,
-		Machines:       req.Machines,
-		Action:         req.Action,
-		Payload:        req.Payload,
-		Schedule:       req.Schedule,
-		Status:         JobStatusPending,
-		CreatedTime:    time.Now(),
-		ExecutionCount: 0,
+		ID:              jobID,
+		Name:            req.Name,
+		Machines:        req.Machines,
+		Action:          req.Action,
+		Payload:         req.Payload,
+		Steps:           req.Steps,
+		Schedule:        req.Schedule,
+		Status:          JobStatusPending,
+		CreatedTime:     js.clock.Now(),
+		ExecutionCount:  0,
+		Calendars:       req.Calendars,
+		BlackoutPolicy:  req.BlackoutPolicy,
+		RetryPolicy:     req.RetryPolicy,
+		Timeout:         req.Timeout,
+		Rollout:         req.Rollout,
+		Priority:        req.Priority,
+		MisfirePolicy:   req.MisfirePolicy,
+		DependsOn:       req.DependsOn,
+		RevertAfter:     req.RevertAfter,
+		Verify:          req.Verify,
+		VerifyTolerance: req.VerifyTolerance,
+		LockPolicy:      req.LockPolicy,
+		Template:        template,
+	}
+
+	// Calculate next run time, dependent jobs run when their parents finish
+	var nextRun time.Time
+	if len(job.DependsOn) == 0 {
+		nextRun = js.calculateNextRunTime(job)
+		if nextRun.IsZero() {
+			return nil, validationResp, fmt.Errorf("job schedule has no upcoming run time. Check EndDay, DaysOfWeek/DaysOfMonth, EndTime and MaxExecutions of the schedule")
+		}
+		job.NextRunTime = &nextRun
 	}
 
-	// Calculate next run time
-	nextRun := js.calculateNextRunTime(job)
-	job.NextRunTime = &nextRun
+	// Persist before the job becomes visible so it is never lost on restart
+	if err := js.persistJob(job); err != nil {
+		return nil, nil, fmt.Errorf("failed to store job: %w", err)
+	}
 
 	// Store the job
 	js.jobs[jobID] = job
+	js.schedule.set(job)
 
 	log := utility.GetLogger()
 	log.Info().
@@ -124,9 +221,254 @@ func (js *JobService) CreateJob(req *JobCreateRequest) (*Job, *JobValidationResp
 		Time("nextRun", nextRun).
 		Msg("Job created")
 
+	// Immediate jobs are dispatched without waiting for the next tick
+	if job.Schedule.Immediate {
+		go js.checkAndExecuteJobs()
+	}
+
 	return job, validationResp, nil
 }
 
+// UpdateJob applies a partial job definition to an existing job. patch is a
+// JSON object with any subset of the JobCreateRequest fields; each field given
+// replaces the job's value and null clears an optional field. The merged job is
+// validated like a new one and replaces the job only if it is valid, so a failed
+// update leaves the job untouched. Running jobs cannot be updated.
+func (js *JobService) UpdateJob(jobID string, patch []byte) (*Job, *JobValidationResponse, error) {
+	log := utility.GetLogger()
+
+	// Deep validation connects to the machines, so it runs on a preview of the
+	// updated job before js.mu is taken
+	var preview *JobCreateRequest
+	js.mu.RLock()
+	if job, exists := js.jobs[jobID]; exists {
+		preview, _ = mergeJobRequest(job, patch)
+	}
+	js.mu.RUnlock()
+	var resourceResults []MachineValidationResult
+	if preview != nil {
+		resourceResults = js.validateMachineResources(preview)
+	}
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	job, exists := js.jobs[jobID]
+	if !exists {
+		log.Warn().Str("jobID", jobID).Msg("Job not found")
+		return nil, nil, fmt.Errorf("%w: '%s'. Use GET /jobs to list available jobs", ErrJobNotFound, jobID)
+	}
+
+	js.runningMu.Lock()
+	isRunning := js.runningJobs[jobID]
+	js.runningMu.Unlock()
+	if isRunning || job.Status == JobStatusRunning {
+		return nil, nil, fmt.Errorf("%w: '%s' is executing. Retry the update once the execution has finished", ErrJobRunning, jobID)
+	}
+
+	req, err := mergeJobRequest(job, patch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validationResp := js.validateJobRequest(jobID, req, resourceResults)
+	if !validationResp.Valid {
+		return nil, validationResp, fmt.Errorf("job validation failed")
+	}
+
+	// Build the updated job on a copy so the original stays intact on failure
+	updated := *job
+	updated.Name = req.Name
+	updated.Machines = req.Machines
+	updated.Action = req.Action
+	updated.Payload = req.Payload
+	updated.Steps = req.Steps
+	updated.Schedule = req.Schedule
+	updated.Calendars = req.Calendars
+	updated.BlackoutPolicy = req.BlackoutPolicy
+	updated.RetryPolicy = req.RetryPolicy
+	updated.Timeout = req.Timeout
+	updated.Rollout = req.Rollout
+	updated.Priority = req.Priority
+	updated.MisfirePolicy = req.MisfirePolicy
+	updated.DependsOn = req.DependsOn
+	updated.RevertAfter = req.RevertAfter
+	updated.Verify = req.Verify
+	updated.VerifyTolerance = req.VerifyTolerance
+	updated.LockPolicy = req.LockPolicy
+
+	// Only a change to when the job runs reschedules it. Other updates keep the
+	// status and NextRunTime, so a finished or exhausted job is not run again.
+	if scheduleChanged(job, &updated) {
+		var nextRun time.Time
+		if len(updated.DependsOn) == 0 {
+			nextRun = js.calculateNextRunTime(&updated)
+			if nextRun.IsZero() {
+				return nil, validationResp, fmt.Errorf("job schedule has no upcoming run time. Check EndDay, DaysOfWeek/DaysOfMonth, EndTime and MaxExecutions of the schedule")
+			}
+		}
+
+		// A cancelled or paused job keeps its status, any other job is rescheduled
+		if updated.Status == JobStatusCancelled || updated.Status == JobStatusPaused {
+			updated.NextRunTime = nil
+		} else {
+			updated.Status = JobStatusPending
+			updated.NextRunTime = nil
+			if !nextRun.IsZero() {
+				updated.NextRunTime = &nextRun
+			}
+		}
+	}
+
+	if err := js.persistJob(&updated); err != nil {
+		return nil, nil, err
+	}
+
+	*job = updated
+	js.schedule.set(job)
+
+	// A run queued under the old definition is dropped, the job is queued again when due
+	js.queue.remove(jobID)
+
+	log.Info().
+		Str("jobID", jobID).
+		Str("status", string(job.Status)).
+		Msg("Job updated")
+
+	if job.Status == JobStatusPending && job.Schedule.Immediate {
+		go js.checkAndExecuteJobs()
+	}
+
+	return job, validationResp, nil
+}
+
+// scheduleChanged reports whether an update changes when a job runs: its
+// Schedule, DependsOn or Calendars
+func scheduleChanged(job, updated *Job) bool {
+	timing := func(j *Job) string {
+		data, _ := json.Marshal(struct {
+			Schedule  Schedule
+			DependsOn []JobDependency `json:",omitempty"`
+			Calendars []string        `json:",omitempty"`
+		}{j.Schedule, j.DependsOn, j.Calendars})
+		return string(data)
+	}
+	return timing(job) != timing(updated)
+}
+
+// mergeJobRequest overlays the fields of a JSON patch on the definition of a job
+func mergeJobRequest(job *Job, patch []byte) (*JobCreateRequest, error) {
+	var patchFields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &patchFields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJobUpdate, err)
+	}
+
+	current, err := json.Marshal(&JobCreateRequest{
+		Name:            job.Name,
+		Machines:        job.Machines,
+		Action:          job.Action,
+		Payload:         job.Payload,
+		Steps:           job.Steps,
+		Schedule:        job.Schedule,
+		Calendars:       job.Calendars,
+		BlackoutPolicy:  job.BlackoutPolicy,
+		RetryPolicy:     job.RetryPolicy,
+		Timeout:         job.Timeout,
+		Rollout:         job.Rollout,
+		Priority:        job.Priority,
+		MisfirePolicy:   job.MisfirePolicy,
+		DependsOn:       job.DependsOn,
+		RevertAfter:     job.RevertAfter,
+		Verify:          job.Verify,
+		VerifyTolerance: job.VerifyTolerance,
+		LockPolicy:      job.LockPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize job '%s': %w", job.ID, err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return nil, fmt.Errorf("failed to serialize job '%s': %w", job.ID, err)
+	}
+	for name, value := range patchFields {
+		fields[name] = value
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJobUpdate, err)
+	}
+
+	var req JobCreateRequest
+	if err := json.Unmarshal(merged, &req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJobUpdate, err)
+	}
+
+	// A job whose StartTime or EndTime has passed can still be renamed or moved
+	// to other machines
+	scheduleData, _ := json.Marshal(job.Schedule)
+	mergedScheduleData, _ := json.Marshal(req.Schedule)
+	req.scheduleUnchanged = string(scheduleData) == string(mergedScheduleData)
+
+	return &req, nil
+}
+
+// validateJobRequest validates a job definition, its calendar and dependency
+// references and its machines. jobID is empty for a new job. resourceResults are
+// the DeepValidation results, computed before js.mu was taken (caller holds js.mu).
+func (js *JobService) validateJobRequest(jobID string, req *JobCreateRequest, resourceResults []MachineValidationResult) *JobValidationResponse {
+	// Validate basic job structure
+	validationResp := req.validateAt(js.clock.Now())
+
+	// Referenced blackout calendars must exist
+	if errs := js.validateCalendarReferences(req.Calendars); len(errs) > 0 {
+		validationResp.Valid = false
+		validationResp.ScheduleValid = false
+		validationResp.ScheduleErrors = append(validationResp.ScheduleErrors, errs...)
+		validationResp.Message = "Job validation failed"
+	}
+
+	// Parent jobs must exist and must not depend on this job
+	if errs := js.validateDependencyReferences(jobID, req.DependsOn); len(errs) > 0 {
+		validationResp.Valid = false
+		validationResp.ScheduleValid = false
+		validationResp.ScheduleErrors = append(validationResp.ScheduleErrors, errs...)
+		validationResp.Message = "Job validation failed"
+	}
+
+	// Runs must not collide with other jobs on the same machines (opt-in)
+	if req.RejectConflicts && validationResp.Valid {
+		if errs := js.validateConflicts(jobID, req); len(errs) > 0 {
+			validationResp.Valid = false
+			validationResp.ScheduleValid = false
+			validationResp.ScheduleErrors = append(validationResp.ScheduleErrors, errs...)
+			validationResp.Message = "Job validation failed"
+		}
+	}
+
+	// Validate machines against the platform
+	if js.validator != nil {
+		var machineResults []MachineValidationResult
+		if len(req.Steps) > 0 {
+			machineResults = validateStepMachines(js.validator.ValidateMachines, req.Machines, req.Steps)
+		} else {
+			machineResults = js.validator.ValidateMachines(req.Machines, req.Action, req.Payload)
+		}
+		mergeResourceResults(machineResults, resourceResults)
+		validationResp.MachineResults = machineResults
+
+		// Check if all machines are valid
+		for _, result := range machineResults {
+			if !result.Valid {
+				validationResp.Valid = false
+			}
+		}
+	}
+
+	return validationResp
+}
+
 // GetJob retrieves a job by ID
 func (js *JobService) GetJob(jobID string) (*Job, error) {
 	log := utility.GetLogger()
@@ -156,19 +498,63 @@ func (js *JobService) ListJobs() []*Job {
 	return jobs
 }
 
-// DeleteJob deletes a job by ID
+// DeleteJob deletes a job by ID. A job whose changes are waiting to be
+// reverted is kept until the revert has run.
 func (js *JobService) DeleteJob(jobID string) error {
+	return js.deleteJob(jobID, false)
+}
+
+// DeleteJobDiscardingRevert deletes a job even if its changes are still waiting
+// to be reverted. The captured values are dropped and the machines keep the
+// job's settings, e.g. for machines that were decommissioned.
+func (js *JobService) DeleteJobDiscardingRevert(jobID string) error {
+	return js.deleteJob(jobID, true)
+}
+
+// deleteJob removes a job, its history and any scheduled or queued run
+func (js *JobService) deleteJob(jobID string, discardRevert bool) error {
 	log := utility.GetLogger()
 
 	js.mu.Lock()
 	defer js.mu.Unlock()
 
-	if _, exists := js.jobs[jobID]; !exists {
+	job, exists := js.jobs[jobID]
+	if !exists {
 		log.Warn().Str("jobID", jobID).Msg("Job not found")
 		return fmt.Errorf("job with ID '%s' not found in job service (active jobs: %d). Use GET /jobs to list available jobs", jobID, len(js.jobs))
 	}
 
+	if dependents := js.dependentsOf(jobID); len(dependents) > 0 {
+		ids := make([]string, len(dependents))
+		for i, dependent := range dependents {
+			ids[i] = dependent.ID
+		}
+		return fmt.Errorf("%w: '%s' is a dependency of %s. Delete those jobs or remove '%s' from their DependsOn first", ErrJobHasDependents, jobID, strings.Join(ids, ", "), jobID)
+	}
+
+	// Deleting the job would drop the captured values and leave the machines changed
+	if job.PendingRevert != nil && !discardRevert {
+		return fmt.Errorf("%w: the changes of '%s' are restored at %s. Delete the job once the revert has run, or discard the revert explicitly", ErrJobHasPendingRevert, jobID, job.PendingRevert.RevertTime.Format(time.RFC3339))
+	}
+
+	if js.store != nil {
+		if err := js.store.DeleteJob(jobID); err != nil {
+			log.Error().Err(err).Str("jobID", jobID).Msg("Failed to delete persisted job")
+			return err
+		}
+		if err := js.store.DeleteExecutions(jobID); err != nil {
+			log.Warn().Err(err).Str("jobID", jobID).Msg("Failed to delete persisted execution history")
+		}
+	}
+
 	delete(js.jobs, jobID)
+	delete(js.executions, jobID)
+	js.queue.remove(jobID)
+	js.schedule.remove(jobID)
+	js.reverts.remove(jobID)
+	if job.PendingRevert != nil {
+		log.Warn().Str("jobID", jobID).Int("machines", len(job.PendingRevert.Machines)).Msg("Pending revert discarded with the job")
+	}
 	log.Info().Str("jobID", jobID).Msg("Job deleted")
 
 	return nil
@@ -187,12 +573,160 @@ func (js *JobService) CancelJob(jobID string) error {
 		return fmt.Errorf("job with ID '%s' not found in job service (active jobs: %d). Use GET /jobs to list available jobs", jobID, len(js.jobs))
 	}
 
+	previousStatus := job.Status
 	job.Status = JobStatusCancelled
+	if err := js.persistJob(job); err != nil {
+		job.Status = previousStatus
+		return err
+	}
+	js.queue.remove(jobID)
+	js.schedule.remove(jobID)
+
+	// Abort the machine operations of a run that is in progress
+	js.runningMu.Lock()
+	if cancel, running := js.runningCancels[jobID]; running {
+		cancel()
+		log.Info().Str("jobID", jobID).Msg("Aborting in-flight execution")
+	}
+	js.runningMu.Unlock()
+
 	log.Info().Str("jobID", jobID).Msg("Job cancelled")
 
 	return nil
 }
 
+// PauseJob suspends the scheduled executions of a pending or running job until
+// it is resumed. A running execution completes; runs due while the job is
+// paused are not made up.
+func (js *JobService) PauseJob(jobID string) error {
+	log := utility.GetLogger()
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	job, exists := js.jobs[jobID]
+	if !exists {
+		log.Warn().Str("jobID", jobID).Msg("Job not found")
+		return fmt.Errorf("%w: '%s'. Use GET /jobs to list available jobs", ErrJobNotFound, jobID)
+	}
+
+	switch job.Status {
+	case JobStatusPaused:
+		return nil
+	case JobStatusPending, JobStatusRunning:
+	default:
+		return fmt.Errorf("%w: job '%s' is %s and has no scheduled executions to pause", ErrInvalidJobState, jobID, job.Status)
+	}
+
+	previousStatus, previousNextRun := job.Status, job.NextRunTime
+	job.Status = JobStatusPaused
+	job.NextRunTime = nil
+	if err := js.persistJob(job); err != nil {
+		job.Status, job.NextRunTime = previousStatus, previousNextRun
+		return err
+	}
+	js.queue.remove(jobID)
+	js.schedule.set(job)
+
+	log.Info().Str("jobID", jobID).Msg("Job paused")
+
+	return nil
+}
+
+// ResumeJob reschedules a paused job from its next run after now
+func (js *JobService) ResumeJob(jobID string) error {
+	log := utility.GetLogger()
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	job, exists := js.jobs[jobID]
+	if !exists {
+		log.Warn().Str("jobID", jobID).Msg("Job not found")
+		return fmt.Errorf("%w: '%s'. Use GET /jobs to list available jobs", ErrJobNotFound, jobID)
+	}
+
+	switch job.Status {
+	case JobStatusPending, JobStatusRunning:
+		return nil
+	case JobStatusPaused:
+	default:
+		return fmt.Errorf("%w: job '%s' is %s, only paused jobs can be resumed", ErrInvalidJobState, jobID, job.Status)
+	}
+
+	// A paused job may still be finishing the execution it was paused in
+	js.runningMu.Lock()
+	isRunning := js.runningJobs[jobID]
+	js.runningMu.Unlock()
+
+	// Dependent jobs have no schedule, they wait for their parents again
+	var nextRun time.Time
+	if len(job.DependsOn) == 0 {
+		nextRun = js.calculateNextRunTime(job)
+		if nextRun.IsZero() {
+			return fmt.Errorf("%w: job '%s' has no upcoming run time. Update its schedule with PATCH before resuming", ErrInvalidJobState, jobID)
+		}
+	}
+
+	job.Status = JobStatusPending
+	if isRunning {
+		job.Status = JobStatusRunning
+	}
+	job.NextRunTime = nil
+	if !nextRun.IsZero() {
+		job.NextRunTime = &nextRun
+	}
+	if err := js.persistJob(job); err != nil {
+		job.Status = JobStatusPaused
+		job.NextRunTime = nil
+		return err
+	}
+	js.schedule.set(job)
+
+	log.Info().
+		Str("jobID", jobID).
+		Time("nextRun", nextRun).
+		Msg("Job resumed")
+
+	return nil
+}
+
+// RunJob queues an execution of the job outside its schedule; it starts as
+// soon as a worker is free. The run is recorded in the execution history as
+// manual and leaves the job's status, NextRunTime and ExecutionCount unchanged.
+func (js *JobService) RunJob(jobID string) error {
+	log := utility.GetLogger()
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	job, exists := js.jobs[jobID]
+	if !exists {
+		log.Warn().Str("jobID", jobID).Msg("Job not found")
+		return fmt.Errorf("%w: '%s'. Use GET /jobs to list available jobs", ErrJobNotFound, jobID)
+	}
+
+	if job.Status == JobStatusCancelled {
+		return fmt.Errorf("%w: job '%s' is cancelled", ErrInvalidJobState, jobID)
+	}
+
+	js.runningMu.Lock()
+	isRunning := js.runningJobs[jobID]
+	js.runningMu.Unlock()
+
+	if isRunning || job.Status == JobStatusRunning || js.queue.contains(jobID) {
+		return fmt.Errorf("%w: '%s' is already queued or executing", ErrJobRunning, jobID)
+	}
+
+	now := js.clock.Now()
+	js.queue.push(job, time.Time{}, true, now)
+	log.Info().Str("jobID", jobID).Msg("Manual job execution queued")
+
+	js.dispatchQueue(now)
+
+	return nil
+}
+
 // startScheduler starts the job scheduler
 func (js *JobService) startScheduler() {
 	log := utility.GetLogger()
@@ -257,69 +791,191 @@ func (js *JobService) Stop() {
 	}
 }
 
-// checkAndExecuteJobs checks for jobs that need to be executed
+// checkAndExecuteJobs starts due reverts, queues the jobs that are due and
+// dispatches queued runs to free workers. Only due jobs are looked at: when
+// nothing is due the tick returns without taking js.mu, so API calls do not
+// wait for the scheduler.
 func (js *JobService) checkAndExecuteJobs() {
+	now := js.clock.Now()
+
+	next, scheduled := js.schedule.next()
+	nextRevert, reverting := js.reverts.next()
+	if (!scheduled || now.Before(next)) && (!reverting || now.Before(nextRevert)) {
+		return
+	}
+
 	js.mu.Lock()
 	defer js.mu.Unlock()
 
-	now := time.Now()
-	log := utility.GetLogger()
+	// Restore the values of RevertAfter jobs whose window has closed
+	js.startDueReverts(now)
 
-	for _, job := range js.jobs {
-		// Skip cancelled or completed jobs
-		if job.Status == JobStatusCancelled {
+	// Due jobs come out of the index earliest first and are queued in that order
+	for _, entry := range js.schedule.popDue(now) {
+		job := entry.job
+
+		// Skip deleted, cancelled and paused jobs
+		if current, exists := js.jobs[job.ID]; !exists || current != job {
+			continue
+		}
+		if job.Status == JobStatusCancelled || job.Status == JobStatusPaused || job.NextRunTime == nil {
 			continue
 		}
 
-		// Skip if job is already running
+		// The run moved since the entry was indexed, index it at its current time
+		if !job.NextRunTime.Equal(entry.due) {
+			js.schedule.set(job)
+			continue
+		}
+
+		// Skip if job is already running or waiting for a worker, it is
+		// indexed again when that run completes
 		js.runningMu.Lock()
 		isRunning := js.runningJobs[job.ID]
 		js.runningMu.Unlock()
-		
-		if isRunning {
+
+		if isRunning || js.queue.contains(job.ID) {
 			continue
 		}
 
-		// Check if job should run now
-		// Use After() to handle cases where we might have missed the exact time
-		if job.NextRunTime != nil && now.After(*job.NextRunTime) {
+		// Blackout calendars skip or defer the execution instead of running it
+		if calendarID, until, blocked := js.activeBlackout(job, now); blocked {
+			js.applyBlackout(job, now, calendarID, until)
+			continue
+		}
+
+		js.queue.push(job, *job.NextRunTime, false, now)
+	}
+
+	js.dispatchQueue(now)
+}
+
+// dispatchQueue starts queued runs, highest priority first, while workers are
+// free. Scheduled runs that waited longer than the misfire threshold are
+// handled by the misfire policy (caller holds js.mu).
+func (js *JobService) dispatchQueue(now time.Time) {
+	log := utility.GetLogger()
+
+	for js.queue.len() > 0 {
+		// Try to acquire a worker slot (non-blocking), queued runs wait for the next free worker
+		select {
+		case js.workerPool <- struct{}{}:
+		default:
+			log.Debug().
+				Int("queued", js.queue.len()).
+				Int("poolSize", js.workerPoolSize).
+				Msg("Worker pool full, runs stay queued")
+			return
+		}
+
+		run := js.queue.pop(now)
+		job := run.job
+
+		var catchUpFrom time.Time
+		if run.scheduled() {
 			// Calculate how late we are (for monitoring purposes)
-			delay := now.Sub(*job.NextRunTime)
+			delay := now.Sub(run.dueTime)
 			
 			// Log if execution is significantly delayed (> 2 seconds)
 			if delay > 2*time.Second {
 				log.Warn().
 					Str("jobID", job.ID).
 					Str("jobName", job.Name).
-					Time("scheduledTime", *job.NextRunTime).
+					Time("scheduledTime", run.dueTime).
 					Time("actualTime", now).
 					Dur("delay", delay).
 					Msg("Job execution delayed")
 			}
-			
-			// Try to acquire a worker slot (non-blocking)
-			select {
-			case js.workerPool <- struct{}{}:
-				// Successfully acquired a worker slot, execute job
-				go js.executeJobAsync(job)
-			default:
-				// No worker slots available, skip this execution
-				log.Warn().
-					Str("jobID", job.ID).
-					Msg("Worker pool full, skipping job execution (will retry next cycle)")
+
+			if js.misfireThreshold > 0 && delay > js.misfireThreshold {
+				js.queue.misfires++
+				switch js.jobMisfirePolicy(job) {
+				case MisfirePolicyDrop:
+					<-js.workerPool
+					js.dropMisfire(job, now, delay)
+					continue
+				case MisfirePolicyRun:
+					catchUpFrom = run.dueTime
+				}
 			}
 		}
+
+		// Claim the job before releasing the lock so it is not queued again
+		js.runningMu.Lock()
+		js.runningJobs[job.ID] = true
+		js.runningMu.Unlock()
+
+		go js.executeRun(run, catchUpFrom)
+	}
+}
+
+// jobMisfirePolicy returns the misfire policy of a job, falling back to the
+// service default (caller holds js.mu)
+func (js *JobService) jobMisfirePolicy(job *Job) MisfirePolicy {
+	if job.MisfirePolicy != "" {
+		return job.MisfirePolicy
+	}
+	return js.misfirePolicy
+}
+
+// dropMisfire records a misfired run as skipped and moves the job to its next
+// run (caller holds js.mu)
+func (js *JobService) dropMisfire(job *Job, now time.Time, delay time.Duration) {
+	log := utility.GetLogger()
+
+	js.skipToNextRun(job, now)
+	js.recordExecution(job, &ExecutionHistory{
+		ExecutionTime: now,
+		Status:        JobStatusSkipped,
+		Results:       []MachineExecutionResult{},
+		Message: fmt.Sprintf("Run misfired, it waited %s for a worker (threshold %s) and was dropped",
+			delay.Round(time.Second), js.misfireThreshold),
+	})
+	js.persistJobOrWarn(job)
+
+	log.Warn().
+		Str("jobID", job.ID).
+		Dur("delay", delay).
+		Msg("Misfired run dropped")
+}
+
+// skipToNextRun moves a job past its due run without executing it. Once jobs,
+// and schedules with no runs left, end as Skipped (caller holds js.mu).
+func (js *JobService) skipToNextRun(job *Job, now time.Time) {
+	if job.Schedule.Type == ScheduleTypeOnce {
+		job.Status = JobStatusSkipped
+		job.NextRunTime = nil
+	} else if nextRun := js.calculateNextRunTimeFrom(job, now); nextRun.IsZero() {
+		job.Status = JobStatusSkipped
+		job.NextRunTime = nil
+	} else {
+		job.NextRunTime = &nextRun
 	}
+	js.schedule.set(job)
 }
 
-// executeJobAsync executes a job asynchronously
-func (js *JobService) executeJobAsync(job *Job) {
-	// Ensure we release the worker slot when done
+// executeJobAsync executes a job asynchronously. A manual run (Actions/Run)
+// does not advance the job's schedule.
+func (js *JobService) executeJobAsync(job *Job, manual bool) {
+	js.executeRun(&queuedRun{job: job, manual: manual}, time.Time{})
+}
+
+// executeRun executes a run on a worker slot the caller acquired. The next run
+// is calculated from catchUpFrom when it is set, so runs missed while the job
+// waited are still made up, and from now otherwise. A triggered run executes
+// on its machines only and then triggers the job's own dependents.
+func (js *JobService) executeRun(run *queuedRun, catchUpFrom time.Time) {
+	job, manual := run.job, run.manual
+	// Ensure we release the worker slot when done and hand it to the next queued run
 	defer func() {
 		<-js.workerPool // Release worker slot
 		js.runningMu.Lock()
 		delete(js.runningJobs, job.ID)
 		js.runningMu.Unlock()
+
+		js.mu.Lock()
+		js.dispatchQueue(js.clock.Now())
+		js.mu.Unlock()
 	}()
 
 	// Mark job as running
@@ -334,58 +990,296 @@ func (js *JobService) executeJobAsync(job *Job) {
 		Int("poolSize", js.workerPoolSize).
 		Msg("Executing job")
 
-	// Update job status
+	// Update job status, a job cancelled since it was dispatched does not run
 	js.mu.Lock()
+	if job.Status == JobStatusCancelled {
+		js.mu.Unlock()
+		return
+	}
+	previousStatus := job.Status
 	job.Status = JobStatusRunning
+	js.persistJobOrWarn(job)
+	ctx, cancel := js.executionContext(job)
+	target := job
+	if run.machines != nil {
+		subset := *job
+		subset.Machines = run.machines
+		target = &subset
+	}
 	js.mu.Unlock()
 
-	// Execute the job
-	history := js.executor.ExecuteJob(job)
+	js.runningMu.Lock()
+	js.runningCancels[job.ID] = cancel
+	js.runningMu.Unlock()
+
+	// Execute the job, the execution is timed by the service clock
+	start := js.clock.Now()
+	history := js.executor.ExecuteJob(ctx, target)
+	history.ExecutionTime = start
+
+	js.runningMu.Lock()
+	delete(js.runningCancels, job.ID)
+	js.runningMu.Unlock()
+	cancel()
 
 	// Update job status and times
 	js.mu.Lock()
 	defer js.mu.Unlock()
 
-	now := time.Now()
+	now := js.clock.Now()
 	job.LastRunTime = &now
-	job.ExecutionCount++
-
-	// Determine job status based on execution results
-	allSuccess := true
-	for _, result := range history.Results {
-		if !result.Success {
-			allSuccess = false
-			break
-		}
+	if !manual {
+		// Only scheduled runs count towards the schedule, e.g. MaxExecutions
+		job.ExecutionCount++
 	}
 
-	if allSuccess {
-		history.Status = JobStatusCompleted
-	} else {
-		history.Status = JobStatusFailed
-	}
+	// Determine job status based on execution results
+	history.Status = executionStatus(history.Results)
+	history.Manual = manual
+	history.TriggeredBy = run.triggeredBy
 
 	// Update job status
-	if job.Schedule.Type == ScheduleTypeOnce {
+	if job.Status == JobStatusCancelled {
+		// Cancelled while running, the job stays cancelled
+		job.NextRunTime = nil
+	} else if manual {
+		// Manual runs leave the schedule alone, the job returns to its previous
+		// status unless it was paused meanwhile
+		if job.Status == JobStatusRunning {
+			job.Status = previousStatus
+		}
+	} else if len(job.DependsOn) > 0 {
+		// Dependent jobs wait for the next trigger
+		if job.Status == JobStatusRunning {
+			job.Status = JobStatusPending
+		}
+	} else if job.Schedule.Type == ScheduleTypeOnce {
+		job.Status = history.Status
+		job.NextRunTime = nil
+	} else if nextRun := js.nextRunAfterExecution(job, catchUpFrom); nextRun.IsZero() {
+		// Recurring schedule has no runs left (EndTime or MaxExecutions reached)
 		job.Status = history.Status
 		job.NextRunTime = nil
+		log.Info().Str("jobID", job.ID).Msg("Job schedule exhausted, no further runs")
+	} else if job.Status == JobStatusPaused {
+		// Paused while running, the next run is scheduled on resume
+		job.NextRunTime = nil
 	} else {
-		// For continuous jobs, calculate next run time
-		nextRun := js.calculateNextRunTime(job)
+		// For recurring jobs, schedule the next run
 		job.NextRunTime = &nextRun
 		job.Status = JobStatusPending
 	}
 
+	// Index the job at its new NextRunTime; a scheduled run that fell due
+	// during a manual run is picked up on the next tick
+	js.schedule.set(job)
+
+	// Keep the run in the job's execution history
+	js.recordExecution(job, history)
+
+	// Keep the captured values until the revert window closes
+	js.scheduleRevert(job, history, now)
+
+	js.persistJobOrWarn(job)
+
 	log.Info().
 		Str("jobID", job.ID).
 		Str("status", string(history.Status)).
 		Msg("Job execution completed")
+
+	js.triggerDependents(job, now)
 }
 
-// calculateNextRunTime calculates the next run time for a job
+// nextRunAfterExecution returns the next run of a recurring job after an
+// execution, counted from catchUpFrom when set (caller holds js.mu)
+func (js *JobService) nextRunAfterExecution(job *Job, catchUpFrom time.Time) time.Time {
+	if catchUpFrom.IsZero() {
+		return js.calculateNextRunTime(job)
+	}
+	return js.calculateNextRunTimeFrom(job, catchUpFrom)
+}
+
+// executionContext returns the context of a job run, bounded by the job's
+// Timeout when one is set (caller holds js.mu)
+func (js *JobService) executionContext(job *Job) (context.Context, context.CancelFunc) {
+	if job.Timeout != "" {
+		if timeout, err := ParseJobTimeout(job.Timeout); err == nil {
+			return context.WithTimeout(context.Background(), timeout)
+		}
+	}
+	return context.WithCancel(context.Background())
+}
+
+// executionStatus derives the status of a run from its machine results.
+// Interrupted runs report Cancelled or TimedOut before Failed.
+func executionStatus(results []MachineExecutionResult) JobStatus {
+	status := JobStatusCompleted
+	for _, result := range results {
+		switch {
+		case result.Status == JobStatusCancelled:
+			return JobStatusCancelled
+		case result.Status == JobStatusTimedOut:
+			status = JobStatusTimedOut
+		case result.Status == JobStatusVerificationFailed && status == JobStatusCompleted:
+			status = JobStatusVerificationFailed
+		case !result.Success && result.Status != JobStatusVerificationFailed && status != JobStatusTimedOut:
+			status = JobStatusFailed
+		}
+	}
+	return status
+}
+
+// recordExecution adds a finished run to the job's execution history, applies
+// the retention limits and writes it to the execution log (caller holds js.mu)
+func (js *JobService) recordExecution(job *Job, history *ExecutionHistory) {
+	log := utility.GetLogger()
+
+	// Executions are numbered per job so IDs stay stable across restarts.
+	// Skipped and deferred executions take a number too.
+	job.LastExecutionID++
+	history.ID = strconv.Itoa(job.LastExecutionID)
+	history.JobID = job.ID
+
+	js.executions[job.ID] = js.pruneExecutions(append(js.executions[job.ID], history))
+
+	if js.store != nil {
+		if err := js.store.SaveExecutions(job.ID, js.executions[job.ID]); err != nil {
+			log.Error().Err(err).Str("jobID", job.ID).Msg("Failed to persist execution history")
+		}
+	}
+
+	js.logExecutionHistory(history)
+}
+
+// pruneExecutions drops executions beyond the retention limits, oldest first
+func (js *JobService) pruneExecutions(executions []*ExecutionHistory) []*ExecutionHistory {
+	if js.historyMaxAge > 0 {
+		cutoff := js.clock.Now().Add(-js.historyMaxAge)
+		first := 0
+		for first < len(executions) && executions[first].ExecutionTime.Before(cutoff) {
+			first++
+		}
+		executions = executions[first:]
+	}
+
+	if len(executions) > js.historyLimit {
+		executions = executions[len(executions)-js.historyLimit:]
+	}
+
+	return executions
+}
+
+// loadJobs restores persisted blackout calendars and jobs into memory. Jobs that
+// were interrupted while running are put back to Pending so they are scheduled again.
+func (js *JobService) loadJobs() {
+	log := utility.GetLogger()
+
+	if js.store == nil {
+		return
+	}
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	// Calendars and templates do not depend on the jobs loading
+	js.loadCalendars()
+	js.loadTemplates()
+
+	jobs, err := js.store.LoadJobs()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load persisted jobs")
+		return
+	}
+
+	for _, job := range jobs {
+		// Jobs persisted before skipped executions existed numbered history by ExecutionCount
+		if job.LastExecutionID < job.ExecutionCount {
+			job.LastExecutionID = job.ExecutionCount
+		}
+
+		if job.Status == JobStatusRunning {
+			log.Warn().Str("jobID", job.ID).Msg("Job was interrupted while running, rescheduling")
+			job.Status = JobStatusPending
+			if job.Schedule.Type == ScheduleTypeOnce && job.NextRunTime == nil {
+				now := js.clock.Now()
+				job.NextRunTime = &now
+			}
+			js.persistJobOrWarn(job)
+		}
+		js.jobs[job.ID] = job
+		js.schedule.set(job)
+		if job.PendingRevert != nil {
+			js.reverts.setAt(job, &job.PendingRevert.RevertTime)
+		}
+
+		executions, err := js.store.LoadExecutions(job.ID)
+		if err != nil {
+			log.Warn().Err(err).Str("jobID", job.ID).Msg("Failed to load execution history")
+			continue
+		}
+		if len(executions) > 0 {
+			js.executions[job.ID] = executions
+		}
+	}
+
+	log.Info().Int("jobs", len(jobs)).Msg("Restored persisted jobs")
+}
+
+// persistJob writes a job to the store (caller holds js.mu)
+func (js *JobService) persistJob(job *Job) error {
+	if js.store == nil {
+		return nil
+	}
+	if err := js.store.SaveJob(job); err != nil {
+		log := utility.GetLogger()
+		log.Error().Err(err).Str("jobID", job.ID).Msg("Failed to persist job")
+		return err
+	}
+	return nil
+}
+
+// persistJobOrWarn writes a job to the store, logging instead of failing (caller holds js.mu)
+func (js *JobService) persistJobOrWarn(job *Job) {
+	_ = js.persistJob(job)
+}
+
+// calculateNextRunTime calculates the next run time for a job.
+// A zero time means a recurring schedule has no runs left.
 func (js *JobService) calculateNextRunTime(job *Job) time.Time {
+	return js.calculateNextRunTimeFrom(job, js.clock.Now())
+}
+
+// calculateNextRunTimeFrom calculates the first run time of a job after now.
+// Times of day, StartDay/EndDay and day matching are evaluated in the
+// schedule's time zone, stepping by calendar day so DST changes keep the
+// wall-clock time. A time that falls into a DST gap runs at the equivalent
+// time after the clocks jump forward.
+func (js *JobService) calculateNextRunTimeFrom(job *Job, now time.Time) time.Time {
 	log := utility.GetLogger()
-	now := time.Now()
+
+	loc, err := job.Schedule.Location()
+	if err != nil {
+		log.Error().Err(err).Str("jobID", job.ID).Msg("Invalid schedule time zone, using server local time")
+		loc = time.Local
+	}
+	now = now.In(loc)
+
+	switch job.Schedule.Type {
+	case ScheduleTypeCron:
+		return js.calculateNextCronRunTime(job, now)
+	case ScheduleTypeInterval:
+		return js.calculateNextIntervalRunTime(job, now)
+	}
+
+	// One-shot jobs at an absolute time or as soon as possible
+	if job.Schedule.Type == ScheduleTypeOnce {
+		if job.Schedule.Immediate {
+			return now
+		}
+		if job.Schedule.StartTime != nil {
+			return job.Schedule.StartTime.In(loc)
+		}
+	}
 
 	// Parse the scheduled time
 	schedTime, err := time.Parse("15:04:05", job.Schedule.Time)
@@ -394,13 +1288,16 @@ func (js *JobService) calculateNextRunTime(job *Job) time.Time {
 		return now.Add(24 * time.Hour) // Default to next day
 	}
 
+	// atDay returns the scheduled time of day, the given number of days after date
+	atDay := func(date time.Time, days int) time.Time {
+		return wallClockTime(date.Year(), date.Month(), date.Day()+days, schedTime, loc)
+	}
+
 	if job.Schedule.Type == ScheduleTypeOnce {
 		// For "Once" type, schedule for today at the specified time, or tomorrow if time has passed
-		nextRun := time.Date(now.Year(), now.Month(), now.Day(),
-			schedTime.Hour(), schedTime.Minute(), schedTime.Second(), 0, now.Location())
-
+		nextRun := atDay(now, 0)
 		if nextRun.Before(now) {
-			nextRun = nextRun.Add(24 * time.Hour)
+			nextRun = atDay(now, 1)
 		}
 
 		return nextRun
@@ -416,90 +1313,142 @@ func (js *JobService) calculateNextRunTime(job *Job) time.Time {
 	// Start from today or StartDay, whichever is later
 	startDate := now
 	if period.StartDay != nil {
-		if startDay, err := time.Parse("2006-01-02", *period.StartDay); err == nil {
+		if startDay, err := time.ParseInLocation("2006-01-02", *period.StartDay, loc); err == nil {
 			if startDay.After(startDate) {
 				startDate = startDay
 			}
 		}
 	}
 
-	// Find the next valid execution time
-	candidate := time.Date(startDate.Year(), startDate.Month(), startDate.Day(),
-		schedTime.Hour(), schedTime.Minute(), schedTime.Second(), 0, startDate.Location())
-
-	// If candidate is in the past, start from tomorrow
-	if candidate.Before(now) {
-		candidate = candidate.Add(24 * time.Hour)
+	daysOfMonth, err := parsePeriodDaysOfMonth(period.DaysOfMonth)
+	if err != nil {
+		log.Error().Err(err).Str("jobID", job.ID).Msg("Error parsing DaysOfMonth")
+		return time.Time{}
 	}
 
-	// Find next matching day (up to 365 days in the future)
-	for i := 0; i < 365; i++ {
-		if js.isValidExecutionDate(candidate, period) {
+	// Find next matching day. Rare combinations such as Friday the 13th or
+	// February 29 need a search window of several years.
+	for i := 0; i <= maxScheduleSearchDays; i++ {
+		candidate := atDay(startDate, i)
+		if candidate.Before(now) {
+			continue
+		}
+		if js.isValidExecutionDate(candidate, period, daysOfMonth) {
 			return candidate
 		}
-		candidate = candidate.Add(24 * time.Hour)
 	}
 
-	// Fallback
+	// No matching day left (e.g. EndDay has passed), the schedule is exhausted
 	log.Warn().Str("jobID", job.ID).Msg("Could not find valid execution date for job")
-	return now.Add(24 * time.Hour)
+	return time.Time{}
+}
+
+// wallClockTime returns the time of day clock on the given calendar day in loc.
+// A time skipped by a DST jump is moved forward by the size of the gap
+// (02:30 becomes 03:30) rather than before it.
+func wallClockTime(year int, month time.Month, day int, clock time.Time, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, loc)
+
+	if t.Hour() != clock.Hour() || t.Minute() != clock.Minute() {
+		// time.Date may resolve a skipped time to either side of the gap
+		// depending on the zone. Reading the wall time with the offset in
+		// effect before the jump always lands the size of the gap after it.
+		_, offsetBefore := time.Date(year, month, day-1, clock.Hour(), clock.Minute(), clock.Second(), 0, loc).Zone()
+		t = time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, time.UTC).
+			Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+	}
+
+	return t
+}
+
+// calculateNextCronRunTime calculates the next run time of a 'Cron' job
+func (js *JobService) calculateNextCronRunTime(job *Job, now time.Time) time.Time {
+	log := utility.GetLogger()
+
+	// An expression that cannot be parsed or never matches leaves the schedule
+	// exhausted rather than running at times the expression does not name
+	cron, err := ParseCron(job.Schedule.Cron)
+	if err != nil {
+		log.Error().Err(err).Str("jobID", job.ID).Msg("Error parsing cron expression")
+		return time.Time{}
+	}
+
+	nextRun := cron.Next(now)
+	if nextRun.IsZero() {
+		log.Warn().Str("jobID", job.ID).Str("cron", job.Schedule.Cron).Msg("Cron expression has no upcoming run time")
+		return time.Time{}
+	}
+
+	return nextRun
+}
+
+// calculateNextIntervalRunTime calculates the next run time of an 'Interval' job.
+// Runs are anchored at StartTime (default: one interval after creation) and
+// spaced by the interval, so a late run does not shift the following ones.
+// Runs missed while the service was down are skipped, not replayed.
+func (js *JobService) calculateNextIntervalRunTime(job *Job, now time.Time) time.Time {
+	log := utility.GetLogger()
+	schedule := job.Schedule
+
+	interval, err := time.ParseDuration(schedule.Interval)
+	if err != nil || interval < MinScheduleInterval {
+		log.Error().Err(err).Str("jobID", job.ID).Str("interval", schedule.Interval).Msg("Invalid schedule interval")
+		return now.Add(24 * time.Hour)
+	}
+
+	if schedule.MaxExecutions > 0 && job.ExecutionCount >= schedule.MaxExecutions {
+		return time.Time{}
+	}
+
+	var anchor time.Time
+	switch {
+	case schedule.StartTime != nil:
+		anchor = *schedule.StartTime
+	case !job.CreatedTime.IsZero():
+		anchor = job.CreatedTime.Add(interval)
+	default:
+		anchor = now.Add(interval)
+	}
+
+	nextRun := anchor
+	if !anchor.After(now) {
+		elapsed := now.Sub(anchor)
+		nextRun = anchor.Add((elapsed/interval + 1) * interval)
+	}
+
+	if schedule.EndTime != nil && nextRun.After(*schedule.EndTime) {
+		return time.Time{}
+	}
+
+	return nextRun
 }
 
-// isValidExecutionDate checks if a date matches the period criteria
-func (js *JobService) isValidExecutionDate(date time.Time, period *Period) bool {
+// isValidExecutionDate checks if a date matches the period criteria, with the
+// period's DaysOfMonth parsed by the caller (nil when unset).
+// StartDay and EndDay are calendar days in the date's time zone.
+func (js *JobService) isValidExecutionDate(date time.Time, period *Period, daysOfMonth *DaysOfMonthExpression) bool {
+	loc := date.Location()
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+
 	// Check if date is within the period range
 	if period.StartDay != nil {
-		if startDay, err := time.Parse("2006-01-02", *period.StartDay); err == nil {
-			if date.Before(startDay) {
+		if startDay, err := time.ParseInLocation("2006-01-02", *period.StartDay, loc); err == nil {
+			if day.Before(startDay) {
 				return false
 			}
 		}
 	}
 
 	if period.EndDay != nil {
-		if endDay, err := time.Parse("2006-01-02", *period.EndDay); err == nil {
-			// Add 23:59:59 to endDay to include the entire day
-			endDayEnd := endDay.Add(24*time.Hour - time.Second)
-			if date.After(endDayEnd) {
+		if endDay, err := time.ParseInLocation("2006-01-02", *period.EndDay, loc); err == nil {
+			// The entire EndDay is included
+			if day.After(endDay) {
 				return false
 			}
 		}
 	}
 
-	// Check DaysOfWeek
-	if len(period.DaysOfWeek) > 0 {
-		dayMatches := false
-		weekday := date.Weekday().String()
-		for _, day := range period.DaysOfWeek {
-			if string(day) == weekday {
-				dayMatches = true
-				break
-			}
-		}
-		if !dayMatches {
-			return false
-		}
-	}
-
-	// Check DaysOfMonth
-	if period.DaysOfMonth != nil && *period.DaysOfMonth != "" {
-		// Simple check: see if current day is in the list
-		// For simplicity, we'll just check if the day number matches
-		// A more robust implementation would parse the comma-separated list
-		dayOfMonth := date.Day()
-		dayStr := fmt.Sprintf("%d", dayOfMonth)
-		
-		// This is a simplified check - should be enhanced for comma-separated values
-		if *period.DaysOfMonth == dayStr {
-			return true
-		}
-		// If DaysOfMonth is specified but doesn't match, and DaysOfWeek is empty, return false
-		if len(period.DaysOfWeek) == 0 {
-			return false
-		}
-	}
-
-	return true
+	return periodDaysMatch(date, period.DaysOfWeek, daysOfMonth, period.DayMatch)
 }
 
 // logExecutionHistory logs execution history to a file
@@ -533,6 +1482,85 @@ func (js *JobService) logExecutionHistory(history *ExecutionHistory) {
 	log.Debug().Str("logFile", logFile).Msg("Execution history logged")
 }
 
+// GetExecutions returns the retained execution history of a job, oldest first
+func (js *JobService) GetExecutions(jobID string) ([]*ExecutionHistory, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	if _, exists := js.jobs[jobID]; !exists {
+		return nil, fmt.Errorf("job with ID '%s' not found in job service (active jobs: %d). Use GET /jobs to list available jobs", jobID, len(js.jobs))
+	}
+
+	executions := make([]*ExecutionHistory, len(js.executions[jobID]))
+	copy(executions, js.executions[jobID])
+
+	return executions, nil
+}
+
+// GetExecution returns a single execution of a job
+func (js *JobService) GetExecution(jobID, executionID string) (*ExecutionHistory, error) {
+	executions, err := js.GetExecutions(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, execution := range executions {
+		if execution.ID == executionID {
+			return execution, nil
+		}
+	}
+
+	return nil, fmt.Errorf("execution '%s' of job '%s' not found. It may not have run yet or was removed by the execution history retention limits", executionID, jobID)
+}
+
+// GetExecutionHistoryLimit returns the number of executions retained per job
+func (js *JobService) GetExecutionHistoryLimit() int {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+	return js.historyLimit
+}
+
+// SetExecutionHistoryRetention updates how many executions are retained per job
+// and for how long (maxAge 0 keeps executions regardless of age). Existing
+// histories are pruned immediately.
+func (js *JobService) SetExecutionHistoryRetention(limit int, maxAge time.Duration) error {
+	log := utility.GetLogger()
+
+	if limit <= 0 {
+		log.Warn().Int("executionHistoryLimit", limit).Msg("Invalid execution history limit")
+		return fmt.Errorf("job service configuration failed: execution history limit must be greater than 0, got %d. Configure execution_history_limit in config file", limit)
+	}
+	if maxAge < 0 {
+		log.Warn().Dur("executionHistoryMaxAge", maxAge).Msg("Invalid execution history max age")
+		return fmt.Errorf("job service configuration failed: execution history max age must not be negative, got %s. Configure execution_history_max_age_days in config file", maxAge)
+	}
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.historyLimit = limit
+	js.historyMaxAge = maxAge
+
+	for jobID, executions := range js.executions {
+		pruned := js.pruneExecutions(executions)
+		if len(pruned) == len(executions) {
+			continue
+		}
+		js.executions[jobID] = pruned
+		if js.store != nil {
+			if err := js.store.SaveExecutions(jobID, pruned); err != nil {
+				log.Error().Err(err).Str("jobID", jobID).Msg("Failed to persist execution history")
+			}
+		}
+	}
+
+	log.Info().
+		Int("executionHistoryLimit", limit).
+		Dur("executionHistoryMaxAge", maxAge).
+		Msg("Execution history retention updated")
+	return nil
+}
+
 // GetWorkerPoolSize returns the maximum number of jobs allowed
 func (js *JobService) GetWorkerPoolSize() int {
 	js.mu.RLock()
@@ -569,9 +1597,54 @@ func (js *JobService) SetWorkerPoolSize(size int) error {
 		Int("poolSize", size).
 		Int("activeWorkers", len(js.workerPool)).
 		Msg("Worker pool size updated")
+
+	// A larger pool can take queued runs right away
+	js.dispatchQueue(js.clock.Now())
 	return nil
 }
 
+// GetMisfirePolicy returns the service misfire policy and threshold
+func (js *JobService) GetMisfirePolicy() (MisfirePolicy, time.Duration) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+	return js.misfirePolicy, js.misfireThreshold
+}
+
+// SetMisfirePolicy updates what happens to scheduled runs that waited in the
+// run queue for longer than threshold. Jobs with their own MisfirePolicy keep
+// it; a threshold of 0 disables misfire handling.
+func (js *JobService) SetMisfirePolicy(policy MisfirePolicy, threshold time.Duration) error {
+	log := utility.GetLogger()
+
+	if err := ValidateMisfirePolicy(policy); err != nil {
+		log.Warn().Str("misfirePolicy", string(policy)).Msg("Invalid misfire policy")
+		return fmt.Errorf("job service configuration failed: %v. Configure misfire_policy in config file", err)
+	}
+	if threshold < 0 {
+		log.Warn().Dur("misfireThreshold", threshold).Msg("Invalid misfire threshold")
+		return fmt.Errorf("job service configuration failed: misfire threshold must not be negative, got %s. Configure misfire_threshold_seconds in config file", threshold)
+	}
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.misfirePolicy = policy
+	js.misfireThreshold = threshold
+
+	log.Info().
+		Str("misfirePolicy", string(policy)).
+		Dur("misfireThreshold", threshold).
+		Msg("Misfire policy updated")
+	return nil
+}
+
+// GetQueueStats returns the depth and wait times of the run queue
+func (js *JobService) GetQueueStats() QueueStats {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+	return js.queue.stats(js.clock.Now())
+}
+
 // SetLogsDir updates the logs directory path
 func SetLogsDir(dir string) error {
 	log := utility.GetLogger()
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForExecutions waits until the job's history holds count executions
+func waitForExecutions(t *testing.T, service *JobService, jobID string, count int) []*ExecutionHistory {
+	var executions []*ExecutionHistory
+	require.Eventually(t, func() bool {
+		var err error
+		executions, err = service.GetExecutions(jobID)
+		return err == nil && len(executions) == count
+	}, 5*time.Second, 10*time.Millisecond)
+	return executions
+}
+
+func TestJobService_PauseAndResume(t *testing.T) {
+	executor := &MockJobExecutor{}
+	service := NewJobService(&MockJobValidator{}, executor)
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	require.NoError(t, service.PauseJob(job.ID))
+	assert.Equal(t, JobStatusPaused, job.Status)
+	assert.Nil(t, job.NextRunTime)
+
+	// Pausing twice is a no-op
+	require.NoError(t, service.PauseJob(job.ID))
+
+	// Paused jobs are not executed when due
+	makeJobDue(service, job)
+	service.checkAndExecuteJobs()
+	time.Sleep(50 * time.Millisecond)
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	assert.Empty(t, executions)
+
+	require.NoError(t, service.ResumeJob(job.ID))
+	service.mu.RLock()
+	assert.Equal(t, JobStatusPending, job.Status)
+	require.NotNil(t, job.NextRunTime)
+	assert.True(t, job.NextRunTime.After(time.Now()))
+	service.mu.RUnlock()
+
+	// Resuming a job that is not paused is a no-op
+	require.NoError(t, service.ResumeJob(job.ID))
+
+	assert.ErrorIs(t, service.PauseJob("missing"), ErrJobNotFound)
+	assert.ErrorIs(t, service.ResumeJob("missing"), ErrJobNotFound)
+}
+
+func TestJobService_PauseRequiresScheduledJob(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	require.NoError(t, service.CancelJob(job.ID))
+
+	assert.ErrorIs(t, service.PauseJob(job.ID), ErrInvalidJobState)
+	assert.ErrorIs(t, service.ResumeJob(job.ID), ErrInvalidJobState)
+	assert.ErrorIs(t, service.RunJob(job.ID), ErrInvalidJobState)
+}
+
+func TestJobService_PauseWhileRunning(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	executor := &MockJobExecutor{}
+	executor.ExecuteJobFunc = func(job *Job) *ExecutionHistory {
+		close(started)
+		<-release
+		return &ExecutionHistory{
+			JobID:   job.ID,
+			Results: []MachineExecutionResult{{MachineID: job.Machines[0], Success: true}},
+		}
+	}
+	service := NewJobService(&MockJobValidator{}, executor)
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		runJobOnce(service, job)
+		close(done)
+	}()
+	<-started
+
+	// Running jobs cannot be run again but can be paused
+	assert.ErrorIs(t, service.RunJob(job.ID), ErrJobRunning)
+	require.NoError(t, service.PauseJob(job.ID))
+
+	close(release)
+	<-done
+
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+	assert.Equal(t, JobStatusPaused, job.Status)
+	assert.Nil(t, job.NextRunTime)
+	assert.Equal(t, 1, job.ExecutionCount)
+}
+
+func TestJobService_RunJob(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	nextRun := *job.NextRunTime
+
+	require.NoError(t, service.RunJob(job.ID))
+	executions := waitForExecutions(t, service, job.ID, 1)
+	assert.True(t, executions[0].Manual)
+	assert.Equal(t, JobStatusCompleted, executions[0].Status)
+
+	// The schedule is left alone
+	require.Eventually(t, func() bool {
+		service.runningMu.Lock()
+		defer service.runningMu.Unlock()
+		return !service.runningJobs[job.ID]
+	}, 5*time.Second, 10*time.Millisecond)
+
+	service.mu.RLock()
+	assert.Equal(t, JobStatusPending, job.Status)
+	assert.Equal(t, 0, job.ExecutionCount)
+	require.NotNil(t, job.LastRunTime)
+	assert.True(t, nextRun.Equal(*job.NextRunTime))
+	service.mu.RUnlock()
+
+	// Paused jobs can be run manually and stay paused
+	require.NoError(t, service.PauseJob(job.ID))
+	require.NoError(t, service.RunJob(job.ID))
+	waitForExecutions(t, service, job.ID, 2)
+	require.Eventually(t, func() bool {
+		service.mu.RLock()
+		defer service.mu.RUnlock()
+		return job.Status == JobStatusPaused
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.ErrorIs(t, service.RunJob("missing"), ErrJobNotFound)
+}
+
+func TestJobService_RunJobQueuedWhenPoolFull(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+	require.NoError(t, service.SetWorkerPoolSize(1))
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	// With every worker busy the run is queued, not rejected
+	service.workerPool <- struct{}{}
+	require.NoError(t, service.RunJob(job.ID))
+	assert.Equal(t, 1, service.GetQueueStats().Depth)
+	assert.ErrorIs(t, service.RunJob(job.ID), ErrJobRunning)
+
+	// The queued run starts once a worker is released
+	<-service.workerPool
+	service.mu.Lock()
+	service.dispatchQueue(time.Now())
+	service.mu.Unlock()
+
+	executions := waitForExecutions(t, service, job.ID, 1)
+	assert.True(t, executions[0].Manual)
+}
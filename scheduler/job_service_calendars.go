@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"multifish/utility"
+)
+
+var (
+	// ErrCalendarExists is returned when a calendar ID is already taken
+	ErrCalendarExists = errors.New("calendar already exists")
+	// ErrCalendarInUse is returned when deleting a calendar that jobs still reference
+	ErrCalendarInUse = errors.New("calendar is referenced by jobs")
+)
+
+// CreateCalendar validates and stores a blackout calendar. An empty ID is generated.
+// The returned slice lists validation problems; it is empty when only err is set.
+func (js *JobService) CreateCalendar(calendar *BlackoutCalendar) ([]string, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if errs := calendar.Validate(); len(errs) > 0 {
+		return errs, fmt.Errorf("calendar validation failed")
+	}
+
+	if calendar.ID == "" {
+		calendar.ID = fmt.Sprintf("Calendar-%d", time.Now().UnixNano())
+	}
+	if _, exists := js.calendars[calendar.ID]; exists {
+		return nil, fmt.Errorf("%w: '%s'. Choose another Id or delete the existing calendar first", ErrCalendarExists, calendar.ID)
+	}
+	calendar.CreatedTime = js.clock.Now()
+
+	if js.store != nil {
+		if err := js.store.SaveCalendar(calendar); err != nil {
+			return nil, err
+		}
+	}
+
+	js.calendars[calendar.ID] = calendar
+
+	log := utility.GetLogger()
+	log.Info().
+		Str("calendarID", calendar.ID).
+		Bool("global", calendar.Global).
+		Int("windows", len(calendar.Windows)).
+		Msg("Blackout calendar created")
+
+	return nil, nil
+}
+
+// GetCalendar retrieves a blackout calendar by ID
+func (js *JobService) GetCalendar(calendarID string) (*BlackoutCalendar, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	calendar, exists := js.calendars[calendarID]
+	if !exists {
+		return nil, fmt.Errorf("calendar not found: %s", calendarID)
+	}
+
+	return calendar, nil
+}
+
+// ListCalendars returns all blackout calendars ordered by creation time
+func (js *JobService) ListCalendars() []*BlackoutCalendar {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	return js.sortedCalendars()
+}
+
+// DeleteCalendar deletes a blackout calendar that no job references
+func (js *JobService) DeleteCalendar(calendarID string) error {
+	log := utility.GetLogger()
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if _, exists := js.calendars[calendarID]; !exists {
+		return fmt.Errorf("calendar not found: %s", calendarID)
+	}
+
+	var referencedBy []string
+	for _, job := range js.jobs {
+		for _, id := range job.Calendars {
+			if id == calendarID {
+				referencedBy = append(referencedBy, job.ID)
+				break
+			}
+		}
+	}
+	if len(referencedBy) > 0 {
+		sort.Strings(referencedBy)
+		return fmt.Errorf("%w: '%s' is used by %s. Delete those jobs first", ErrCalendarInUse, calendarID, strings.Join(referencedBy, ", "))
+	}
+
+	if js.store != nil {
+		if err := js.store.DeleteCalendar(calendarID); err != nil {
+			log.Error().Err(err).Str("calendarID", calendarID).Msg("Failed to delete persisted calendar")
+			return err
+		}
+	}
+
+	delete(js.calendars, calendarID)
+	log.Info().Str("calendarID", calendarID).Msg("Blackout calendar deleted")
+
+	return nil
+}
+
+// loadCalendars restores persisted blackout calendars (caller holds js.mu)
+func (js *JobService) loadCalendars() {
+	log := utility.GetLogger()
+
+	calendars, err := js.store.LoadCalendars()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load persisted calendars")
+		return
+	}
+
+	for _, calendar := range calendars {
+		js.calendars[calendar.ID] = calendar
+	}
+
+	log.Info().Int("calendars", len(calendars)).Msg("Restored persisted calendars")
+}
+
+// sortedCalendars returns all calendars ordered by creation time (caller holds js.mu)
+func (js *JobService) sortedCalendars() []*BlackoutCalendar {
+	calendars := make([]*BlackoutCalendar, 0, len(js.calendars))
+	for _, calendar := range js.calendars {
+		calendars = append(calendars, calendar)
+	}
+	sort.Slice(calendars, func(i, j int) bool {
+		if calendars[i].CreatedTime.Equal(calendars[j].CreatedTime) {
+			return calendars[i].ID < calendars[j].ID
+		}
+		return calendars[i].CreatedTime.Before(calendars[j].CreatedTime)
+	})
+	return calendars
+}
+
+// validateCalendarReferences returns an error for each referenced calendar
+// that does not exist (caller holds js.mu)
+func (js *JobService) validateCalendarReferences(calendarIDs []string) []string {
+	var errors []string
+	for _, calendarID := range calendarIDs {
+		if _, exists := js.calendars[calendarID]; !exists {
+			errors = append(errors, fmt.Sprintf("calendar '%s' not found. Create it under /MultiFish/v1/JobService/Calendars first", calendarID))
+		}
+	}
+	return errors
+}
+
+// jobCalendars returns the calendars that apply to a job: the ones it
+// references followed by the global ones (caller holds js.mu)
+func (js *JobService) jobCalendars(job *Job) []*BlackoutCalendar {
+	var calendars []*BlackoutCalendar
+	referenced := make(map[string]bool)
+
+	for _, calendarID := range job.Calendars {
+		if calendar, exists := js.calendars[calendarID]; exists {
+			calendars = append(calendars, calendar)
+			referenced[calendarID] = true
+		}
+	}
+
+	for _, calendar := range js.sortedCalendars() {
+		if calendar.Global && !referenced[calendar.ID] {
+			calendars = append(calendars, calendar)
+		}
+	}
+
+	return calendars
+}
+
+// activeBlackout reports whether a job is blacked out at t. It returns the
+// calendar that blocks it and when the blackout ends, following windows that
+// start exactly when the previous one ends (caller holds js.mu).
+func (js *JobService) activeBlackout(job *Job, t time.Time) (string, time.Time, bool) {
+	calendars := js.jobCalendars(job)
+
+	blockedAt := func(at time.Time) (string, time.Time) {
+		var calendarID string
+		var until time.Time
+		for _, calendar := range calendars {
+			if end, blocked := calendar.BlackoutAt(at); blocked && end.After(until) {
+				if calendarID == "" {
+					calendarID = calendar.ID
+				}
+				until = end
+			}
+		}
+		return calendarID, until
+	}
+
+	calendarID, until := blockedAt(t)
+	if calendarID == "" {
+		return "", time.Time{}, false
+	}
+
+	for i := 0; i < maxBlackoutChain; i++ {
+		_, next := blockedAt(until)
+		if next.IsZero() {
+			break
+		}
+		until = next
+	}
+
+	return calendarID, until, true
+}
+
+// applyBlackout skips or defers a due execution according to the job's
+// BlackoutPolicy and records the decision in the execution history (caller holds js.mu)
+func (js *JobService) applyBlackout(job *Job, now time.Time, calendarID string, until time.Time) {
+	log := utility.GetLogger()
+
+	history := &ExecutionHistory{
+		ExecutionTime: now,
+		Results:       []MachineExecutionResult{},
+	}
+
+	if job.BlackoutPolicy == BlackoutPolicyDefer {
+		history.Status = JobStatusDeferred
+		history.Message = fmt.Sprintf("Blackout calendar '%s' is active, execution deferred until %s",
+			calendarID, until.Format(time.RFC3339))
+		job.NextRunTime = &until
+		js.schedule.set(job)
+	} else {
+		history.Status = JobStatusSkipped
+		history.Message = fmt.Sprintf("Blackout calendar '%s' is active until %s, execution skipped",
+			calendarID, until.Format(time.RFC3339))
+
+		js.skipToNextRun(job, now)
+	}
+
+	js.recordExecution(job, history)
+	js.persistJobOrWarn(job)
+
+	log.Info().
+		Str("jobID", job.ID).
+		Str("calendarID", calendarID).
+		Str("status", string(history.Status)).
+		Time("blackoutUntil", until).
+		Msg("Job execution blocked by blackout calendar")
+}
@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingActionExecutor blocks PatchProfile until its context is done
+type blockingActionExecutor struct {
+	MockActionExecutor
+	started chan struct{}
+}
+
+func newBlockingActionExecutor() *blockingActionExecutor {
+	return &blockingActionExecutor{started: make(chan struct{}, 1)}
+}
+
+func (b *blockingActionExecutor) ExecutePatchProfile(ctx context.Context, machine interface{}, managerPayloads Payload) error {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestJobService_CancelAbortsRunningExecution(t *testing.T) {
+	actionExecutor := newBlockingActionExecutor()
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actionExecutor)
+	service := NewJobService(&MockJobValidator{}, executor)
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.RetryPolicy = &RetryPolicy{MaxAttempts: 3, RetryOn: []ErrorClass{ErrorClassOther, ErrorClassTimeout}}
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		runJobOnce(service, job)
+		close(done)
+	}()
+
+	select {
+	case <-actionExecutor.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("execution did not start")
+	}
+
+	require.NoError(t, service.CancelJob(job.ID))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled execution did not return")
+	}
+
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, JobStatusCancelled, executions[0].Status)
+	require.Len(t, executions[0].Results, 1)
+	assert.Equal(t, JobStatusCancelled, executions[0].Results[0].Status)
+	// Cancellation is not retried
+	assert.Len(t, executions[0].Results[0].Attempts, 1)
+
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+	assert.Equal(t, JobStatusCancelled, job.Status)
+	assert.Nil(t, job.NextRunTime)
+}
+
+func TestJobService_TimeoutAbortsExecution(t *testing.T) {
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, newBlockingActionExecutor())
+	service := NewJobService(&MockJobValidator{}, executor)
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Timeout = "1s"
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+	assert.Equal(t, "1s", job.Timeout)
+
+	start := time.Now()
+	runJobOnce(service, job)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, JobStatusTimedOut, executions[0].Status)
+	require.Len(t, executions[0].Results, 1)
+	result := executions[0].Results[0]
+	assert.False(t, result.Success)
+	assert.Equal(t, JobStatusTimedOut, result.Status)
+	assert.Contains(t, result.Message, "timeout")
+
+	// A timed out run does not stop a continuous job
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+	assert.NotEqual(t, JobStatusCancelled, job.Status)
+	assert.NotNil(t, job.NextRunTime)
+}
+
+func TestJobCreateRequest_ValidateTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       string
+		expectedValid bool
+	}{
+		{"No timeout", "", true},
+		{"Minutes", "10m", true},
+		{"Minimum", "1s", true},
+		{"Too short", "500ms", false},
+		{"Negative", "-1m", false},
+		{"Not a duration", "ten minutes", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newTestJobRequest()
+			request.Timeout = tt.timeout
+			result := request.Validate()
+			assert.Equal(t, tt.expectedValid, result.Valid, "errors: %v", result.ActionErrors)
+			assert.Equal(t, tt.expectedValid, result.ActionValid)
+		})
+	}
+}
+
+func TestExecutionStatus(t *testing.T) {
+	completed := MachineExecutionResult{Success: true, Status: JobStatusCompleted}
+	failed := MachineExecutionResult{Status: JobStatusFailed}
+	timedOut := MachineExecutionResult{Status: JobStatusTimedOut}
+	cancelled := MachineExecutionResult{Status: JobStatusCancelled}
+
+	assert.Equal(t, JobStatusCompleted, executionStatus([]MachineExecutionResult{completed, completed}))
+	assert.Equal(t, JobStatusFailed, executionStatus([]MachineExecutionResult{completed, failed}))
+	assert.Equal(t, JobStatusTimedOut, executionStatus([]MachineExecutionResult{timedOut, failed}))
+	assert.Equal(t, JobStatusTimedOut, executionStatus([]MachineExecutionResult{failed, timedOut}))
+	assert.Equal(t, JobStatusCancelled, executionStatus([]MachineExecutionResult{timedOut, cancelled}))
+}
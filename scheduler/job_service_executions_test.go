@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runJobOnce executes a job synchronously the way the scheduler would
+func runJobOnce(service *JobService, job *Job) {
+	service.workerPool <- struct{}{}
+	service.executeJobAsync(job, false)
+}
+
+// TestJobService_RecordsExecutions tests that every run is kept in the job's execution history
+func TestJobService_RecordsExecutions(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	assert.Empty(t, executions)
+
+	runJobOnce(service, job)
+	runJobOnce(service, job)
+
+	executions, err = service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+	assert.Equal(t, "1", executions[0].ID)
+	assert.Equal(t, "2", executions[1].ID)
+	assert.Equal(t, job.ID, executions[1].JobID)
+	assert.Equal(t, JobStatusCompleted, executions[1].Status)
+	assert.Len(t, executions[1].Results, 1)
+
+	execution, err := service.GetExecution(job.ID, "2")
+	require.NoError(t, err)
+	assert.Equal(t, "2", execution.ID)
+
+	_, err = service.GetExecution(job.ID, "99")
+	assert.Error(t, err)
+
+	_, err = service.GetExecutions("non-existent")
+	assert.Error(t, err)
+
+	// Deleting the job drops its history
+	require.NoError(t, service.DeleteJob(job.ID))
+	_, err = service.GetExecutions(job.ID)
+	assert.Error(t, err)
+}
+
+// TestJobService_ExecutionHistoryRetention tests the count and age retention limits
+func TestJobService_ExecutionHistoryRetention(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	assert.Equal(t, DefaultExecutionHistoryLimit, service.GetExecutionHistoryLimit())
+	assert.Error(t, service.SetExecutionHistoryRetention(0, 0))
+	assert.Error(t, service.SetExecutionHistoryRetention(10, -time.Hour))
+
+	require.NoError(t, service.SetExecutionHistoryRetention(2, 0))
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		runJobOnce(service, job)
+	}
+
+	// Only the newest executions are kept
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+	assert.Equal(t, "2", executions[0].ID)
+	assert.Equal(t, "3", executions[1].ID)
+
+	// Executions older than the max age are pruned when retention changes
+	service.mu.Lock()
+	service.executions[job.ID][0].ExecutionTime = time.Now().Add(-48 * time.Hour)
+	service.mu.Unlock()
+
+	require.NoError(t, service.SetExecutionHistoryRetention(10, 24*time.Hour))
+
+	executions, err = service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "3", executions[0].ID)
+}
+
+// TestJobService_PersistsExecutions tests that execution history survives a restart
+func TestJobService_PersistsExecutions(t *testing.T) {
+	store := newTestJobStore(t)
+
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	runJobOnce(service, job)
+	service.Stop()
+
+	restarted := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	defer restarted.Stop()
+
+	executions, err := restarted.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "1", executions[0].ID)
+	assert.Equal(t, "machine1", executions[0].Results[0].MachineID)
+
+	// Deleting the job removes its persisted history
+	require.NoError(t, restarted.DeleteJob(job.ID))
+	persisted, err := store.LoadExecutions(job.ID)
+	require.NoError(t, err)
+	assert.Empty(t, persisted)
+}
+
+// TestJobService_ExecutionHistoryRetentionUsesServiceClock tests that the max
+// age is measured from the service clock
+func TestJobService_ExecutionHistoryRetentionUsesServiceClock(t *testing.T) {
+	clock := &testClock{now: time.Date(2020, 1, 6, 7, 0, 0, 0, time.UTC)}
+	service := NewJobServiceWithClock(&MockJobValidator{}, &MockJobExecutor{}, nil, clock)
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	runJobOnce(service, job)
+	runJobOnce(service, job)
+
+	service.mu.Lock()
+	service.executions[job.ID][0].ExecutionTime = clock.Now().Add(-48 * time.Hour)
+	service.executions[job.ID][1].ExecutionTime = clock.Now().Add(-time.Hour)
+	service.mu.Unlock()
+
+	require.NoError(t, service.SetExecutionHistoryRetention(10, 24*time.Hour))
+
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "2", executions[0].ID)
+}
+
+// TestJobService_ExecutionTimeUsesServiceClock tests that executions are timed
+// by the service clock
+func TestJobService_ExecutionTimeUsesServiceClock(t *testing.T) {
+	clock := &testClock{now: time.Date(2020, 1, 6, 7, 0, 0, 0, time.UTC)}
+	service := NewJobServiceWithClock(&MockJobValidator{}, &MockJobExecutor{}, nil, clock)
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	runJobOnce(service, job)
+
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.True(t, clock.Now().Equal(executions[0].ExecutionTime))
+}
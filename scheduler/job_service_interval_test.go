@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobCreateRequestValidate_Interval(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	later := future.Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		schedule      Schedule
+		expectedValid bool
+	}{
+		{"Valid interval", Schedule{Type: ScheduleTypeInterval, Interval: "15m"}, true},
+		{"Valid bounded interval", Schedule{Type: ScheduleTypeInterval, Interval: "30s", StartTime: &future, EndTime: &later, MaxExecutions: 10}, true},
+		{"Missing interval", Schedule{Type: ScheduleTypeInterval}, false},
+		{"Unparsable interval", Schedule{Type: ScheduleTypeInterval, Interval: "fortnightly"}, false},
+		{"Interval too short", Schedule{Type: ScheduleTypeInterval, Interval: "500ms"}, false},
+		{"Start after end", Schedule{Type: ScheduleTypeInterval, Interval: "1m", StartTime: &later, EndTime: &future}, false},
+		{"End in the past", Schedule{Type: ScheduleTypeInterval, Interval: "1m", EndTime: &past}, false},
+		{"Negative max executions", Schedule{Type: ScheduleTypeInterval, Interval: "1m", MaxExecutions: -1}, false},
+		{"Time not allowed", Schedule{Type: ScheduleTypeInterval, Interval: "1m", Time: "08:00:00"}, false},
+		{"Interval on continuous schedule", Schedule{Type: ScheduleTypeContinuous, Time: "08:00:00", Interval: "1m", Period: &Period{DaysOfWeek: []DayOfWeek{Monday}}}, false},
+		{"MaxExecutions on once schedule", Schedule{Type: ScheduleTypeOnce, Time: "08:00:00", MaxExecutions: 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newTestJobRequest()
+			request.Schedule = tt.schedule
+
+			result := request.Validate()
+			assert.Equal(t, tt.expectedValid, result.Valid, "errors: %v", result.ScheduleErrors)
+		})
+	}
+}
+
+func TestJobService_CalculateNextIntervalRunTime(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	now := time.Date(2026, 2, 9, 10, 0, 0, 0, time.UTC)
+	start := now.Add(-25 * time.Minute)
+	futureStart := now.Add(2 * time.Hour)
+	end := now.Add(20 * time.Minute)
+
+	tests := []struct {
+		name     string
+		job      *Job
+		expected time.Time
+	}{
+		{
+			name:     "First run one interval after creation",
+			job:      &Job{CreatedTime: now, Schedule: Schedule{Type: ScheduleTypeInterval, Interval: "10m"}},
+			expected: now.Add(10 * time.Minute),
+		},
+		{
+			name:     "Future start time",
+			job:      &Job{CreatedTime: now, Schedule: Schedule{Type: ScheduleTypeInterval, Interval: "10m", StartTime: &futureStart}},
+			expected: futureStart,
+		},
+		{
+			name:     "Anchored to start time",
+			job:      &Job{CreatedTime: start, Schedule: Schedule{Type: ScheduleTypeInterval, Interval: "10m", StartTime: &start}},
+			expected: start.Add(30 * time.Minute),
+		},
+		{
+			name:     "Next run after end time",
+			job:      &Job{CreatedTime: start, Schedule: Schedule{Type: ScheduleTypeInterval, Interval: "1h", StartTime: &start, EndTime: &end}},
+			expected: time.Time{},
+		},
+		{
+			name:     "Max executions reached",
+			job:      &Job{CreatedTime: now, ExecutionCount: 3, Schedule: Schedule{Type: ScheduleTypeInterval, Interval: "10m", MaxExecutions: 3}},
+			expected: time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.calculateNextIntervalRunTime(tt.job, now))
+		})
+	}
+}
+
+// TestJobService_IntervalMaxExecutions tests that an interval job stops after MaxExecutions runs
+func TestJobService_IntervalMaxExecutions(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Schedule = Schedule{Type: ScheduleTypeInterval, Interval: "1h", MaxExecutions: 2}
+
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+	require.NotNil(t, job.NextRunTime)
+
+	runJobOnce(service, job)
+	assert.Equal(t, JobStatusPending, job.Status)
+	require.NotNil(t, job.NextRunTime)
+
+	runJobOnce(service, job)
+	assert.Equal(t, JobStatusCompleted, job.Status)
+	assert.Nil(t, job.NextRunTime)
+	assert.Equal(t, 2, job.ExecutionCount)
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobCreateRequestValidate_Once(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		schedule      Schedule
+		expectedValid bool
+	}{
+		{"Time of day", Schedule{Type: ScheduleTypeOnce, Time: "08:00:00"}, true},
+		{"Absolute start time", Schedule{Type: ScheduleTypeOnce, StartTime: &future}, true},
+		{"Immediate", Schedule{Type: ScheduleTypeOnce, Immediate: true}, true},
+		{"Start time in the past", Schedule{Type: ScheduleTypeOnce, StartTime: &past}, false},
+		{"No run time", Schedule{Type: ScheduleTypeOnce}, false},
+		{"Time and start time", Schedule{Type: ScheduleTypeOnce, Time: "08:00:00", StartTime: &future}, false},
+		{"Immediate and start time", Schedule{Type: ScheduleTypeOnce, Immediate: true, StartTime: &future}, false},
+		{"Period not allowed", Schedule{Type: ScheduleTypeOnce, Immediate: true, Period: &Period{}}, false},
+		{"Immediate on continuous schedule", Schedule{Type: ScheduleTypeContinuous, Time: "08:00:00", Immediate: true, Period: &Period{DaysOfWeek: []DayOfWeek{Monday}}}, false},
+		{"Start time on cron schedule", Schedule{Type: ScheduleTypeCron, Cron: "0 8 * * *", StartTime: &future}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newTestJobRequest()
+			request.Schedule = tt.schedule
+
+			result := request.Validate()
+			assert.Equal(t, tt.expectedValid, result.Valid, "errors: %v", result.ScheduleErrors)
+		})
+	}
+}
+
+func TestJobService_CreateJob_OnceStartTime(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	startTime := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Second)
+
+	request := newTestJobRequest()
+	request.Schedule = Schedule{Type: ScheduleTypeOnce, StartTime: &startTime, TimeZone: "UTC"}
+
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+	require.NotNil(t, job.NextRunTime)
+	assert.True(t, startTime.Equal(*job.NextRunTime))
+	assert.Equal(t, "UTC", job.NextRunTime.Location().String())
+
+	// A start time in the past is rejected
+	past := time.Now().Add(-time.Hour)
+	request.Schedule.StartTime = &past
+	_, validation, err := service.CreateJob(request)
+	assert.Error(t, err)
+	assert.False(t, validation.ScheduleValid)
+}
+
+func TestJobService_CreateJob_Immediate(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Schedule = Schedule{Type: ScheduleTypeOnce, Immediate: true}
+
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		executions, err := service.GetExecutions(job.ID)
+		return err == nil && len(executions) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The job runs only once
+	current, err := service.GetJob(job.ID)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		service.mu.RLock()
+		defer service.mu.RUnlock()
+		return current.Status == JobStatusCompleted && current.NextRunTime == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestJobService_CreateJob_ImmediateFrozenClock tests that an Immediate job runs
+// even when the clock has not moved past its NextRunTime
+func TestJobService_CreateJob_ImmediateFrozenClock(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)}
+	service := NewJobServiceWithClock(&MockJobValidator{}, &MockJobExecutor{}, nil, clock)
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Schedule = Schedule{Type: ScheduleTypeOnce, Immediate: true}
+
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		executions, err := service.GetExecutions(job.ID)
+		return err == nil && len(executions) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"multifish/utility"
+)
+
+// revertRetryDelay is the wait before restoring the machines a revert failed on again
+const revertRetryDelay = time.Minute
+
+// revertTime returns when the changes of a run that finished at finished are
+// restored: RevertAfter later, or at the next RevertAfter time of day in the
+// schedule's time zone
+func (js *JobService) revertTime(job *Job, finished time.Time) time.Time {
+	log := utility.GetLogger()
+
+	duration, clock, err := ParseRevertAfter(job.RevertAfter)
+	if err != nil {
+		log.Error().Err(err).Str("jobID", job.ID).Msg("Invalid RevertAfter, reverting immediately")
+		return finished
+	}
+	if duration > 0 {
+		return finished.Add(duration)
+	}
+
+	loc, err := job.Schedule.Location()
+	if err != nil {
+		loc = time.Local
+	}
+	local := finished.In(loc)
+	revertAt := wallClockTime(local.Year(), local.Month(), local.Day(), clock, loc)
+	if !revertAt.After(finished) {
+		revertAt = wallClockTime(local.Year(), local.Month(), local.Day()+1, clock, loc)
+	}
+	return revertAt
+}
+
+// scheduleRevert keeps the values captured by a run of a RevertAfter job until
+// its revert time. If an earlier revert is still pending, the values it holds
+// for a machine are kept, so the machine returns to its state before the first
+// run, and the revert moves to the new revert time (caller holds js.mu).
+func (js *JobService) scheduleRevert(job *Job, history *ExecutionHistory, finished time.Time) {
+	if job.RevertAfter == "" {
+		return
+	}
+
+	pending := job.PendingRevert
+	if pending == nil {
+		pending = &PendingRevert{}
+	}
+
+	captured := make(map[string]bool, len(pending.Machines))
+	for _, machine := range pending.Machines {
+		captured[machine.MachineID] = true
+	}
+
+	changed := false
+	for _, result := range history.Results {
+		if len(result.Revert) == 0 {
+			continue
+		}
+		changed = true
+		if !captured[result.MachineID] {
+			pending.Machines = append(pending.Machines, MachineRevert{MachineID: result.MachineID, Steps: result.Revert})
+		}
+	}
+	if !changed {
+		return
+	}
+
+	pending.Executions = append(pending.Executions, history.ID)
+	pending.RevertTime = js.revertTime(job, finished)
+	job.PendingRevert = pending
+	js.reverts.setAt(job, &pending.RevertTime)
+
+	log := utility.GetLogger()
+	log.Info().
+		Str("jobID", job.ID).
+		Int("machines", len(pending.Machines)).
+		Time("revertTime", pending.RevertTime).
+		Msg("Revert scheduled")
+}
+
+// startDueReverts starts restoring the captured values of the jobs whose
+// revert time has passed. Reverts do not wait for a worker, so a busy pool
+// cannot hold machines in the changed state; paused and cancelled jobs are
+// reverted too. The PendingRevert is kept until the machines are restored
+// (caller holds js.mu).
+func (js *JobService) startDueReverts(now time.Time) {
+	for _, entry := range js.reverts.popDue(now) {
+		job := entry.job
+
+		revert := job.PendingRevert
+		if current, exists := js.jobs[job.ID]; !exists || current != job || revert == nil {
+			continue
+		}
+
+		// Moved by a later run since the entry was indexed
+		if !revert.RevertTime.Equal(entry.due) {
+			js.reverts.setAt(job, &revert.RevertTime)
+			continue
+		}
+
+		// The running revert indexes the job again when it finishes
+		if js.reverting[job.ID] {
+			continue
+		}
+		js.reverting[job.ID] = true
+
+		runs := make([]*Job, len(revert.Machines))
+		for i, machine := range revert.Machines {
+			runs[i] = &Job{
+				ID:              job.ID,
+				Name:            job.Name,
+				Machines:        []string{machine.MachineID},
+				Steps:           machine.Steps,
+				RetryPolicy:     job.RetryPolicy,
+				Verify:          job.Verify,
+				VerifyTolerance: job.VerifyTolerance,
+				LockPolicy:      job.LockPolicy,
+			}
+		}
+
+		ctx, cancel := js.executionContext(job)
+		go js.executeRevert(ctx, cancel, job, append([]string(nil), revert.Executions...), runs)
+	}
+}
+
+// finishRevert drops the machines that were restored from the job's
+// PendingRevert. Machines that failed stay pending and are retried after
+// revertRetryDelay. If the job ran again while reverting, every machine stays
+// pending for the new revert time, as the run may have changed them again
+// (caller holds js.mu).
+func (js *JobService) finishRevert(job *Job, executions []string, results []MachineExecutionResult) {
+	pending := job.PendingRevert
+	if pending == nil {
+		return
+	}
+
+	if len(pending.Executions) == len(executions) {
+		restored := make(map[string]bool, len(results))
+		for _, result := range results {
+			restored[result.MachineID] = result.Success
+		}
+
+		remaining := make([]MachineRevert, 0, len(pending.Machines))
+		for _, machine := range pending.Machines {
+			if !restored[machine.MachineID] {
+				remaining = append(remaining, machine)
+			}
+		}
+		if len(remaining) == 0 {
+			job.PendingRevert = nil
+			return
+		}
+
+		pending.Machines = remaining
+		pending.RevertTime = js.clock.Now().Add(revertRetryDelay)
+
+		log := utility.GetLogger()
+		log.Warn().
+			Str("jobID", job.ID).
+			Int("machines", len(remaining)).
+			Time("retryTime", pending.RevertTime).
+			Msg("Revert failed on some machines, retrying")
+	}
+
+	js.reverts.setAt(job, &pending.RevertTime)
+}
+
+// executeRevert restores the captured values on every machine in parallel and
+// records the result in the job's execution history
+func (js *JobService) executeRevert(ctx context.Context, cancel context.CancelFunc, job *Job, executions []string, runs []*Job) {
+	defer cancel()
+	log := utility.GetLogger()
+
+	start := js.clock.Now()
+	results := make([]MachineExecutionResult, len(runs))
+
+	var wg sync.WaitGroup
+	for i, run := range runs {
+		wg.Add(1)
+		go func(i int, run *Job) {
+			defer wg.Done()
+			history := js.executor.ExecuteJob(ctx, run)
+			if len(history.Results) == 0 {
+				now := js.clock.Now()
+				results[i] = MachineExecutionResult{
+					MachineID: run.Machines[0],
+					Status:    JobStatusFailed,
+					Message:   "Revert produced no result",
+					StartTime: now,
+					EndTime:   now,
+					Duration:  "0s",
+				}
+				return
+			}
+			results[i] = history.Results[0]
+		}(i, run)
+	}
+	wg.Wait()
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	delete(js.reverting, job.ID)
+
+	history := &ExecutionHistory{
+		ExecutionTime: start,
+		Status:        executionStatus(results),
+		Results:       results,
+		Message:       fmt.Sprintf("Restored the values changed by execution %s", strings.Join(executions, ", ")),
+		RevertOf:      executions,
+	}
+
+	if current, exists := js.jobs[job.ID]; !exists || current != job {
+		log.Warn().Str("jobID", job.ID).Msg("Job deleted while reverting, revert result not recorded")
+		return
+	}
+	js.recordExecution(job, history)
+	js.finishRevert(job, executions, results)
+	js.persistJobOrWarn(job)
+
+	log.Info().
+		Str("jobID", job.ID).
+		Str("status", string(history.Status)).
+		Msg("Job changes reverted")
+}
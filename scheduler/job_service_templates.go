@@ -0,0 +1,166 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"multifish/utility"
+)
+
+var (
+	// ErrTemplateExists is returned when a template ID is already taken
+	ErrTemplateExists = errors.New("template already exists")
+	// ErrTemplateNotFound is returned when no template has the requested ID
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrInvalidTemplateParameters is returned when instantiation values do not
+	// match the template parameters
+	ErrInvalidTemplateParameters = errors.New("invalid template parameters")
+)
+
+// CreateTemplate validates and stores a job template. An empty ID is generated.
+// The returned slice lists validation problems; it is empty when only err is set.
+func (js *JobService) CreateTemplate(template *JobTemplate) ([]string, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if errs := template.Validate(); len(errs) > 0 {
+		return errs, fmt.Errorf("template validation failed")
+	}
+
+	if template.ID == "" {
+		template.ID = fmt.Sprintf("Template-%d", time.Now().UnixNano())
+	}
+	if _, exists := js.templates[template.ID]; exists {
+		return nil, fmt.Errorf("%w: '%s'. Choose another Id or delete the existing template first", ErrTemplateExists, template.ID)
+	}
+	template.CreatedTime = js.clock.Now()
+
+	if js.store != nil {
+		if err := js.store.SaveTemplate(template); err != nil {
+			return nil, err
+		}
+	}
+
+	js.templates[template.ID] = template
+
+	log := utility.GetLogger()
+	log.Info().
+		Str("templateID", template.ID).
+		Int("parameters", len(template.Parameters)).
+		Msg("Job template created")
+
+	return nil, nil
+}
+
+// GetTemplate retrieves a job template by ID
+func (js *JobService) GetTemplate(templateID string) (*JobTemplate, error) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	template, exists := js.templates[templateID]
+	if !exists {
+		return nil, fmt.Errorf("%w: '%s'. Use GET /JobService/JobTemplates to list available templates", ErrTemplateNotFound, templateID)
+	}
+
+	return template, nil
+}
+
+// ListTemplates returns all job templates ordered by creation time
+func (js *JobService) ListTemplates() []*JobTemplate {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	templates := make([]*JobTemplate, 0, len(js.templates))
+	for _, template := range js.templates {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].CreatedTime.Equal(templates[j].CreatedTime) {
+			return templates[i].ID < templates[j].ID
+		}
+		return templates[i].CreatedTime.Before(templates[j].CreatedTime)
+	})
+	return templates
+}
+
+// DeleteTemplate deletes a job template. Jobs instantiated from it are kept
+// and still record its ID and parameter values.
+func (js *JobService) DeleteTemplate(templateID string) error {
+	log := utility.GetLogger()
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if _, exists := js.templates[templateID]; !exists {
+		return fmt.Errorf("%w: '%s'", ErrTemplateNotFound, templateID)
+	}
+
+	if js.store != nil {
+		if err := js.store.DeleteTemplate(templateID); err != nil {
+			log.Error().Err(err).Str("templateID", templateID).Msg("Failed to delete persisted template")
+			return err
+		}
+	}
+
+	delete(js.templates, templateID)
+	log.Info().Str("templateID", templateID).Msg("Job template deleted")
+
+	return nil
+}
+
+// InstantiateTemplate substitutes the parameter values into a template and
+// creates the job with the normal validation. Parameters left out take their
+// defaults. The job records the template ID and the values used.
+func (js *JobService) InstantiateTemplate(templateID string, values map[string]json.RawMessage) (*Job, *JobValidationResponse, error) {
+	template, err := js.GetTemplate(templateID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolved, errs := template.Resolve(values)
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidTemplateParameters, strings.Join(errs, "; "))
+	}
+
+	req, err := template.instantiate(resolved)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: the instantiated job is not a valid job request: %v", ErrInvalidTemplateParameters, err)
+	}
+
+	job, validationResp, err := js.createJob(req, &TemplateInstance{
+		TemplateID: templateID,
+		Parameters: resolved,
+	})
+	if err != nil {
+		return nil, validationResp, err
+	}
+
+	log := utility.GetLogger()
+	log.Info().
+		Str("jobID", job.ID).
+		Str("templateID", templateID).
+		Msg("Job instantiated from template")
+
+	return job, validationResp, nil
+}
+
+// loadTemplates restores persisted job templates (caller holds js.mu)
+func (js *JobService) loadTemplates() {
+	log := utility.GetLogger()
+
+	templates, err := js.store.LoadTemplates()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load persisted templates")
+		return
+	}
+
+	for _, template := range templates {
+		js.templates[template.ID] = template
+	}
+
+	log.Info().Int("templates", len(templates)).Msg("Restored persisted templates")
+}
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"testing"
 	"time"
 	extendprovider "multifish/providers/extend"
@@ -8,7 +9,8 @@ import (
 
 // Mock JobValidator for testing
 type MockJobValidator struct {
-	ValidateMachinesFunc func(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult
+	ValidateMachinesFunc         func(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult
+	ValidateMachineResourcesFunc func(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult
 }
 
 func (m *MockJobValidator) ValidateMachines(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult {
@@ -27,12 +29,19 @@ func (m *MockJobValidator) ValidateMachines(machineIDs []string, action ActionTy
 	return results
 }
 
+func (m *MockJobValidator) ValidateMachineResources(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult {
+	if m.ValidateMachineResourcesFunc != nil {
+		return m.ValidateMachineResourcesFunc(machineIDs, action, payload)
+	}
+	return m.ValidateMachines(machineIDs, action, payload)
+}
+
 // Mock JobExecutor for testing
 type MockJobExecutor struct {
 	ExecuteJobFunc func(job *Job) *ExecutionHistory
 }
 
-func (m *MockJobExecutor) ExecuteJob(job *Job) *ExecutionHistory {
+func (m *MockJobExecutor) ExecuteJob(ctx context.Context, job *Job) *ExecutionHistory {
 	if m.ExecuteJobFunc != nil {
 		return m.ExecuteJobFunc(job)
 	}
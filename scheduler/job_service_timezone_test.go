@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	return loc
+}
+
+func TestSchedule_Location(t *testing.T) {
+	loc, err := Schedule{}.Location()
+	require.NoError(t, err)
+	assert.Equal(t, time.Local, loc)
+
+	loc, err = Schedule{TimeZone: "UTC"}.Location()
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", loc.String())
+
+	_, err = Schedule{TimeZone: "Mars/Olympus_Mons"}.Location()
+	assert.Error(t, err)
+}
+
+func TestJobCreateRequestValidate_TimeZone(t *testing.T) {
+	loadTestLocation(t, "America/New_York")
+
+	request := newTestJobRequest()
+	request.Schedule.TimeZone = "America/New_York"
+	assert.True(t, request.Validate().Valid)
+
+	request.Schedule.TimeZone = "EST5EDT-ish"
+	result := request.Validate()
+	assert.False(t, result.Valid)
+	require.Len(t, result.ScheduleErrors, 1)
+	assert.Contains(t, result.ScheduleErrors[0], "TimeZone")
+}
+
+func TestJobService_CalculateNextRunTime_TimeZone(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	newYork := loadTestLocation(t, "America/New_York")
+	losAngeles := loadTestLocation(t, "America/Los_Angeles")
+	tokyo := loadTestLocation(t, "Asia/Tokyo")
+	berlin := loadTestLocation(t, "Europe/Berlin")
+
+	endDay := "2026-02-10"
+	startDay := "2026-02-12"
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		schedule Schedule
+		expected time.Time
+	}{
+		{
+			name:     "Once uses the schedule time zone",
+			now:      time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC), // 09:00 in Tokyo
+			schedule: Schedule{Type: ScheduleTypeOnce, Time: "08:00:00", TimeZone: "Asia/Tokyo"},
+			expected: time.Date(2026, 2, 10, 8, 0, 0, 0, tokyo),
+		},
+		{
+			name: "Keeps wall-clock time across spring-forward",
+			now:  time.Date(2026, 3, 7, 12, 0, 0, 0, newYork),
+			schedule: Schedule{Type: ScheduleTypeContinuous, Time: "08:00:00", TimeZone: "America/New_York",
+				Period: &Period{DaysOfWeek: []DayOfWeek{Sunday}}},
+			expected: time.Date(2026, 3, 8, 8, 0, 0, 0, newYork),
+		},
+		{
+			name: "Keeps wall-clock time across fall-back",
+			now:  time.Date(2026, 10, 31, 12, 0, 0, 0, newYork),
+			schedule: Schedule{Type: ScheduleTypeContinuous, Time: "08:00:00", TimeZone: "America/New_York",
+				Period: &Period{DaysOfWeek: []DayOfWeek{Sunday}}},
+			expected: time.Date(2026, 11, 1, 8, 0, 0, 0, newYork),
+		},
+		{
+			name: "Time in DST gap runs after the jump",
+			now:  time.Date(2026, 3, 7, 12, 0, 0, 0, newYork),
+			schedule: Schedule{Type: ScheduleTypeContinuous, Time: "02:30:00", TimeZone: "America/New_York",
+				Period: &Period{DaysOfWeek: []DayOfWeek{Sunday}}},
+			expected: time.Date(2026, 3, 8, 3, 30, 0, 0, newYork),
+		},
+		{
+			// Zones east of UTC resolve the skipped time after the gap
+			name: "Time in DST gap runs after the jump east of UTC",
+			now:  time.Date(2026, 3, 28, 12, 0, 0, 0, berlin),
+			schedule: Schedule{Type: ScheduleTypeContinuous, Time: "02:30:00", TimeZone: "Europe/Berlin",
+				Period: &Period{DaysOfWeek: []DayOfWeek{Sunday}}},
+			expected: time.Date(2026, 3, 29, 3, 30, 0, 0, berlin),
+		},
+		{
+			// 20:00 in Los Angeles on EndDay is already the next day in UTC
+			name: "EndDay is a calendar day in the schedule time zone",
+			now:  time.Date(2026, 2, 10, 12, 0, 0, 0, losAngeles),
+			schedule: Schedule{Type: ScheduleTypeContinuous, Time: "20:00:00", TimeZone: "America/Los_Angeles",
+				Period: &Period{EndDay: &endDay, DaysOfWeek: []DayOfWeek{Tuesday}}},
+			expected: time.Date(2026, 2, 10, 20, 0, 0, 0, losAngeles),
+		},
+		{
+			// Midnight StartDay in Tokyo is still the previous day in UTC
+			name: "StartDay is a calendar day in the schedule time zone",
+			now:  time.Date(2026, 2, 9, 12, 0, 0, 0, tokyo),
+			schedule: Schedule{Type: ScheduleTypeContinuous, Time: "00:30:00", TimeZone: "Asia/Tokyo",
+				Period: &Period{StartDay: &startDay, DaysOfWeek: []DayOfWeek{Thursday}}},
+			expected: time.Date(2026, 2, 12, 0, 30, 0, 0, tokyo),
+		},
+		{
+			name:     "Cron uses the schedule time zone",
+			now:      time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC),
+			schedule: Schedule{Type: ScheduleTypeCron, Cron: "0 8 * * *", TimeZone: "America/New_York"},
+			expected: time.Date(2026, 2, 9, 8, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &Job{ID: "tz-job", Schedule: tt.schedule}
+			nextRun := service.calculateNextRunTimeFrom(job, tt.now)
+			assert.True(t, tt.expected.Equal(nextRun), "expected %s, got %s", tt.expected, nextRun)
+			assert.Equal(t, tt.schedule.TimeZone, nextRun.Location().String())
+		})
+	}
+}
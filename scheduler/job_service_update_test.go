@@ -0,0 +1,245 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobService_UpdateJobPartial(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	runJobOnce(service, job)
+
+	updated, validationResp, err := service.UpdateJob(job.ID, []byte(`{
+		"Name": "Renamed",
+		"Machines": ["machine1", "machine2"],
+		"Schedule": {"Type": "Interval", "Interval": "10m"},
+		"Timeout": "5m"
+	}`))
+	require.NoError(t, err)
+	require.NotNil(t, validationResp)
+	assert.True(t, validationResp.Valid)
+	assert.Same(t, job, updated)
+
+	// The ID, history and unchanged fields are kept
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.Equal(t, []string{"machine1", "machine2"}, updated.Machines)
+	assert.Equal(t, ActionPatchProfile, updated.Action)
+	payload, ok := updated.Payload.([]ExecutePatchProfilePayload)
+	require.True(t, ok, "payload type %T", updated.Payload)
+	assert.Equal(t, "Performance", payload[0].Payload.Profile)
+	assert.Equal(t, "5m", updated.Timeout)
+	assert.Equal(t, 1, updated.ExecutionCount)
+	assert.Equal(t, JobStatusPending, updated.Status)
+
+	executions, err := service.GetExecutions(job.ID)
+	require.NoError(t, err)
+	assert.Len(t, executions, 1)
+
+	// NextRunTime follows the new schedule
+	require.NotNil(t, updated.NextRunTime)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), *updated.NextRunTime, 5*time.Second)
+
+	// null clears optional fields
+	updated, _, err = service.UpdateJob(job.ID, []byte(`{"Timeout": null}`))
+	require.NoError(t, err)
+	assert.Empty(t, updated.Timeout)
+}
+
+func TestJobService_UpdateJobInvalidLeavesJobUntouched(t *testing.T) {
+	validator := &MockJobValidator{
+		ValidateMachinesFunc: func(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult {
+			results := make([]MachineValidationResult, len(machineIDs))
+			for i, machineID := range machineIDs {
+				results[i] = MachineValidationResult{MachineID: machineID, Valid: machineID != "unknown"}
+			}
+			return results
+		},
+	}
+	service := NewJobService(validator, &MockJobExecutor{})
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	nextRun := *job.NextRunTime
+
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"Invalid schedule", `{"Name": "Renamed", "Schedule": {"Type": "Interval", "Interval": "500ms"}}`},
+		{"Unknown machine", `{"Name": "Renamed", "Machines": ["machine1", "unknown"]}`},
+		{"Invalid timeout", `{"Name": "Renamed", "Timeout": "soon"}`},
+		{"Missing calendar", `{"Name": "Renamed", "Calendars": ["missing"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, validationResp, err := service.UpdateJob(job.ID, []byte(tt.patch))
+			assert.Error(t, err)
+			require.NotNil(t, validationResp)
+			assert.False(t, validationResp.Valid)
+
+			assert.Equal(t, "Persisted Job", job.Name)
+			assert.Equal(t, []string{"machine1"}, job.Machines)
+			assert.Equal(t, ScheduleTypeContinuous, job.Schedule.Type)
+			assert.True(t, nextRun.Equal(*job.NextRunTime))
+		})
+	}
+
+	// Payloads that do not match the action are rejected before validation
+	_, _, err = service.UpdateJob(job.ID, []byte(`{"Payload": {"Profile": "Performance"}}`))
+	assert.ErrorIs(t, err, ErrInvalidJobUpdate)
+	_, _, err = service.UpdateJob(job.ID, []byte(`not json`))
+	assert.ErrorIs(t, err, ErrInvalidJobUpdate)
+
+	_, _, err = service.UpdateJob("missing", []byte(`{}`))
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestJobService_UpdateJobKeepsFinishedJobs(t *testing.T) {
+	var mu sync.Mutex
+	executions := 0
+	executor := &MockJobExecutor{
+		ExecuteJobFunc: func(job *Job) *ExecutionHistory {
+			mu.Lock()
+			defer mu.Unlock()
+			executions++
+			return &ExecutionHistory{JobID: job.ID, ExecutionTime: time.Now(), Status: JobStatusCompleted}
+		},
+	}
+	service := NewJobService(&MockJobValidator{}, executor)
+	defer service.Stop()
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		status   JobStatus
+		count    int
+	}{
+		{"Completed immediate Once job", Schedule{Type: ScheduleTypeOnce, Immediate: true}, JobStatusCompleted, 1},
+		{"Completed Once job", Schedule{Type: ScheduleTypeOnce, Time: "08:00:00"}, JobStatusCompleted, 1},
+		{"Failed Once job", Schedule{Type: ScheduleTypeOnce, Time: "08:00:00"}, JobStatusFailed, 1},
+		{"Exhausted Interval job", Schedule{Type: ScheduleTypeInterval, Interval: "10m", MaxExecutions: 2}, JobStatusCompleted, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, _, err := service.CreateJob(newTestJobRequest())
+			require.NoError(t, err)
+
+			// Put the job in the state its last run left it in
+			service.mu.Lock()
+			job.Schedule = tt.schedule
+			job.Status = tt.status
+			job.ExecutionCount = tt.count
+			job.NextRunTime = nil
+			service.schedule.set(job)
+			service.mu.Unlock()
+
+			updated, _, err := service.UpdateJob(job.ID, []byte(`{"Name": "Renamed", "Timeout": "5m"}`))
+			require.NoError(t, err)
+			assert.Equal(t, "Renamed", updated.Name)
+			assert.Equal(t, tt.status, updated.Status)
+			assert.Nil(t, updated.NextRunTime)
+
+			// Changing the schedule still needs an upcoming run
+			if tt.schedule.Type == ScheduleTypeInterval {
+				_, _, err = service.UpdateJob(job.ID, []byte(`{"Schedule": {"Type": "Interval", "Interval": "5m", "MaxExecutions": 2}}`))
+				assert.Error(t, err)
+				assert.Equal(t, tt.status, job.Status)
+			}
+		})
+	}
+
+	service.checkAndExecuteJobs()
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	assert.Zero(t, executions, "finished jobs must not run again")
+	mu.Unlock()
+}
+
+func TestJobService_UpdateJobWithPassedStartTime(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	start := time.Now().Add(time.Hour)
+	request := newTestJobRequest()
+	request.Schedule = Schedule{Type: ScheduleTypeOnce, StartTime: &start}
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	// The StartTime has passed and the job has run
+	service.mu.Lock()
+	passed := time.Now().Add(-time.Hour)
+	job.Schedule.StartTime = &passed
+	job.Status = JobStatusCompleted
+	job.NextRunTime = nil
+	service.schedule.set(job)
+	service.mu.Unlock()
+
+	// Updates that keep the schedule are accepted
+	updated, _, err := service.UpdateJob(job.ID, []byte(`{"Name": "Renamed", "Machines": ["machine1", "machine2"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.Equal(t, []string{"machine1", "machine2"}, updated.Machines)
+	assert.Equal(t, JobStatusCompleted, updated.Status)
+
+	// A new schedule must still start in the future
+	earlier := passed.Add(-time.Hour).Format(time.RFC3339)
+	_, validationResp, err := service.UpdateJob(job.ID, []byte(`{"Schedule": {"Type": "Once", "StartTime": "`+earlier+`"}}`))
+	require.Error(t, err)
+	assert.False(t, validationResp.ScheduleValid)
+	assert.Contains(t, validationResp.ScheduleErrors[0], "is in the past")
+}
+
+func TestJobService_UpdateJobRefusedWhileRunning(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	service.runningMu.Lock()
+	service.runningJobs[job.ID] = true
+	service.runningMu.Unlock()
+
+	_, _, err = service.UpdateJob(job.ID, []byte(`{"Name": "Renamed"}`))
+	assert.ErrorIs(t, err, ErrJobRunning)
+	assert.Equal(t, "Persisted Job", job.Name)
+
+	service.runningMu.Lock()
+	delete(service.runningJobs, job.ID)
+	service.runningMu.Unlock()
+
+	_, _, err = service.UpdateJob(job.ID, []byte(`{"Name": "Renamed"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", job.Name)
+}
+
+func TestJobService_UpdateJobPersists(t *testing.T) {
+	store := newTestJobStore(t)
+
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	_, _, err = service.UpdateJob(job.ID, []byte(`{"Name": "Renamed", "RetryPolicy": {"MaxAttempts": 3}}`))
+	require.NoError(t, err)
+	service.Stop()
+
+	restarted := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	defer restarted.Stop()
+
+	restored, err := restarted.GetJob(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", restored.Name)
+	require.NotNil(t, restored.RetryPolicy)
+	assert.Equal(t, 3, restored.RetryPolicy.MaxAttempts)
+}
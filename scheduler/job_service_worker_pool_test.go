@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
@@ -44,6 +45,7 @@ func TestWorkerPool_ConcurrencyControl(t *testing.T) {
 
 		service.mu.Lock()
 		service.jobs[job.ID] = job
+		service.schedule.set(job)
 		service.mu.Unlock()
 	}
 
@@ -114,7 +116,7 @@ type ConcurrencyTrackingExecutor struct {
 	currentCount  int
 }
 
-func (e *ConcurrencyTrackingExecutor) ExecuteJob(job *Job) *ExecutionHistory {
+func (e *ConcurrencyTrackingExecutor) ExecuteJob(ctx context.Context, job *Job) *ExecutionHistory {
 	e.mu.Lock()
 	e.currentCount++
 	if e.currentCount > e.maxConcurrent {
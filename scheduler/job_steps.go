@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MaxJobSteps bounds the number of steps of a workflow job
+const MaxJobSteps = 20
+
+// stepActions are the actions a workflow step may perform
+var stepActions = []ActionType{ActionPatchProfile, ActionPatchManager, ActionPatchFanController, ActionPatchFanZone, ActionPatchPidController}
+
+// JobStep is one action of a workflow job. Steps run in order on each machine;
+// a failed step stops the remaining steps on that machine unless it has
+// ContinueOnError set.
+type JobStep struct {
+	Name            string     `json:"Name,omitempty"`
+	Action          ActionType `json:"Action"`
+	Payload         Payload    `json:"Payload"`
+	ContinueOnError bool       `json:"ContinueOnError,omitempty"` // A failure of this step does not stop the workflow or fail the machine
+}
+
+// UnmarshalJSON custom unmarshaler for JobStep to handle dynamic Payload type based on Action
+func (s *JobStep) UnmarshalJSON(data []byte) error {
+	type Alias JobStep
+	aux := &struct {
+		Payload json.RawMessage `json:"Payload"`
+		*Alias
+	}{
+		Alias: (*Alias)(s),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	payload, err := decodePayload(s.Action, aux.Payload)
+	if err != nil {
+		return err
+	}
+	s.Payload = payload
+
+	return nil
+}
+
+// label names the step in messages, e.g. "step 2 (PatchPidController)"
+func (s JobStep) label(index int) string {
+	if s.Name != "" {
+		return fmt.Sprintf("step %d '%s' (%s)", index+1, s.Name, s.Action)
+	}
+	return fmt.Sprintf("step %d (%s)", index+1, s.Action)
+}
+
+// StepExecutionResult records one workflow step on a machine
+type StepExecutionResult struct {
+	Step      int                `json:"Step"` // Position in Steps, 1 is the first
+	Name      string             `json:"Name,omitempty"`
+	Action    ActionType         `json:"Action"`
+	Success   bool               `json:"Success"`
+	Status    JobStatus          `json:"Status"` // Completed, Failed, Cancelled, TimedOut or Skipped
+	Message   string             `json:"Message,omitempty"`
+	Error     string             `json:"Error,omitempty"`
+	StartTime time.Time          `json:"StartTime"`
+	EndTime   time.Time          `json:"EndTime"`
+	Duration  string             `json:"Duration"`
+	Attempts  []ExecutionAttempt `json:"Attempts,omitempty"`
+}
+
+// validateSteps validates the steps of a workflow job. Action and Payload are
+// replaced by the steps and must be left empty.
+func (j *JobCreateRequest) validateSteps() (actionErrors []string, payloadErrors []string) {
+	if j.Action != "" || j.Payload != nil {
+		actionErrors = append(actionErrors, "job validation failed: Action and Payload must be empty when Steps are specified. Move the action into the first step")
+	}
+	if len(j.Steps) > MaxJobSteps {
+		actionErrors = append(actionErrors, fmt.Sprintf("job validation failed: at most %d steps are allowed, got %d", MaxJobSteps, len(j.Steps)))
+	}
+
+	for i, step := range j.Steps {
+		if !isStepAction(step.Action) {
+			actionErrors = append(actionErrors, fmt.Sprintf("Steps[%d]: unsupported action type '%s'. Valid actions are: %v", i, step.Action, stepActions))
+			continue
+		}
+		if err := validateActionPayload(step.Action, step.Payload); err != nil {
+			payloadErrors = append(payloadErrors, fmt.Sprintf("Steps[%d]: %v", i, err))
+		}
+	}
+
+	return actionErrors, payloadErrors
+}
+
+func isStepAction(action ActionType) bool {
+	for _, valid := range stepActions {
+		if action == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// validateStepMachines validates the machines against every step and merges
+// the results into one result per machine. validate is one of the JobValidator
+// methods.
+func validateStepMachines(validate func(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult, machineIDs []string, steps []JobStep) []MachineValidationResult {
+	results := make([]MachineValidationResult, len(machineIDs))
+	for i, machineID := range machineIDs {
+		results[i] = MachineValidationResult{MachineID: machineID, Valid: true, Errors: []string{}}
+	}
+
+	for s, step := range steps {
+		for i, stepResult := range validate(machineIDs, step.Action, step.Payload) {
+			if i >= len(results) || stepResult.Valid {
+				continue
+			}
+			results[i].Valid = false
+			if len(stepResult.Errors) == 0 && stepResult.Message != "" {
+				results[i].Errors = append(results[i].Errors, fmt.Sprintf("%s: %s", step.label(s), stepResult.Message))
+			}
+			for _, err := range stepResult.Errors {
+				results[i].Errors = append(results[i].Errors, fmt.Sprintf("%s: %s", step.label(s), err))
+			}
+		}
+	}
+
+	for i := range results {
+		if results[i].Valid {
+			results[i].Message = "Machine is valid for every step"
+		} else {
+			results[i].Message = "Machine validation failed"
+		}
+	}
+
+	return results
+}
+
+// executeSteps runs the steps of a workflow job on a single machine, in order.
+// Each step is retried like a single-action job. A failed step without
+// ContinueOnError fails the machine and skips the remaining steps; an
+// interrupted step (cancel or timeout) always does.
+func (pe *PlatformExecutor) executeSteps(ctx context.Context, job *Job, machineID string) MachineExecutionResult {
+	result := MachineExecutionResult{
+		MachineID: machineID,
+		StartTime: time.Now(),
+		Success:   true,
+		Status:    JobStatusCompleted,
+		Steps:     make([]StepExecutionResult, 0, len(job.Steps)),
+	}
+
+	ignored := 0
+	stopped := ""
+	for i, step := range job.Steps {
+		if stopped != "" {
+			now := time.Now()
+			result.Steps = append(result.Steps, StepExecutionResult{
+				Step:      i + 1,
+				Name:      step.Name,
+				Action:    step.Action,
+				Status:    JobStatusSkipped,
+				Message:   stopped,
+				StartTime: now,
+				EndTime:   now,
+				Duration:  "0s",
+			})
+			continue
+		}
+
+		stepResult, revert := pe.executeAction(ctx, job, machineID, step.Action, step.Payload, step.label(i))
+		if revert != nil {
+			// Reverted in reverse order, so each step is undone on top of the values it changed
+			result.Revert = append([]JobStep{*revert}, result.Revert...)
+		}
+		result.Steps = append(result.Steps, StepExecutionResult{
+			Step:      i + 1,
+			Name:      step.Name,
+			Action:    step.Action,
+			Success:   stepResult.Success,
+			Status:    stepResult.Status,
+			Message:   stepResult.Message,
+			Error:     stepResult.Error,
+			StartTime: stepResult.StartTime,
+			EndTime:   stepResult.EndTime,
+			Duration:  stepResult.Duration,
+			Attempts:  stepResult.Attempts,
+		})
+
+		if stepResult.Success {
+			continue
+		}
+
+		interrupted := stepResult.Status == JobStatusCancelled || stepResult.Status == JobStatusTimedOut
+		if step.ContinueOnError && !interrupted {
+			ignored++
+			continue
+		}
+
+		result.Success = false
+		result.Status = stepResult.Status
+		result.Error = stepResult.Error
+		result.Message = fmt.Sprintf("Workflow stopped at %s: %s", step.label(i), stepResult.Message)
+		stopped = fmt.Sprintf("Not executed, %s did not complete", step.label(i))
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime).String()
+
+	if result.Success {
+		result.Message = fmt.Sprintf("Successfully executed %d steps", len(job.Steps))
+		if ignored > 0 {
+			result.Message = fmt.Sprintf("Executed %d steps, %d failed steps continued on error", len(job.Steps), ignored)
+		}
+	}
+
+	return result
+}
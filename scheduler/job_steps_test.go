@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	extendprovider "multifish/providers/extend"
+)
+
+const workflowRequestJSON = `{
+	"Name": "Custom cooling",
+	"Machines": ["machine1"],
+	"Steps": [
+		{"Name": "Custom profile", "Action": "PatchProfile", "Payload": [{"ManagerID": "bmc", "Payload": {"Profile": "Custom"}}]},
+		{"Action": "PatchPidController", "Payload": [{"ManagerID": "bmc", "PidControllerID": "CPU", "Payload": {"SetPoint": 70}}]},
+		{"Action": "PatchFanZone", "Payload": [{"ManagerID": "bmc", "FanZoneID": "Zone1", "Payload": {"FailSafePercent": 80}}]}
+	],
+	"Schedule": {"Type": "Once", "Immediate": true}
+}`
+
+// newWorkflowTestJob returns a job running the given steps on machine1
+func newWorkflowTestJob(steps ...JobStep) *Job {
+	return &Job{
+		ID:       "workflow-job",
+		Machines: []string{"machine1"},
+		Steps:    steps,
+	}
+}
+
+func profileStep(profile string) JobStep {
+	return JobStep{
+		Action: ActionPatchProfile,
+		Payload: []ExecutePatchProfilePayload{
+			{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: profile}},
+		},
+	}
+}
+
+func fanZoneStep() JobStep {
+	return JobStep{
+		Action: ActionPatchFanZone,
+		Payload: []ExecutePatchFanZonePayload{
+			{ManagerID: "bmc", FanZoneID: "Zone1"},
+		},
+	}
+}
+
+func TestJobCreateRequest_ValidateSteps(t *testing.T) {
+	var request JobCreateRequest
+	require.NoError(t, json.Unmarshal([]byte(workflowRequestJSON), &request))
+	require.Len(t, request.Steps, 3)
+	assert.IsType(t, []ExecutePatchPidControllerPayload{}, request.Steps[1].Payload)
+
+	result := request.Validate()
+	assert.True(t, result.Valid, "errors: %v %v", result.ActionErrors, result.PayloadErrors)
+
+	// Action and Payload are replaced by the steps
+	withAction := request
+	withAction.Action = ActionPatchProfile
+	result = withAction.Validate()
+	assert.False(t, result.ActionValid)
+
+	// Step payloads are validated against the step action
+	invalid := request
+	invalid.Steps = []JobStep{request.Steps[0], {Action: ActionPatchFanZone, Payload: []ExecutePatchFanZonePayload{{ManagerID: "bmc"}}}}
+	result = invalid.Validate()
+	assert.False(t, result.PayloadValid)
+	require.Len(t, result.PayloadErrors, 1)
+	assert.Contains(t, result.PayloadErrors[0], "Steps[1]")
+
+	unknown := request
+	unknown.Steps = []JobStep{{Action: "Reboot"}}
+	result = unknown.Validate()
+	assert.False(t, result.ActionValid)
+}
+
+func TestJobService_CreateWorkflowJobValidatesEveryStep(t *testing.T) {
+	validator := &MockJobValidator{
+		ValidateMachinesFunc: func(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult {
+			result := MachineValidationResult{MachineID: machineIDs[0], Valid: true}
+			if action == ActionPatchFanZone {
+				result.Valid = false
+				result.Errors = []string{"fan zone 'Zone1' not found"}
+			}
+			return []MachineValidationResult{result}
+		},
+	}
+	service := NewJobService(validator, &MockJobExecutor{})
+	defer service.Stop()
+
+	var request JobCreateRequest
+	require.NoError(t, json.Unmarshal([]byte(workflowRequestJSON), &request))
+
+	_, result, err := service.CreateJob(&request)
+	require.Error(t, err)
+	require.Len(t, result.MachineResults, 1)
+	assert.False(t, result.MachineResults[0].Valid)
+	require.Len(t, result.MachineResults[0].Errors, 1)
+	assert.Contains(t, result.MachineResults[0].Errors[0], "step 3 (PatchFanZone)")
+}
+
+func TestPlatformExecutor_RunsStepsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []ActionType
+	record := func(action ActionType) func(interface{}, Payload) error {
+		return func(interface{}, Payload) error {
+			mu.Lock()
+			order = append(order, action)
+			mu.Unlock()
+			return nil
+		}
+	}
+	actionExecutor := &MockActionExecutor{
+		ExecutePatchProfileFunc:       record(ActionPatchProfile),
+		ExecutePatchPidControllerFunc: record(ActionPatchPidController),
+		ExecutePatchFanZoneFunc:       record(ActionPatchFanZone),
+	}
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actionExecutor)
+
+	var request JobCreateRequest
+	require.NoError(t, json.Unmarshal([]byte(workflowRequestJSON), &request))
+	job := newWorkflowTestJob(request.Steps...)
+
+	history := executor.ExecuteJob(context.Background(), job)
+
+	assert.Equal(t, []ActionType{ActionPatchProfile, ActionPatchPidController, ActionPatchFanZone}, order)
+	require.Len(t, history.Results, 1)
+	result := history.Results[0]
+	assert.True(t, result.Success)
+	assert.Equal(t, JobStatusCompleted, result.Status)
+	require.Len(t, result.Steps, 3)
+	for i, step := range result.Steps {
+		assert.Equal(t, i+1, step.Step)
+		assert.True(t, step.Success)
+		assert.Len(t, step.Attempts, 1)
+	}
+	assert.Equal(t, "Custom profile", result.Steps[0].Name)
+}
+
+func TestPlatformExecutor_FailedStepStopsWorkflow(t *testing.T) {
+	fanZoneCalls := 0
+	actionExecutor := &MockActionExecutor{
+		ExecutePatchProfileFunc: func(interface{}, Payload) error {
+			return NewStatusError(http.StatusBadRequest, errors.New("profile rejected"))
+		},
+		ExecutePatchFanZoneFunc: func(interface{}, Payload) error {
+			fanZoneCalls++
+			return nil
+		},
+	}
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actionExecutor)
+
+	history := executor.ExecuteJob(context.Background(), newWorkflowTestJob(profileStep("Custom"), fanZoneStep()))
+
+	result := history.Results[0]
+	assert.False(t, result.Success)
+	assert.Equal(t, JobStatusFailed, result.Status)
+	assert.Contains(t, result.Message, "step 1 (PatchProfile)")
+	assert.Zero(t, fanZoneCalls)
+	require.Len(t, result.Steps, 2)
+	assert.Equal(t, JobStatusFailed, result.Steps[0].Status)
+	assert.Equal(t, JobStatusSkipped, result.Steps[1].Status)
+	assert.Equal(t, JobStatusFailed, executionStatus(history.Results))
+}
+
+func TestPlatformExecutor_ContinueOnError(t *testing.T) {
+	fanZoneCalls := 0
+	actionExecutor := &MockActionExecutor{
+		ExecutePatchProfileFunc: func(interface{}, Payload) error {
+			return errors.New("profile rejected")
+		},
+		ExecutePatchFanZoneFunc: func(interface{}, Payload) error {
+			fanZoneCalls++
+			return nil
+		},
+	}
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actionExecutor)
+
+	optional := profileStep("Custom")
+	optional.ContinueOnError = true
+	history := executor.ExecuteJob(context.Background(), newWorkflowTestJob(optional, fanZoneStep()))
+
+	result := history.Results[0]
+	assert.True(t, result.Success)
+	assert.Equal(t, 1, fanZoneCalls)
+	require.Len(t, result.Steps, 2)
+	assert.False(t, result.Steps[0].Success)
+	assert.Equal(t, JobStatusFailed, result.Steps[0].Status)
+	assert.True(t, result.Steps[1].Success)
+	assert.Contains(t, result.Message, "1 failed steps continued on error")
+}
+
+func TestJob_StepsRoundTrip(t *testing.T) {
+	job := newWorkflowTestJob(profileStep("Custom"), fanZoneStep())
+	data, err := json.Marshal(job)
+	require.NoError(t, err)
+
+	var restored Job
+	require.NoError(t, json.Unmarshal(data, &restored))
+	require.Len(t, restored.Steps, 2)
+	assert.IsType(t, []ExecutePatchProfilePayload{}, restored.Steps[0].Payload)
+	assert.IsType(t, []ExecutePatchFanZonePayload{}, restored.Steps[1].Payload)
+}
@@ -0,0 +1,204 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"multifish/storage"
+	"multifish/utility"
+)
+
+const (
+	// jobsCollection is the storage collection holding scheduled jobs
+	jobsCollection = "jobs"
+	// executionsCollection holds the retained execution history of each job
+	executionsCollection = "executions"
+	// calendarsCollection holds the blackout calendars
+	calendarsCollection = "calendars"
+	// templatesCollection holds the job templates
+	templatesCollection = "templates"
+)
+
+// JobStore persists jobs so schedules, execution counts and status survive restarts
+type JobStore interface {
+	SaveJob(job *Job) error
+	DeleteJob(jobID string) error
+	LoadJobs() ([]*Job, error)
+
+	// Execution history is stored per job as one retained list
+	SaveExecutions(jobID string, executions []*ExecutionHistory) error
+	DeleteExecutions(jobID string) error
+	LoadExecutions(jobID string) ([]*ExecutionHistory, error)
+
+	// Blackout calendars shared by all jobs
+	SaveCalendar(calendar *BlackoutCalendar) error
+	DeleteCalendar(calendarID string) error
+	LoadCalendars() ([]*BlackoutCalendar, error)
+
+	// Job templates instantiated into jobs
+	SaveTemplate(template *JobTemplate) error
+	DeleteTemplate(templateID string) error
+	LoadTemplates() ([]*JobTemplate, error)
+}
+
+// FileJobStore stores each job as a JSON document in the data directory
+type FileJobStore struct {
+	store *storage.FileStore
+}
+
+// NewFileJobStore creates a job store backed by the given file store
+func NewFileJobStore(store *storage.FileStore) *FileJobStore {
+	return &FileJobStore{store: store}
+}
+
+// SaveJob writes (or replaces) a job
+func (s *FileJobStore) SaveJob(job *Job) error {
+	if err := s.store.Put(jobsCollection, job.ID, job); err != nil {
+		return fmt.Errorf("failed to persist job '%s': %w", job.ID, err)
+	}
+	return nil
+}
+
+// DeleteJob removes a job
+func (s *FileJobStore) DeleteJob(jobID string) error {
+	if err := s.store.Delete(jobsCollection, jobID); err != nil {
+		return fmt.Errorf("failed to delete persisted job '%s': %w", jobID, err)
+	}
+	return nil
+}
+
+// LoadJobs returns all persisted jobs ordered by creation time
+func (s *FileJobStore) LoadJobs() ([]*Job, error) {
+	log := utility.GetLogger()
+
+	records, err := s.store.List(jobsCollection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted jobs: %w", err)
+	}
+
+	jobs := make([]*Job, 0, len(records))
+	for key, data := range records {
+		job := &Job{}
+		if err := json.Unmarshal(data, job); err != nil {
+			log.Warn().Err(err).Str("jobID", key).Msg("Skipping unreadable persisted job")
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedTime.Before(jobs[j].CreatedTime) })
+
+	return jobs, nil
+}
+
+// SaveExecutions writes (or replaces) the retained execution history of a job
+func (s *FileJobStore) SaveExecutions(jobID string, executions []*ExecutionHistory) error {
+	if err := s.store.Put(executionsCollection, jobID, executions); err != nil {
+		return fmt.Errorf("failed to persist execution history of job '%s': %w", jobID, err)
+	}
+	return nil
+}
+
+// DeleteExecutions removes the execution history of a job
+func (s *FileJobStore) DeleteExecutions(jobID string) error {
+	if err := s.store.Delete(executionsCollection, jobID); err != nil {
+		return fmt.Errorf("failed to delete execution history of job '%s': %w", jobID, err)
+	}
+	return nil
+}
+
+// LoadExecutions returns the execution history of a job, oldest first.
+// A job without recorded executions has an empty history.
+func (s *FileJobStore) LoadExecutions(jobID string) ([]*ExecutionHistory, error) {
+	var executions []*ExecutionHistory
+	if err := s.store.Get(executionsCollection, jobID, &executions); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load execution history of job '%s': %w", jobID, err)
+	}
+	return executions, nil
+}
+
+// SaveCalendar writes (or replaces) a blackout calendar
+func (s *FileJobStore) SaveCalendar(calendar *BlackoutCalendar) error {
+	if err := s.store.Put(calendarsCollection, calendar.ID, calendar); err != nil {
+		return fmt.Errorf("failed to persist calendar '%s': %w", calendar.ID, err)
+	}
+	return nil
+}
+
+// DeleteCalendar removes a blackout calendar
+func (s *FileJobStore) DeleteCalendar(calendarID string) error {
+	if err := s.store.Delete(calendarsCollection, calendarID); err != nil {
+		return fmt.Errorf("failed to delete persisted calendar '%s': %w", calendarID, err)
+	}
+	return nil
+}
+
+// LoadCalendars returns all persisted blackout calendars ordered by creation time
+func (s *FileJobStore) LoadCalendars() ([]*BlackoutCalendar, error) {
+	log := utility.GetLogger()
+
+	records, err := s.store.List(calendarsCollection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted calendars: %w", err)
+	}
+
+	calendars := make([]*BlackoutCalendar, 0, len(records))
+	for key, data := range records {
+		calendar := &BlackoutCalendar{}
+		if err := json.Unmarshal(data, calendar); err != nil {
+			log.Warn().Err(err).Str("calendarID", key).Msg("Skipping unreadable persisted calendar")
+			continue
+		}
+		calendars = append(calendars, calendar)
+	}
+
+	sort.Slice(calendars, func(i, j int) bool { return calendars[i].CreatedTime.Before(calendars[j].CreatedTime) })
+
+	return calendars, nil
+}
+
+// SaveTemplate writes (or replaces) a job template
+func (s *FileJobStore) SaveTemplate(template *JobTemplate) error {
+	if err := s.store.Put(templatesCollection, template.ID, template); err != nil {
+		return fmt.Errorf("failed to persist template '%s': %w", template.ID, err)
+	}
+	return nil
+}
+
+// DeleteTemplate removes a job template
+func (s *FileJobStore) DeleteTemplate(templateID string) error {
+	if err := s.store.Delete(templatesCollection, templateID); err != nil {
+		return fmt.Errorf("failed to delete persisted template '%s': %w", templateID, err)
+	}
+	return nil
+}
+
+// LoadTemplates returns all persisted job templates ordered by creation time
+func (s *FileJobStore) LoadTemplates() ([]*JobTemplate, error) {
+	log := utility.GetLogger()
+
+	records, err := s.store.List(templatesCollection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted templates: %w", err)
+	}
+
+	templates := make([]*JobTemplate, 0, len(records))
+	for key, data := range records {
+		template := &JobTemplate{}
+		if err := json.Unmarshal(data, template); err != nil {
+			log.Warn().Err(err).Str("templateID", key).Msg("Skipping unreadable persisted template")
+			continue
+		}
+		templates = append(templates, template)
+	}
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].CreatedTime.Before(templates[j].CreatedTime) })
+
+	return templates, nil
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	extendprovider "multifish/providers/extend"
+	"multifish/storage"
+)
+
+func newTestJobStore(t *testing.T) *FileJobStore {
+	fileStore, err := storage.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	return NewFileJobStore(fileStore)
+}
+
+func newTestJobRequest() *JobCreateRequest {
+	return &JobCreateRequest{
+		Name:     "Persisted Job",
+		Machines: []string{"machine1"},
+		Action:   ActionPatchProfile,
+		Payload: []ExecutePatchProfilePayload{
+			{
+				ManagerID: "bmc",
+				Payload:   extendprovider.PatchProfileType{Profile: "Performance"},
+			},
+		},
+		Schedule: Schedule{
+			Type: ScheduleTypeContinuous,
+			Time: "08:00:00",
+			Period: &Period{
+				DaysOfWeek: []DayOfWeek{Monday, Friday},
+			},
+		},
+	}
+}
+
+// TestFileJobStore_SaveLoadDelete tests persisting jobs with typed payloads
+func TestFileJobStore_SaveLoadDelete(t *testing.T) {
+	store := newTestJobStore(t)
+
+	lastRun := time.Now().Add(-time.Hour).Truncate(time.Second)
+	nextRun := time.Now().Add(time.Hour).Truncate(time.Second)
+	job := &Job{
+		ID:       "Job-1",
+		Name:     "Fan zones",
+		Machines: []string{"machine1", "machine2"},
+		Action:   ActionPatchFanZone,
+		Payload: []ExecutePatchFanZonePayload{
+			{ManagerID: "bmc", FanZoneID: "Zone_1"},
+		},
+		Schedule:       newTestJobRequest().Schedule,
+		Status:         JobStatusPending,
+		CreatedTime:    time.Now().Truncate(time.Second),
+		LastRunTime:    &lastRun,
+		NextRunTime:    &nextRun,
+		ExecutionCount: 3,
+	}
+
+	require.NoError(t, store.SaveJob(job))
+
+	jobs, err := store.LoadJobs()
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+
+	loaded := jobs[0]
+	assert.Equal(t, job.ID, loaded.ID)
+	assert.Equal(t, job.Machines, loaded.Machines)
+	assert.Equal(t, job.ExecutionCount, loaded.ExecutionCount)
+	assert.True(t, job.LastRunTime.Equal(*loaded.LastRunTime))
+	assert.True(t, job.NextRunTime.Equal(*loaded.NextRunTime))
+	assert.Equal(t, job.Schedule.Period.DaysOfWeek, loaded.Schedule.Period.DaysOfWeek)
+
+	// Payload is restored with its concrete type
+	payload, ok := loaded.Payload.([]ExecutePatchFanZonePayload)
+	require.True(t, ok, "payload type %T", loaded.Payload)
+	assert.Equal(t, "Zone_1", payload[0].FanZoneID)
+
+	require.NoError(t, store.DeleteJob(job.ID))
+	jobs, err = store.LoadJobs()
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+// TestJobService_PersistsJobs tests that job changes are written through to the store
+func TestJobService_PersistsJobs(t *testing.T) {
+	store := newTestJobStore(t)
+
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	defer service.Stop()
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	jobs, err := store.LoadJobs()
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, job.ID, jobs[0].ID)
+
+	// Cancel is persisted
+	require.NoError(t, service.CancelJob(job.ID))
+	jobs, err = store.LoadJobs()
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCancelled, jobs[0].Status)
+
+	// Delete removes the job from the store
+	require.NoError(t, service.DeleteJob(job.ID))
+	jobs, err = store.LoadJobs()
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+// failingJobStore is a FileJobStore whose job writes or job loads fail
+type failingJobStore struct {
+	*FileJobStore
+	saveErr error
+	loadErr error
+}
+
+func (s *failingJobStore) SaveJob(job *Job) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	return s.FileJobStore.SaveJob(job)
+}
+
+func (s *failingJobStore) LoadJobs() ([]*Job, error) {
+	if s.loadErr != nil {
+		return nil, s.loadErr
+	}
+	return s.FileJobStore.LoadJobs()
+}
+
+// TestJobService_CreateJobStoreFailure tests that a job that cannot be stored
+// is reported as a storage failure, not as a failed validation
+func TestJobService_CreateJobStoreFailure(t *testing.T) {
+	store := &failingJobStore{FileJobStore: newTestJobStore(t), saveErr: errors.New("disk full")}
+
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	defer service.Stop()
+
+	job, validationResp, err := service.CreateJob(newTestJobRequest())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disk full")
+	assert.Nil(t, job)
+	assert.Nil(t, validationResp)
+	assert.Empty(t, service.ListJobs())
+}
+
+// TestJobService_LoadJobsFailureKeepsCalendarsAndTemplates tests that an
+// unreadable job store does not drop the calendars and templates
+func TestJobService_LoadJobsFailureKeepsCalendarsAndTemplates(t *testing.T) {
+	store := &failingJobStore{FileJobStore: newTestJobStore(t), loadErr: errors.New("permission denied")}
+	require.NoError(t, store.SaveCalendar(&BlackoutCalendar{ID: "freeze", Name: "Freeze"}))
+	require.NoError(t, store.SaveTemplate(&JobTemplate{ID: "nightly", Name: "Nightly"}))
+
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	defer service.Stop()
+
+	assert.Empty(t, service.ListJobs())
+	_, err := service.GetCalendar("freeze")
+	assert.NoError(t, err)
+	_, err = service.GetTemplate("nightly")
+	assert.NoError(t, err)
+}
+
+// TestJobService_PersistsExecutionState tests that execution count and times survive a restart
+func TestJobService_PersistsExecutionState(t *testing.T) {
+	store := newTestJobStore(t)
+
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	service.workerPool <- struct{}{}
+	service.executeJobAsync(job, false)
+	service.Stop()
+
+	restarted := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	defer restarted.Stop()
+
+	restored, err := restarted.GetJob(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored.ExecutionCount)
+	assert.NotNil(t, restored.LastRunTime)
+	assert.NotNil(t, restored.NextRunTime)
+	assert.Equal(t, JobStatusPending, restored.Status)
+}
+
+// TestJobService_LoadJobs_InterruptedRun tests that jobs persisted as Running are rescheduled
+func TestJobService_LoadJobs_InterruptedRun(t *testing.T) {
+	store := newTestJobStore(t)
+
+	nextRun := time.Now().Add(time.Hour)
+	require.NoError(t, store.SaveJob(&Job{
+		ID:          "Job-interrupted",
+		Machines:    []string{"machine1"},
+		Action:      ActionPatchProfile,
+		Schedule:    Schedule{Type: ScheduleTypeOnce, Time: "08:00:00"},
+		Status:      JobStatusRunning,
+		CreatedTime: time.Now(),
+		NextRunTime: &nextRun,
+	}))
+
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, store)
+	defer service.Stop()
+
+	job, err := service.GetJob("Job-interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusPending, job.Status)
+	assert.Equal(t, 1, service.GetJobCount())
+}
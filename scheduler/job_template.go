@@ -0,0 +1,352 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TemplateParameterType is the type of a job template parameter
+type TemplateParameterType string
+
+const (
+	TemplateParameterString      TemplateParameterType = "String"      // JSON string
+	TemplateParameterInteger     TemplateParameterType = "Integer"     // JSON number without a fraction
+	TemplateParameterNumber      TemplateParameterType = "Number"      // JSON number
+	TemplateParameterBoolean     TemplateParameterType = "Boolean"     // true or false
+	TemplateParameterStringArray TemplateParameterType = "StringArray" // Array of strings, e.g. a machine list
+)
+
+// templatePlaceholder matches a "{{Name}}" placeholder in a template string
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_]*)\s*\}\}`)
+
+// templateParameterName is the format of a parameter name
+var templateParameterName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// JobTemplate is a job request with "{{Name}}" placeholders for its parameters.
+// A string that is a single placeholder is replaced by the typed parameter
+// value, e.g. "Machines": "{{Machines}}" becomes an array; placeholders inside
+// a longer string are replaced by the value as text.
+type JobTemplate struct {
+	ID          string              `json:"Id"`
+	Name        string              `json:"Name,omitempty"`
+	Description string              `json:"Description,omitempty"`
+	Parameters  []TemplateParameter `json:"Parameters"`
+	Job         json.RawMessage     `json:"Job"` // JobCreateRequest with placeholders
+	CreatedTime time.Time           `json:"CreatedTime"`
+}
+
+// TemplateParameter is a typed parameter of a job template. A parameter
+// without a Default must be given a value when the template is instantiated.
+type TemplateParameter struct {
+	Name        string                `json:"Name"`
+	Type        TemplateParameterType `json:"Type"`
+	Description string                `json:"Description,omitempty"`
+	Default     json.RawMessage       `json:"Default,omitempty"`
+}
+
+// TemplateInstance records the template and parameter values a job was created from
+type TemplateInstance struct {
+	TemplateID string                 `json:"TemplateId"`
+	Parameters map[string]interface{} `json:"Parameters"` // Values after defaults were applied
+}
+
+// Validate validates the template and returns all problems found
+func (t *JobTemplate) Validate() []string {
+	var errors []string
+
+	declared := make(map[string]bool)
+	for i, param := range t.Parameters {
+		if !templateParameterName.MatchString(param.Name) {
+			errors = append(errors, fmt.Sprintf("Parameters[%d]: invalid Name '%s' (must start with a letter and contain only letters, digits and '_')", i, param.Name))
+			continue
+		}
+		if declared[param.Name] {
+			errors = append(errors, fmt.Sprintf("Parameters[%d]: duplicate parameter '%s'", i, param.Name))
+			continue
+		}
+		declared[param.Name] = true
+
+		switch param.Type {
+		case TemplateParameterString, TemplateParameterInteger, TemplateParameterNumber, TemplateParameterBoolean, TemplateParameterStringArray:
+		default:
+			errors = append(errors, fmt.Sprintf("Parameters[%d]: invalid Type '%s' (must be 'String', 'Integer', 'Number', 'Boolean' or 'StringArray')", i, param.Type))
+			continue
+		}
+		if len(param.Default) > 0 {
+			if _, err := param.decode(param.Default); err != nil {
+				errors = append(errors, fmt.Sprintf("Parameters[%d]: invalid Default: %v", i, err))
+			}
+		}
+	}
+
+	var body interface{}
+	if err := decodeTemplateJSON(t.Job, &body); err != nil {
+		return append(errors, fmt.Sprintf("Job must be a JSON object: %v", err))
+	}
+	if _, ok := body.(map[string]interface{}); !ok {
+		return append(errors, "Job must be a JSON object holding a job request")
+	}
+
+	used := make(map[string]bool)
+	walkTemplateStrings(body, func(s string) {
+		for _, match := range templatePlaceholder.FindAllStringSubmatch(s, -1) {
+			used[match[1]] = true
+		}
+	})
+	var undeclared []string
+	for name := range used {
+		if !declared[name] {
+			undeclared = append(undeclared, name)
+		}
+	}
+	sort.Strings(undeclared)
+	for _, name := range undeclared {
+		errors = append(errors, fmt.Sprintf("Job uses '{{%s}}' but no parameter '%s' is declared. Add it to Parameters", name, name))
+	}
+	for _, param := range t.Parameters {
+		if declared[param.Name] && !used[param.Name] {
+			errors = append(errors, fmt.Sprintf("parameter '%s' is not used in Job. Reference it as '{{%s}}' or remove it", param.Name, param.Name))
+			used[param.Name] = true // Report each parameter once
+		}
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+
+	// The body must decode as a job request, checked with the defaults or
+	// placeholder values of each type
+	sample := make(map[string]interface{}, len(t.Parameters))
+	for _, param := range t.Parameters {
+		sample[param.Name] = param.sampleValue()
+	}
+	if _, err := t.instantiate(sample); err != nil {
+		errors = append(errors, fmt.Sprintf("Job is not a valid job request: %v", err))
+	}
+
+	return errors
+}
+
+// Resolve checks the parameter values of an instantiation against the
+// parameter types, applies the defaults and returns the resolved values.
+// It returns all problems found.
+func (t *JobTemplate) Resolve(values map[string]json.RawMessage) (map[string]interface{}, []string) {
+	var errors []string
+	resolved := make(map[string]interface{}, len(t.Parameters))
+
+	declared := make(map[string]bool, len(t.Parameters))
+	for _, param := range t.Parameters {
+		declared[param.Name] = true
+
+		raw, given := values[param.Name]
+		if !given {
+			raw = param.Default
+		}
+		if len(raw) == 0 {
+			errors = append(errors, fmt.Sprintf("parameter '%s' (%s) is required", param.Name, param.Type))
+			continue
+		}
+		value, err := param.decode(raw)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("parameter '%s': %v", param.Name, err))
+			continue
+		}
+		resolved[param.Name] = value
+	}
+
+	var unknown []string
+	for name := range values {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errors = append(errors, fmt.Sprintf("unknown parameter '%s'. The template declares: %s", name, strings.Join(t.parameterNames(), ", ")))
+	}
+
+	return resolved, errors
+}
+
+// instantiate substitutes resolved parameter values into the template body
+// and decodes the result as a job request
+func (t *JobTemplate) instantiate(values map[string]interface{}) (*JobCreateRequest, error) {
+	var body interface{}
+	if err := decodeTemplateJSON(t.Job, &body); err != nil {
+		return nil, err
+	}
+
+	body, err := substituteTemplate(body, values)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req := &JobCreateRequest{}
+	if err := json.Unmarshal(data, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// parameterNames returns the names of the template parameters
+func (t *JobTemplate) parameterNames() []string {
+	names := make([]string, len(t.Parameters))
+	for i, param := range t.Parameters {
+		names[i] = param.Name
+	}
+	return names
+}
+
+// decode parses a parameter value and checks it against the parameter type
+func (p TemplateParameter) decode(raw json.RawMessage) (interface{}, error) {
+	var value interface{}
+	if err := decodeTemplateJSON(raw, &value); err != nil {
+		return nil, err
+	}
+
+	switch p.Type {
+	case TemplateParameterString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case TemplateParameterInteger:
+		if number, ok := value.(json.Number); ok {
+			if i, err := number.Int64(); err == nil {
+				return i, nil
+			}
+		}
+	case TemplateParameterNumber:
+		if number, ok := value.(json.Number); ok {
+			if f, err := number.Float64(); err == nil {
+				return f, nil
+			}
+		}
+	case TemplateParameterBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case TemplateParameterStringArray:
+		if items, ok := value.([]interface{}); ok {
+			strs := make([]string, 0, len(items))
+			for _, item := range items {
+				s, ok := item.(string)
+				if !ok {
+					break
+				}
+				strs = append(strs, s)
+			}
+			if len(strs) == len(items) {
+				return strs, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("expected a value of type %s, got %s", p.Type, string(raw))
+}
+
+// sampleValue returns the default of the parameter, or a value of its type
+func (p TemplateParameter) sampleValue() interface{} {
+	if len(p.Default) > 0 {
+		if value, err := p.decode(p.Default); err == nil {
+			return value
+		}
+	}
+	switch p.Type {
+	case TemplateParameterInteger:
+		return int64(0)
+	case TemplateParameterNumber:
+		return float64(0)
+	case TemplateParameterBoolean:
+		return false
+	case TemplateParameterStringArray:
+		return []string{}
+	default:
+		return ""
+	}
+}
+
+// substituteTemplate replaces the placeholders in every string of a decoded
+// JSON value. Object keys are left unchanged.
+func substituteTemplate(value interface{}, values map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			substituted, err := substituteTemplate(item, values)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = substituted
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			substituted, err := substituteTemplate(item, values)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = substituted
+		}
+		return v, nil
+	case string:
+		// A single placeholder takes the typed value
+		if match := templatePlaceholder.FindStringSubmatch(v); match != nil && match[0] == v {
+			return values[match[1]], nil
+		}
+
+		var err error
+		substituted := templatePlaceholder.ReplaceAllStringFunc(v, func(placeholder string) string {
+			name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+			switch param := values[name].(type) {
+			case string:
+				return param
+			case int64:
+				return strconv.FormatInt(param, 10)
+			case float64:
+				return strconv.FormatFloat(param, 'f', -1, 64)
+			case bool:
+				return strconv.FormatBool(param)
+			default:
+				err = fmt.Errorf("parameter '%s' cannot be used inside the string %q, use it as the whole value", name, v)
+				return placeholder
+			}
+		})
+		return substituted, err
+	default:
+		return v, nil
+	}
+}
+
+// walkTemplateStrings calls fn for every string in a decoded JSON value
+func walkTemplateStrings(value interface{}, fn func(string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			walkTemplateStrings(item, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkTemplateStrings(item, fn)
+		}
+	case string:
+		fn(v)
+	}
+}
+
+// decodeTemplateJSON decodes JSON keeping numbers exact
+func decodeTemplateJSON(data []byte, value interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("value is empty")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
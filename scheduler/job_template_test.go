@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTemplate returns a rack profile template with a machine list, a time
+// of day and typed job settings as parameters
+func newTestTemplate() *JobTemplate {
+	return &JobTemplate{
+		ID:   "rack-profile",
+		Name: "Rack profile switch",
+		Parameters: []TemplateParameter{
+			{Name: "Rack", Type: TemplateParameterString},
+			{Name: "Machines", Type: TemplateParameterStringArray},
+			{Name: "Time", Type: TemplateParameterString, Default: json.RawMessage(`"08:00:00"`)},
+			{Name: "Priority", Type: TemplateParameterInteger, Default: json.RawMessage(`50`)},
+			{Name: "Tolerance", Type: TemplateParameterNumber},
+		},
+		Job: json.RawMessage(`{
+			"Name": "Performance {{Rack}}",
+			"Machines": "{{Machines}}",
+			"Action": "PatchProfile",
+			"Payload": [{"ManagerID": "bmc", "Payload": {"Profile": "Performance"}}],
+			"Schedule": {"Type": "Continuous", "Time": "{{Time}}", "Period": {"DaysOfWeek": ["Monday"]}},
+			"Priority": "{{Priority}}",
+			"Verify": true,
+			"VerifyTolerance": "{{ Tolerance }}"
+		}`),
+	}
+}
+
+func TestJobTemplate_Validate(t *testing.T) {
+	assert.Empty(t, newTestTemplate().Validate())
+
+	template := newTestTemplate()
+	template.Parameters = append(template.Parameters,
+		TemplateParameter{Name: "Tolerance", Type: TemplateParameterNumber},
+		TemplateParameter{Name: "Zone", Type: "Text"},
+		TemplateParameter{Name: "Count", Type: TemplateParameterInteger, Default: json.RawMessage(`1.5`)},
+		TemplateParameter{Name: "1st", Type: TemplateParameterString},
+	)
+	template.Job = json.RawMessage(`{"Name": "{{Rack}} {{Row}}", "Machines": "{{Machines}}"}`)
+	assert.Equal(t, []string{
+		"Parameters[5]: duplicate parameter 'Tolerance'",
+		"Parameters[6]: invalid Type 'Text' (must be 'String', 'Integer', 'Number', 'Boolean' or 'StringArray')",
+		"Parameters[7]: invalid Default: expected a value of type Integer, got 1.5",
+		"Parameters[8]: invalid Name '1st' (must start with a letter and contain only letters, digits and '_')",
+		"Job uses '{{Row}}' but no parameter 'Row' is declared. Add it to Parameters",
+		"parameter 'Time' is not used in Job. Reference it as '{{Time}}' or remove it",
+		"parameter 'Priority' is not used in Job. Reference it as '{{Priority}}' or remove it",
+		"parameter 'Tolerance' is not used in Job. Reference it as '{{Tolerance}}' or remove it",
+		"parameter 'Zone' is not used in Job. Reference it as '{{Zone}}' or remove it",
+		"parameter 'Count' is not used in Job. Reference it as '{{Count}}' or remove it",
+	}, template.Validate())
+
+	// A parameter whose type does not fit the field is caught at creation
+	template = newTestTemplate()
+	template.Parameters[3].Type = TemplateParameterString
+	template.Parameters[3].Default = json.RawMessage(`"high"`)
+	errs := template.Validate()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "Job is not a valid job request")
+
+	template.Job = json.RawMessage(`["not", "an", "object"]`)
+	assert.Contains(t, template.Validate(), "Job must be a JSON object holding a job request")
+}
+
+func TestJobTemplate_Resolve(t *testing.T) {
+	template := newTestTemplate()
+
+	values, errs := template.Resolve(map[string]json.RawMessage{
+		"Rack":      json.RawMessage(`"R12"`),
+		"Machines":  json.RawMessage(`["r12-1", "r12-2"]`),
+		"Tolerance": json.RawMessage(`0.5`),
+	})
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]interface{}{
+		"Rack":      "R12",
+		"Machines":  []string{"r12-1", "r12-2"},
+		"Time":      "08:00:00",
+		"Priority":  int64(50),
+		"Tolerance": 0.5,
+	}, values)
+
+	_, errs = template.Resolve(map[string]json.RawMessage{
+		"Rack":     json.RawMessage(`12`),
+		"Machines": json.RawMessage(`["r12-1", 2]`),
+		"Priority": json.RawMessage(`50.5`),
+		"Fans":     json.RawMessage(`4`),
+	})
+	assert.Equal(t, []string{
+		"parameter 'Rack': expected a value of type String, got 12",
+		`parameter 'Machines': expected a value of type StringArray, got ["r12-1", 2]`,
+		"parameter 'Priority': expected a value of type Integer, got 50.5",
+		"parameter 'Tolerance' (Number) is required",
+		"unknown parameter 'Fans'. The template declares: Rack, Machines, Time, Priority, Tolerance",
+	}, errs)
+}
+
+func TestJobService_InstantiateTemplate(t *testing.T) {
+	service := NewJobServiceWithStore(&MockJobValidator{}, &MockJobExecutor{}, newTestJobStore(t))
+	defer service.Stop()
+
+	errs, err := service.CreateTemplate(newTestTemplate())
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	_, err = service.CreateTemplate(newTestTemplate())
+	assert.ErrorIs(t, err, ErrTemplateExists)
+
+	job, _, err := service.InstantiateTemplate("rack-profile", map[string]json.RawMessage{
+		"Rack":      json.RawMessage(`"R12"`),
+		"Machines":  json.RawMessage(`["r12-1", "r12-2"]`),
+		"Time":      json.RawMessage(`"21:30:00"`),
+		"Tolerance": json.RawMessage(`0.5`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Performance R12", job.Name)
+	assert.Equal(t, []string{"r12-1", "r12-2"}, job.Machines)
+	assert.Equal(t, "21:30:00", job.Schedule.Time)
+	assert.Equal(t, 50, job.Priority)
+	assert.Equal(t, 0.5, *job.VerifyTolerance)
+	require.NotNil(t, job.Template)
+	assert.Equal(t, "rack-profile", job.Template.TemplateID)
+	assert.Equal(t, int64(50), job.Template.Parameters["Priority"], "defaults are recorded")
+
+	// Parameter values go through the normal job validation
+	_, result, err := service.InstantiateTemplate("rack-profile", map[string]json.RawMessage{
+		"Rack":      json.RawMessage(`"R13"`),
+		"Machines":  json.RawMessage(`["r13-1"]`),
+		"Time":      json.RawMessage(`"25:00:00"`),
+		"Tolerance": json.RawMessage(`0.5`),
+	})
+	require.Error(t, err)
+	assert.False(t, result.ScheduleValid)
+
+	_, _, err = service.InstantiateTemplate("rack-profile", nil)
+	assert.True(t, errors.Is(err, ErrInvalidTemplateParameters))
+	_, _, err = service.InstantiateTemplate("missing", nil)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	// Templates are persisted and deleting one keeps its jobs
+	stored, err := service.store.LoadTemplates()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "rack-profile", stored[0].ID)
+
+	require.NoError(t, service.DeleteTemplate("rack-profile"))
+	assert.Empty(t, service.ListTemplates())
+	kept, err := service.GetJob(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "rack-profile", kept.Template.TemplateID)
+}
@@ -0,0 +1,435 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"multifish/utility"
+)
+
+// LockPolicy decides what an operation does when the machine it changes is
+// locked by another job or PATCH request
+type LockPolicy string
+
+const (
+	LockPolicyWait     LockPolicy = "Wait"     // Wait in line up to the lock wait timeout, then fail
+	LockPolicyFailFast LockPolicy = "FailFast" // Fail at once
+	LockPolicyQueue    LockPolicy = "Queue"    // Wait in line for as long as the operation may run
+)
+
+// ValidLockPolicies lists the accepted lock policies
+var ValidLockPolicies = []string{string(LockPolicyWait), string(LockPolicyFailFast), string(LockPolicyQueue)}
+
+// LockScope decides what a lock covers
+type LockScope string
+
+const (
+	LockScopeMachine LockScope = "Machine" // One operation per machine
+	LockScopeManager LockScope = "Manager" // One operation per manager, operations on other managers of the machine run
+)
+
+// ValidLockScopes lists the accepted lock scopes
+var ValidLockScopes = []string{string(LockScopeMachine), string(LockScopeManager)}
+
+const (
+	// DefaultLockPolicy waits for the running operation to finish
+	DefaultLockPolicy = LockPolicyWait
+	// DefaultLockWaitTimeout is how long the Wait policy waits for a lock
+	DefaultLockWaitTimeout = 30 * time.Second
+	// DefaultLockScope locks whole machines
+	DefaultLockScope = LockScopeMachine
+)
+
+// ErrMachineLocked is returned when a machine is locked by another operation
+// and the lock policy does not wait for it
+var ErrMachineLocked = errors.New("machine is locked by another operation")
+
+// ErrLockScopeInUse is returned when the lock scope is changed while
+// operations hold or wait for locks of the current scope
+var ErrLockScopeInUse = errors.New("lock scope is in use by held locks")
+
+// ValidateLockPolicy returns an error for an unknown lock policy
+func ValidateLockPolicy(policy LockPolicy) error {
+	for _, valid := range ValidLockPolicies {
+		if string(policy) == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid LockPolicy: %s (must be 'Wait', 'FailFast' or 'Queue')", policy)
+}
+
+// ValidateLockScope returns an error for an unknown lock scope
+func ValidateLockScope(scope LockScope) error {
+	for _, valid := range ValidLockScopes {
+		if string(scope) == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid LockScope: %s (must be 'Machine' or 'Manager')", scope)
+}
+
+// LockOwner identifies the operation holding or waiting for a lock
+type LockOwner struct {
+	JobID     string `json:"JobId,omitempty"` // Set for job executions
+	Operation string `json:"Operation"`       // Action of a job, or method and path of a PATCH request
+}
+
+func (o LockOwner) String() string {
+	if o.JobID != "" {
+		return fmt.Sprintf("job '%s' (%s)", o.JobID, o.Operation)
+	}
+	return o.Operation
+}
+
+// MachineLock describes a held lock
+type MachineLock struct {
+	Resource     string      `json:"Resource"` // Machine ID, or "<machine>/Managers/<manager>" in the Manager scope
+	MachineID    string      `json:"MachineId"`
+	ManagerID    string      `json:"ManagerId,omitempty"`
+	Owner        LockOwner   `json:"Owner"`
+	AcquiredTime time.Time   `json:"AcquiredTime"`
+	Waiting      []LockOwner `json:"Waiting"` // Operations waiting for the lock, in the order they get it
+}
+
+// lockWaiter is an operation waiting for a lock. ready is closed when the lock
+// is handed to it.
+type lockWaiter struct {
+	owner LockOwner
+	ready chan struct{}
+}
+
+// heldLock is a lock and the operations waiting for it, in arrival order
+type heldLock struct {
+	lock    MachineLock
+	waiters []*lockWaiter
+}
+
+// MachineLockManager serializes the operations that change a machine, so a job
+// and a manual PATCH (or two jobs) do not apply contradictory settings to the
+// same BMC at the same moment. Waiting operations get the lock in arrival order.
+type MachineLockManager struct {
+	mu          sync.Mutex
+	locks       map[string]*heldLock // Held locks by resource
+	policy      LockPolicy
+	waitTimeout time.Duration
+	scope       LockScope
+	active      int // Operations holding or waiting for locks
+}
+
+// NewMachineLockManager creates a lock manager with the default policy, wait
+// timeout and scope
+func NewMachineLockManager() *MachineLockManager {
+	return &MachineLockManager{
+		locks:       make(map[string]*heldLock),
+		policy:      DefaultLockPolicy,
+		waitTimeout: DefaultLockWaitTimeout,
+		scope:       DefaultLockScope,
+	}
+}
+
+// GetSettings returns the default lock policy, the wait timeout of the Wait
+// policy and the lock scope
+func (lm *MachineLockManager) GetSettings() (LockPolicy, time.Duration, LockScope) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.policy, lm.waitTimeout, lm.scope
+}
+
+// SetSettings updates the default lock policy, the wait timeout of the Wait
+// policy (0 waits without limit) and the lock scope. The scope cannot change
+// while operations hold or wait for locks, a Machine lock and a Manager lock
+// of the same machine would not exclude each other.
+func (lm *MachineLockManager) SetSettings(policy LockPolicy, waitTimeout time.Duration, scope LockScope) error {
+	log := utility.GetLogger()
+
+	if err := ValidateLockPolicy(policy); err != nil {
+		return fmt.Errorf("lock configuration failed: %v. Configure lock_policy in config file", err)
+	}
+	if waitTimeout < 0 {
+		return fmt.Errorf("lock configuration failed: lock wait timeout must not be negative, got %s. Configure lock_wait_timeout_seconds in config file", waitTimeout)
+	}
+	if err := ValidateLockScope(scope); err != nil {
+		return fmt.Errorf("lock configuration failed: %v. Configure lock_scope in config file", err)
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if scope != lm.scope && lm.active > 0 {
+		return fmt.Errorf("%w: %d operations hold or wait for %s locks. Retry once they have finished",
+			ErrLockScopeInUse, lm.active, lm.scope)
+	}
+
+	lm.policy = policy
+	lm.waitTimeout = waitTimeout
+	lm.scope = scope
+
+	log.Info().
+		Str("lockPolicy", string(policy)).
+		Dur("lockWaitTimeout", waitTimeout).
+		Str("lockScope", string(scope)).
+		Msg("Machine lock settings updated")
+	return nil
+}
+
+// Acquire locks a machine for an operation, or only the given managers of it in
+// the Manager scope. policy overrides the default policy when set. The returned
+// function releases the locks and hands them to the next waiting operations.
+// An error wrapping ErrMachineLocked means the machine stayed locked; ctx
+// ending while waiting returns its error.
+func (lm *MachineLockManager) Acquire(ctx context.Context, machineID string, managerIDs []string, owner LockOwner, policy LockPolicy) (func(), error) {
+	lm.mu.Lock()
+	if policy == "" {
+		policy = lm.policy
+	}
+	waitTimeout := lm.waitTimeout
+	locks := lm.lockedResources(machineID, managerIDs)
+	lm.active++
+	lm.mu.Unlock()
+
+	var expired <-chan time.Time
+	if policy == LockPolicyWait && waitTimeout > 0 {
+		timer := time.NewTimer(waitTimeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	// Resources are locked in sorted order so two operations never wait for
+	// each other
+	held := make([]string, 0, len(locks))
+	release := func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			lm.release(held[i])
+		}
+		lm.mu.Lock()
+		lm.active--
+		lm.mu.Unlock()
+	}
+	for _, lock := range locks {
+		if err := lm.lock(ctx, expired, lock, owner, policy); err != nil {
+			release()
+			return nil, err
+		}
+		held = append(held, lock.Resource)
+	}
+
+	var once sync.Once
+	return func() { once.Do(release) }, nil
+}
+
+// lockedResources returns the locks an operation needs, sorted by resource
+// (caller holds lm.mu)
+func (lm *MachineLockManager) lockedResources(machineID string, managerIDs []string) []MachineLock {
+	if lm.scope != LockScopeManager || len(managerIDs) == 0 {
+		return []MachineLock{{Resource: machineID, MachineID: machineID}}
+	}
+
+	seen := make(map[string]bool)
+	var locks []MachineLock
+	for _, managerID := range managerIDs {
+		if seen[managerID] {
+			continue
+		}
+		seen[managerID] = true
+		locks = append(locks, MachineLock{
+			Resource:  fmt.Sprintf("%s/Managers/%s", machineID, managerID),
+			MachineID: machineID,
+			ManagerID: managerID,
+		})
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Resource < locks[j].Resource })
+	return locks
+}
+
+// lock takes one lock, waiting in line for it unless the policy fails fast
+func (lm *MachineLockManager) lock(ctx context.Context, expired <-chan time.Time, lock MachineLock, owner LockOwner, policy LockPolicy) error {
+	log := utility.GetLogger()
+
+	lm.mu.Lock()
+	held, locked := lm.locks[lock.Resource]
+	if !locked {
+		lock.Owner = owner
+		lock.AcquiredTime = time.Now()
+		lm.locks[lock.Resource] = &heldLock{lock: lock}
+		lm.mu.Unlock()
+		return nil
+	}
+	holder := held.lock.Owner
+	if policy == LockPolicyFailFast {
+		lm.mu.Unlock()
+		return fmt.Errorf("%w: '%s' is held by %s since %s. Retry once it has finished, or use the Wait or Queue lock policy",
+			ErrMachineLocked, lock.Resource, holder, held.lock.AcquiredTime.Format(time.RFC3339))
+	}
+	waiter := &lockWaiter{owner: owner, ready: make(chan struct{})}
+	held.waiters = append(held.waiters, waiter)
+	lm.mu.Unlock()
+
+	log.Info().
+		Str("resource", lock.Resource).
+		Str("owner", owner.String()).
+		Str("holder", holder.String()).
+		Msg("Waiting for machine lock")
+
+	var err error
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-expired:
+		err = fmt.Errorf("%w: '%s' is still held by %s after waiting. Retry later, or use the Queue lock policy to wait without a limit",
+			ErrMachineLocked, lock.Resource, holder)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	lm.mu.Lock()
+	select {
+	case <-waiter.ready:
+		// The lock was handed over while giving up, pass it on
+		lm.mu.Unlock()
+		lm.release(lock.Resource)
+		return err
+	default:
+	}
+	for i, w := range held.waiters {
+		if w == waiter {
+			held.waiters = append(held.waiters[:i], held.waiters[i+1:]...)
+			break
+		}
+	}
+	lm.mu.Unlock()
+	return err
+}
+
+// release hands a lock to the first waiting operation, or frees it
+func (lm *MachineLockManager) release(resource string) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	held, locked := lm.locks[resource]
+	if !locked {
+		return
+	}
+	if len(held.waiters) == 0 {
+		delete(lm.locks, resource)
+		return
+	}
+
+	next := held.waiters[0]
+	held.waiters = held.waiters[1:]
+	held.lock.Owner = next.owner
+	held.lock.AcquiredTime = time.Now()
+	close(next.ready)
+}
+
+// GetLocks returns the held locks and their waiting operations, sorted by
+// resource
+func (lm *MachineLockManager) GetLocks() []MachineLock {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	locks := make([]MachineLock, 0, len(lm.locks))
+	for _, held := range lm.locks {
+		lock := held.lock
+		lock.Waiting = make([]LockOwner, len(held.waiters))
+		for i, waiter := range held.waiters {
+			lock.Waiting[i] = waiter.owner
+		}
+		locks = append(locks, lock)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Resource < locks[j].Resource })
+	return locks
+}
+
+// payloadManagerIDs returns the managers a payload changes
+func payloadManagerIDs(payload Payload) []string {
+	var managerIDs []string
+	switch payloads := payload.(type) {
+	case []ExecutePatchManagerPayload:
+		for _, p := range payloads {
+			managerIDs = append(managerIDs, p.ManagerID)
+		}
+	case []ExecutePatchProfilePayload:
+		for _, p := range payloads {
+			managerIDs = append(managerIDs, p.ManagerID)
+		}
+	case []ExecutePatchFanControllerPayload:
+		for _, p := range payloads {
+			managerIDs = append(managerIDs, p.ManagerID)
+		}
+	case []ExecutePatchFanZonePayload:
+		for _, p := range payloads {
+			managerIDs = append(managerIDs, p.ManagerID)
+		}
+	case []ExecutePatchPidControllerPayload:
+		for _, p := range payloads {
+			managerIDs = append(managerIDs, p.ManagerID)
+		}
+	}
+	return managerIDs
+}
+
+// lockManagerIDs returns the managers a job changes, over all of its steps
+func (j *Job) lockManagerIDs() []string {
+	if len(j.Steps) == 0 {
+		return payloadManagerIDs(j.Payload)
+	}
+	var managerIDs []string
+	for _, step := range j.Steps {
+		managerIDs = append(managerIDs, payloadManagerIDs(step.Payload)...)
+	}
+	return managerIDs
+}
+
+// lockOperation names a job execution in the lock listing
+func (j *Job) lockOperation() string {
+	if len(j.Steps) > 0 {
+		return fmt.Sprintf("Workflow of %d steps", len(j.Steps))
+	}
+	return string(j.Action)
+}
+
+// lockMachine takes the lock of a machine for a job execution. It returns a
+// no-op release when the executor has no lock manager.
+func (pe *PlatformExecutor) lockMachine(ctx context.Context, job *Job, machineID string) (func(), error) {
+	if pe.locks == nil {
+		return func() {}, nil
+	}
+	return pe.locks.Acquire(ctx, machineID, job.lockManagerIDs(), LockOwner{JobID: job.ID, Operation: job.lockOperation()}, job.LockPolicy)
+}
+
+// lockFailedResult records a machine that was not executed because its lock
+// could not be taken
+func lockFailedResult(ctx context.Context, job *Job, machineID string, startTime time.Time, err error) MachineExecutionResult {
+	log := utility.GetLogger()
+
+	result := MachineExecutionResult{
+		MachineID: machineID,
+		Success:   false,
+		Status:    JobStatusFailed,
+		Message:   "Not executed, the machine is locked by another operation",
+		Error:     err.Error(),
+		StartTime: startTime,
+		EndTime:   time.Now(),
+	}
+	result.Duration = result.EndTime.Sub(result.StartTime).String()
+
+	switch ctx.Err() {
+	case context.Canceled:
+		result.Status = JobStatusCancelled
+		result.Message = "Not executed, job cancelled while waiting for the machine lock"
+	case context.DeadlineExceeded:
+		result.Status = JobStatusTimedOut
+		result.Message = "Not executed, job timeout exceeded while waiting for the machine lock"
+	}
+
+	log.Warn().
+		Err(err).
+		Str("jobID", job.ID).
+		Str("machineID", machineID).
+		Msg("Machine lock not acquired")
+	return result
+}
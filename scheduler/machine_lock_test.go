@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMachineLockManager_FailFast(t *testing.T) {
+	locks := NewMachineLockManager()
+	ctx := context.Background()
+
+	release, err := locks.Acquire(ctx, "machine1", nil, LockOwner{JobID: "Job-1", Operation: "PatchProfile"}, "")
+	require.NoError(t, err)
+
+	_, err = locks.Acquire(ctx, "machine1", nil, LockOwner{Operation: "PATCH /fan"}, LockPolicyFailFast)
+	require.ErrorIs(t, err, ErrMachineLocked)
+	assert.Contains(t, err.Error(), "job 'Job-1' (PatchProfile)")
+
+	// Other machines are not affected
+	other, err := locks.Acquire(ctx, "machine2", nil, LockOwner{Operation: "PATCH /fan"}, LockPolicyFailFast)
+	require.NoError(t, err)
+	other()
+
+	held := locks.GetLocks()
+	require.Len(t, held, 1)
+	assert.Equal(t, "machine1", held[0].Resource)
+	assert.Equal(t, "Job-1", held[0].Owner.JobID)
+	assert.Empty(t, held[0].Waiting)
+
+	release()
+	release() // Releasing twice is harmless
+	assert.Empty(t, locks.GetLocks())
+}
+
+func TestMachineLockManager_QueueInArrivalOrder(t *testing.T) {
+	locks := NewMachineLockManager()
+	ctx := context.Background()
+
+	release, err := locks.Acquire(ctx, "machine1", nil, LockOwner{JobID: "Job-1"}, LockPolicyQueue)
+	require.NoError(t, err)
+
+	acquired := make(chan string, 2)
+	for _, jobID := range []string{"Job-2", "Job-3"} {
+		go func(jobID string) {
+			release, err := locks.Acquire(ctx, "machine1", nil, LockOwner{JobID: jobID}, LockPolicyQueue)
+			if err != nil {
+				acquired <- err.Error()
+				return
+			}
+			acquired <- jobID
+			release()
+		}(jobID)
+		// Wait until the job is in line so the order is fixed
+		require.Eventually(t, func() bool {
+			held := locks.GetLocks()
+			return len(held) == 1 && len(held[0].Waiting) > 0 && held[0].Waiting[len(held[0].Waiting)-1].JobID == jobID
+		}, time.Second, time.Millisecond)
+	}
+
+	held := locks.GetLocks()
+	assert.Equal(t, []LockOwner{{JobID: "Job-2"}, {JobID: "Job-3"}}, held[0].Waiting)
+
+	release()
+	assert.Equal(t, "Job-2", <-acquired)
+	assert.Equal(t, "Job-3", <-acquired)
+	assert.Eventually(t, func() bool { return len(locks.GetLocks()) == 0 }, time.Second, time.Millisecond)
+}
+
+func TestMachineLockManager_WaitTimeoutAndCancel(t *testing.T) {
+	locks := NewMachineLockManager()
+	require.NoError(t, locks.SetSettings(LockPolicyWait, 20*time.Millisecond, LockScopeMachine))
+
+	release, err := locks.Acquire(context.Background(), "machine1", nil, LockOwner{JobID: "Job-1"}, "")
+	require.NoError(t, err)
+	defer release()
+
+	_, err = locks.Acquire(context.Background(), "machine1", nil, LockOwner{JobID: "Job-2"}, "")
+	require.ErrorIs(t, err, ErrMachineLocked)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = locks.Acquire(ctx, "machine1", nil, LockOwner{JobID: "Job-3"}, LockPolicyQueue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Operations that gave up no longer wait for the lock
+	assert.Empty(t, locks.GetLocks()[0].Waiting)
+
+	assert.Error(t, locks.SetSettings("Block", time.Second, LockScopeMachine))
+	assert.Error(t, locks.SetSettings(LockPolicyWait, -time.Second, LockScopeMachine))
+	assert.Error(t, locks.SetSettings(LockPolicyWait, time.Second, "Rack"))
+}
+
+func TestMachineLockManager_ManagerScope(t *testing.T) {
+	locks := NewMachineLockManager()
+	require.NoError(t, locks.SetSettings(LockPolicyFailFast, DefaultLockWaitTimeout, LockScopeManager))
+	ctx := context.Background()
+
+	release, err := locks.Acquire(ctx, "machine1", []string{"bmc", "bmc"}, LockOwner{JobID: "Job-1"}, "")
+	require.NoError(t, err)
+	defer release()
+
+	other, err := locks.Acquire(ctx, "machine1", []string{"bmc2"}, LockOwner{JobID: "Job-2"}, "")
+	require.NoError(t, err, "other managers of the machine are not locked")
+	defer other()
+
+	_, err = locks.Acquire(ctx, "machine1", []string{"bmc2", "bmc3"}, LockOwner{JobID: "Job-3"}, "")
+	require.ErrorIs(t, err, ErrMachineLocked)
+
+	held := locks.GetLocks()
+	require.Len(t, held, 2, "locks taken before a conflict are released")
+	assert.Equal(t, "machine1/Managers/bmc", held[0].Resource)
+	assert.Equal(t, "bmc", held[0].ManagerID)
+	assert.Equal(t, "machine1/Managers/bmc2", held[1].Resource)
+}
+
+func TestPlatformExecutor_LockedMachine(t *testing.T) {
+	locks := NewMachineLockManager()
+	executed := 0
+	actions := &MockActionExecutor{
+		ExecutePatchProfileFunc: func(machine interface{}, managerPayloads Payload) error {
+			executed++
+			return nil
+		},
+	}
+	executor := NewPlatformExecutorWithLocks(&MockJobPlatformManager{}, actions, locks)
+
+	job := newWorkflowTestJob(profileStep("Performance"))
+	job.Steps = nil
+	job.Action = ActionPatchProfile
+	job.Payload = profileStep("Performance").Payload
+	job.LockPolicy = LockPolicyFailFast
+
+	release, err := locks.Acquire(context.Background(), job.Machines[0], nil, LockOwner{Operation: "PATCH /fan"}, "")
+	require.NoError(t, err)
+
+	history := executor.ExecuteJob(context.Background(), job)
+	result := history.Results[0]
+	assert.False(t, result.Success)
+	assert.Equal(t, JobStatusFailed, result.Status)
+	assert.Contains(t, result.Message, "locked by another operation")
+	assert.Contains(t, result.Error, "PATCH /fan")
+	assert.Equal(t, 0, executed)
+
+	release()
+	history = executor.ExecuteJob(context.Background(), job)
+	assert.True(t, history.Results[0].Success)
+	assert.Equal(t, 1, executed)
+	assert.Empty(t, locks.GetLocks(), "the lock is released after the run")
+}
+
+func TestJobCreateRequest_ValidateLockPolicy(t *testing.T) {
+	request := newTestJobRequest()
+	request.LockPolicy = LockPolicyQueue
+	assert.True(t, request.Validate().Valid)
+
+	request.LockPolicy = "Block"
+	result := request.Validate()
+	assert.False(t, result.Valid)
+	assert.Contains(t, result.ScheduleErrors, "invalid LockPolicy: Block (must be 'Wait', 'FailFast' or 'Queue')")
+}
+
+func TestMachineLockManager_ScopeChangeRefusedWhileLocked(t *testing.T) {
+	locks := NewMachineLockManager()
+	require.NoError(t, locks.SetSettings(LockPolicyFailFast, DefaultLockWaitTimeout, LockScopeManager))
+	ctx := context.Background()
+
+	release, err := locks.Acquire(ctx, "machine1", []string{"bmc"}, LockOwner{JobID: "Job-1"}, "")
+	require.NoError(t, err)
+
+	// A Machine lock of machine1 would not see the held Manager lock
+	err = locks.SetSettings(LockPolicyFailFast, DefaultLockWaitTimeout, LockScopeMachine)
+	require.ErrorIs(t, err, ErrLockScopeInUse)
+	_, _, scope := locks.GetSettings()
+	assert.Equal(t, LockScopeManager, scope)
+
+	// Other settings still change
+	require.NoError(t, locks.SetSettings(LockPolicyWait, time.Second, LockScopeManager))
+
+	_, err = locks.Acquire(ctx, "machine1", []string{"bmc"}, LockOwner{JobID: "Job-2"}, LockPolicyFailFast)
+	require.ErrorIs(t, err, ErrMachineLocked)
+
+	release()
+	release()
+	require.NoError(t, locks.SetSettings(LockPolicyFailFast, DefaultLockWaitTimeout, LockScopeMachine))
+}
@@ -139,35 +139,40 @@ func (pv *PlatformValidator) validatePayloadSupport(machine interface{}, action
 
 // validatePayload validates the payload based on the action
 func (j *JobCreateRequest) validatePayload() error {
-	switch j.Action {
+	return validateActionPayload(j.Action, j.Payload)
+}
+
+// validateActionPayload validates the payload of an action
+func validateActionPayload(action ActionType, payload Payload) error {
+	switch action {
 
 	// Manager-related actions
 	case ActionPatchProfile:
-		payload, ok := j.Payload.([]ExecutePatchProfilePayload)
+		payload, ok := payload.([]ExecutePatchProfilePayload)
 		if !ok {
 			return fmt.Errorf("job validation failed: invalid payload format for PatchProfile action. Expected array of ExecutePatchProfilePayload objects. See API documentation for correct structure")
 		}
 		return ValidateProfilePayloads(payload)
 	case ActionPatchManager:
-		payload, ok := j.Payload.([]ExecutePatchManagerPayload)
+		payload, ok := payload.([]ExecutePatchManagerPayload)
 		if !ok {
 			return fmt.Errorf("job validation failed: invalid payload format for PatchManager action. Expected array of ExecutePatchManagerPayload objects. See API documentation for correct structure")
 		}
 		return ValidateManagerPatchPayloads(payload)
 	case ActionPatchFanController:
-		payload, ok := j.Payload.([]ExecutePatchFanControllerPayload)
+		payload, ok := payload.([]ExecutePatchFanControllerPayload)
 		if !ok {
 			return fmt.Errorf("job validation failed: invalid payload format for PatchFanController action. Expected array of ExecutePatchFanControllerPayload objects. See API documentation for correct structure")
 		}
 		return ValidateFanControllerPayloads(payload)
 	case ActionPatchFanZone:
-		payload, ok := j.Payload.([]ExecutePatchFanZonePayload)
+		payload, ok := payload.([]ExecutePatchFanZonePayload)
 		if !ok {
 			return fmt.Errorf("job validation failed: invalid payload format for PatchFanZone action. Expected array of ExecutePatchFanZonePayload objects. See API documentation for correct structure")
 		}
 		return ValidateFanZonePayloads(payload)
 	case ActionPatchPidController:
-		payload, ok := j.Payload.([]ExecutePatchPidControllerPayload)
+		payload, ok := payload.([]ExecutePatchPidControllerPayload)
 		if !ok {
 			return fmt.Errorf("job validation failed: invalid payload format for PatchPidController action. Expected array of ExecutePatchPidControllerPayload objects. See API documentation for correct structure")
 		}
@@ -176,7 +181,7 @@ func (j *JobCreateRequest) validatePayload() error {
 	// Add other actions here
 
 	default:
-		return fmt.Errorf("job validation failed: unsupported action type '%s'. Supported actions: %v. Update the 'action' field in job definition", j.Action, []string{"PatchProfile", "PatchManager", "PatchFanController", "PatchFanZone", "PatchPidController"})
+		return fmt.Errorf("job validation failed: unsupported action type '%s'. Supported actions: %v. Update the 'action' field in job definition", action, []string{"PatchProfile", "PatchManager", "PatchFanController", "PatchFanZone", "PatchPidController"})
 	}
 }
 
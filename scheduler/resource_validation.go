@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	extendprovider "multifish/providers/extend"
+)
+
+// DeepValidationTimeout bounds how long deep validation waits for one machine
+const DeepValidationTimeout = 30 * time.Second
+
+// NewPlatformValidatorWithExecutor creates a platform validator that can also
+// read the managers of the machines for deep validation
+func NewPlatformValidatorWithExecutor(platformMgr JobPlatformManager, machineExecutor MachineActionExecutor) *PlatformValidator {
+	return &PlatformValidator{
+		platformMgr:     platformMgr,
+		machineExecutor: machineExecutor,
+	}
+}
+
+// ValidateMachineResources connects to each machine, in parallel, and checks
+// that the managers, fan controllers, fan zones and PID controllers the payload
+// references exist and that profiles are among the manager's allowable values
+func (pv *PlatformValidator) ValidateMachineResources(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult {
+	results := make([]MachineValidationResult, len(machineIDs))
+
+	var wg sync.WaitGroup
+	for i, machineID := range machineIDs {
+		wg.Add(1)
+		go func(i int, machineID string) {
+			defer wg.Done()
+
+			result := MachineValidationResult{
+				MachineID: machineID,
+				Valid:     true,
+				Errors:    []string{},
+			}
+
+			if pv.machineExecutor == nil {
+				result.Errors = append(result.Errors, "deep validation is not available: the validator cannot connect to machines")
+			} else if machine, err := pv.platformMgr.GetMachine(machineID); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			} else {
+				ctx, cancel := context.WithTimeout(context.Background(), DeepValidationTimeout)
+				result.Errors = append(result.Errors, pv.validateResources(ctx, machine, action, payload)...)
+				cancel()
+			}
+
+			if len(result.Errors) > 0 {
+				result.Valid = false
+				result.Message = "Machine resources do not match the payload"
+			} else {
+				result.Message = "Machine resources match the payload"
+			}
+			results[i] = result
+		}(i, machineID)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// validateResources reads the managers the payload references from the
+// machine and returns an error for every resource that does not match
+func (pv *PlatformValidator) validateResources(ctx context.Context, machine interface{}, action ActionType, payload Payload) []string {
+	managers := make(map[string]interface{})
+	managerErrors := make(map[string]error)
+	manager := func(managerID string) (interface{}, error) {
+		if err, failed := managerErrors[managerID]; failed {
+			return nil, err
+		}
+		if mgr, read := managers[managerID]; read {
+			return mgr, nil
+		}
+		mgr, err := pv.machineExecutor.GetManagerByService(ctx, machine, managerID)
+		if err != nil {
+			err = fmt.Errorf("manager '%s' could not be read from the machine: %w", managerID, err)
+			managerErrors[managerID] = err
+			return nil, err
+		}
+		managers[managerID] = mgr
+		return mgr, nil
+	}
+
+	settings := make(map[string]*extendprovider.OpenBmcFan)
+	fanSettings := func(managerID string) (*extendprovider.OpenBmcFan, error) {
+		if fan, read := settings[managerID]; read {
+			return fan, nil
+		}
+		mgr, err := manager(managerID)
+		if err != nil {
+			return nil, err
+		}
+		fan, err := pv.machineExecutor.GetFanSettings(ctx, mgr)
+		if err != nil {
+			return nil, fmt.Errorf("manager '%s' has no Oem.OpenBmc.Fan settings: %w", managerID, err)
+		}
+		settings[managerID] = fan
+		return fan, nil
+	}
+
+	var errors []string
+	reported := make(map[string]bool)
+	report := func(i int, err error) {
+		// A missing manager is reported once, not for every payload that uses it
+		if reported[err.Error()] {
+			return
+		}
+		reported[err.Error()] = true
+		errors = append(errors, fmt.Sprintf("Payload[%d]: %v", i, err))
+	}
+
+	switch action {
+	case ActionPatchManager:
+		payloads, ok := payload.([]ExecutePatchManagerPayload)
+		if !ok {
+			return []string{fmt.Sprintf("deep validation failed: invalid payload type for PatchManager, expected []ExecutePatchManagerPayload, got %T", payload)}
+		}
+		for i, mp := range payloads {
+			if _, err := manager(mp.ManagerID); err != nil {
+				report(i, err)
+			}
+		}
+
+	case ActionPatchProfile:
+		payloads, ok := payload.([]ExecutePatchProfilePayload)
+		if !ok {
+			return []string{fmt.Sprintf("deep validation failed: invalid payload type for PatchProfile, expected []ExecutePatchProfilePayload, got %T", payload)}
+		}
+		for i, mp := range payloads {
+			fan, err := fanSettings(mp.ManagerID)
+			if err != nil {
+				report(i, err)
+				continue
+			}
+			if len(fan.ProfileAllowableValues) > 0 && !containsString(fan.ProfileAllowableValues, mp.Payload.Profile) {
+				report(i, fmt.Errorf("profile '%s' is not supported by manager '%s'. Allowable values: %v", mp.Payload.Profile, mp.ManagerID, fan.ProfileAllowableValues))
+			}
+		}
+
+	case ActionPatchFanController:
+		payloads, ok := payload.([]ExecutePatchFanControllerPayload)
+		if !ok {
+			return []string{fmt.Sprintf("deep validation failed: invalid payload type for PatchFanController, expected []ExecutePatchFanControllerPayload, got %T", payload)}
+		}
+		for i, fp := range payloads {
+			fan, err := fanSettings(fp.ManagerID)
+			if err != nil {
+				report(i, err)
+				continue
+			}
+			var available []string
+			if fan.FanControllers != nil {
+				if _, exists := fan.FanControllers.Items[fp.FanControllerID]; exists {
+					continue
+				}
+				for id := range fan.FanControllers.Items {
+					available = append(available, id)
+				}
+			}
+			sort.Strings(available)
+			report(i, fmt.Errorf("fan controller '%s' not found on manager '%s'. Available fan controllers: %v", fp.FanControllerID, fp.ManagerID, available))
+		}
+
+	case ActionPatchFanZone:
+		payloads, ok := payload.([]ExecutePatchFanZonePayload)
+		if !ok {
+			return []string{fmt.Sprintf("deep validation failed: invalid payload type for PatchFanZone, expected []ExecutePatchFanZonePayload, got %T", payload)}
+		}
+		for i, fz := range payloads {
+			fan, err := fanSettings(fz.ManagerID)
+			if err != nil {
+				report(i, err)
+				continue
+			}
+			var available []string
+			if fan.FanZones != nil {
+				if _, exists := fan.FanZones.Items[fz.FanZoneID]; exists {
+					continue
+				}
+				for id := range fan.FanZones.Items {
+					available = append(available, id)
+				}
+			}
+			sort.Strings(available)
+			report(i, fmt.Errorf("fan zone '%s' not found on manager '%s'. Available fan zones: %v", fz.FanZoneID, fz.ManagerID, available))
+		}
+
+	case ActionPatchPidController:
+		payloads, ok := payload.([]ExecutePatchPidControllerPayload)
+		if !ok {
+			return []string{fmt.Sprintf("deep validation failed: invalid payload type for PatchPidController, expected []ExecutePatchPidControllerPayload, got %T", payload)}
+		}
+		for i, pc := range payloads {
+			fan, err := fanSettings(pc.ManagerID)
+			if err != nil {
+				report(i, err)
+				continue
+			}
+			var available []string
+			if fan.PidControllers != nil {
+				if _, exists := fan.PidControllers.Items[pc.PidControllerID]; exists {
+					continue
+				}
+				for id := range fan.PidControllers.Items {
+					available = append(available, id)
+				}
+			}
+			sort.Strings(available)
+			report(i, fmt.Errorf("PID controller '%s' not found on manager '%s'. Available PID controllers: %v", pc.PidControllerID, pc.ManagerID, available))
+		}
+
+	default:
+		return []string{fmt.Sprintf("deep validation failed: unsupported action '%s'", action)}
+	}
+
+	return errors
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMachineResources runs the opt-in deep validation of a job request.
+// It connects to the machines, so it runs without js.mu held; requests that
+// fail the basic validation are not checked against the machines.
+func (js *JobService) validateMachineResources(req *JobCreateRequest) []MachineValidationResult {
+	if !req.DeepValidation || js.validator == nil || !req.validateAt(js.clock.Now()).Valid {
+		return nil
+	}
+	if len(req.Steps) > 0 {
+		return validateStepMachines(js.validator.ValidateMachineResources, req.Machines, req.Steps)
+	}
+	return js.validator.ValidateMachineResources(req.Machines, req.Action, req.Payload)
+}
+
+// mergeResourceResults adds the deep validation errors of each machine to its
+// platform validation result. Machines that already failed keep their result.
+func mergeResourceResults(results []MachineValidationResult, resources []MachineValidationResult) {
+	byMachine := make(map[string]MachineValidationResult, len(resources))
+	for _, resource := range resources {
+		byMachine[resource.MachineID] = resource
+	}
+
+	for i := range results {
+		resource, checked := byMachine[results[i].MachineID]
+		if !checked || !results[i].Valid || resource.Valid {
+			continue
+		}
+		results[i].Valid = false
+		results[i].Message = resource.Message
+		results[i].Errors = append(results[i].Errors, resource.Errors...)
+	}
+}
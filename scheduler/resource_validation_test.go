@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	extendprovider "multifish/providers/extend"
+)
+
+func TestPlatformValidator_ValidateMachineResources(t *testing.T) {
+	machine := newFakeFanMachine()
+	machine.fan.ProfileAllowableValues = []string{"Balanced", "Performance"}
+	platform := &MockJobPlatformManager{
+		GetMachineFunc: func(machineID string) (interface{}, error) {
+			if machineID != "machine1" {
+				return nil, errors.New("machine not found")
+			}
+			return "machine1", nil
+		},
+	}
+	validator := NewPlatformValidatorWithExecutor(platform, machine)
+
+	results := validator.ValidateMachineResources([]string{"machine1"}, ActionPatchProfile, []ExecutePatchProfilePayload{
+		{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Performance"}},
+	})
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Valid, "errors: %v", results[0].Errors)
+
+	results = validator.ValidateMachineResources([]string{"machine1"}, ActionPatchProfile, []ExecutePatchProfilePayload{
+		{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Turbo"}},
+		{ManagerID: "bmc2", Payload: extendprovider.PatchProfileType{Profile: "Balanced"}},
+		{ManagerID: "bmc2", Payload: extendprovider.PatchProfileType{Profile: "Balanced"}},
+	})
+	assert.False(t, results[0].Valid)
+	assert.Equal(t, []string{
+		"Payload[0]: profile 'Turbo' is not supported by manager 'bmc'. Allowable values: [Balanced Performance]",
+		"Payload[1]: manager 'bmc2' could not be read from the machine: manager not found",
+	}, results[0].Errors)
+
+	results = validator.ValidateMachineResources([]string{"machine1"}, ActionPatchFanZone, []ExecutePatchFanZonePayload{
+		{ManagerID: "bmc", FanZoneID: "Zone1"},
+		{ManagerID: "bmc", FanZoneID: "Zone2"},
+	})
+	assert.Equal(t, []string{"Payload[1]: fan zone 'Zone2' not found on manager 'bmc'. Available fan zones: [Zone1]"}, results[0].Errors)
+
+	results = validator.ValidateMachineResources([]string{"machine1"}, ActionPatchFanController, []ExecutePatchFanControllerPayload{
+		{ManagerID: "bmc", FanControllerID: "Fan1"},
+	})
+	assert.Equal(t, []string{"Payload[0]: fan controller 'Fan1' not found on manager 'bmc'. Available fan controllers: []"}, results[0].Errors)
+
+	results = validator.ValidateMachineResources([]string{"machine2"}, ActionPatchProfile, []ExecutePatchProfilePayload{
+		{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Balanced"}},
+	})
+	assert.False(t, results[0].Valid)
+	assert.Equal(t, []string{"machine not found"}, results[0].Errors)
+
+	// A validator without a machine executor cannot connect to machines
+	results = NewPlatformValidator(platform).ValidateMachineResources([]string{"machine1"}, ActionPatchProfile, []ExecutePatchProfilePayload{
+		{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Balanced"}},
+	})
+	assert.False(t, results[0].Valid)
+}
+
+func TestJobService_DeepValidation(t *testing.T) {
+	deepValidations := 0
+	validator := &MockJobValidator{
+		ValidateMachineResourcesFunc: func(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult {
+			deepValidations++
+			return []MachineValidationResult{{
+				MachineID: machineIDs[0],
+				Message:   "Machine resources do not match the payload",
+				Errors:    []string{"Payload[0]: profile 'Performance' is not supported by manager 'bmc'"},
+			}}
+		},
+	}
+	service := NewJobService(validator, &MockJobExecutor{})
+	defer service.Stop()
+
+	// Without DeepValidation the machines are not contacted
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 0, deepValidations)
+
+	request := newTestJobRequest()
+	request.DeepValidation = true
+	_, result, err := service.CreateJob(request)
+	require.Error(t, err)
+	assert.Equal(t, 1, deepValidations)
+	assert.False(t, result.Valid)
+	require.Len(t, result.MachineResults, 1)
+	assert.False(t, result.MachineResults[0].Valid)
+	assert.Equal(t, []string{"Payload[0]: profile 'Performance' is not supported by manager 'bmc'"}, result.MachineResults[0].Errors)
+
+	// An update is deep validated only when the patch asks for it
+	_, _, err = service.UpdateJob(job.ID, []byte(`{"Name": "Renamed"}`))
+	require.NoError(t, err)
+	_, result, err = service.UpdateJob(job.ID, []byte(`{"Name": "Renamed again", "DeepValidation": true}`))
+	require.Error(t, err)
+	assert.Equal(t, 2, deepValidations)
+	assert.False(t, result.MachineResults[0].Valid)
+
+	stored, err := service.GetJob(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", stored.Name)
+}
+
+// TestJobService_DeepValidationUsesServiceClock tests that the basic validation
+// in front of the deep validation runs against the service clock
+func TestJobService_DeepValidationUsesServiceClock(t *testing.T) {
+	deepValidations := 0
+	validator := &MockJobValidator{
+		ValidateMachineResourcesFunc: func(machineIDs []string, action ActionType, payload Payload) []MachineValidationResult {
+			deepValidations++
+			return []MachineValidationResult{{MachineID: machineIDs[0], Valid: true}}
+		},
+	}
+	clock := &testClock{now: time.Date(2020, 1, 6, 7, 0, 0, 0, time.UTC)}
+	service := NewJobServiceWithClock(validator, &MockJobExecutor{}, nil, clock)
+	defer service.Stop()
+
+	// The StartTime is in the future for the service but in the past for the wall clock
+	start := clock.Now().Add(time.Hour)
+	request := newTestJobRequest()
+	request.Schedule = Schedule{Type: ScheduleTypeOnce, StartTime: &start}
+	request.DeepValidation = true
+	_, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deepValidations)
+}
@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ErrorClass groups machine execution errors for retry decisions
+type ErrorClass string
+
+const (
+	ErrorClassTimeout     ErrorClass = "Timeout"     // The BMC did not answer in time
+	ErrorClassConnection  ErrorClass = "Connection"  // The BMC could not be reached
+	ErrorClassServerError ErrorClass = "ServerError" // 5xx, 408 and 429 responses
+	ErrorClassClientError ErrorClass = "ClientError" // Other 4xx responses (bad request, not found, ...)
+	ErrorClassOther       ErrorClass = "Other"       // Anything else, e.g. an unknown machine
+)
+
+const (
+	// MaxRetryAttempts bounds RetryPolicy.MaxAttempts
+	MaxRetryAttempts = 10
+	// MaxRetryBackoff bounds the wait between two attempts
+	MaxRetryBackoff = time.Hour
+
+	defaultRetryBackoff    = time.Second
+	defaultRetryMultiplier = 2.0
+)
+
+// defaultRetryOn lists the error classes retried when RetryPolicy.RetryOn is empty
+var defaultRetryOn = []ErrorClass{ErrorClassTimeout, ErrorClassConnection, ErrorClassServerError}
+
+// RetryPolicy controls how often a failed machine is retried within one execution
+type RetryPolicy struct {
+	MaxAttempts    int          `json:"MaxAttempts"`              // Attempts per machine including the first, 1-10
+	InitialBackoff string       `json:"InitialBackoff,omitempty"` // Wait before the second attempt, e.g. "2s" (default "1s")
+	Multiplier     float64      `json:"Multiplier,omitempty"`     // Backoff growth per attempt, >= 1 (default 2)
+	RetryOn        []ErrorClass `json:"RetryOn,omitempty"`        // Retryable error classes (default Timeout, Connection, ServerError)
+}
+
+// StatusError is an error returned by a BMC request together with its HTTP status code
+type StatusError struct {
+	StatusCode int
+	Err        error
+}
+
+// NewStatusError wraps err with the HTTP status code of the failed request
+func NewStatusError(statusCode int, err error) *StatusError {
+	return &StatusError{StatusCode: statusCode, Err: err}
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError returns the error class of a machine execution error
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassConnection
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.StatusCode == http.StatusRequestTimeout, statusErr.StatusCode == http.StatusGatewayTimeout:
+			return ErrorClassTimeout
+		case statusErr.StatusCode == http.StatusTooManyRequests, statusErr.StatusCode >= 500:
+			return ErrorClassServerError
+		case statusErr.StatusCode >= 400:
+			return ErrorClassClientError
+		}
+	}
+
+	return ErrorClassOther
+}
+
+// Validate validates the retry policy and returns all problems found
+func (p *RetryPolicy) Validate() []string {
+	var errors []string
+
+	if p.MaxAttempts < 1 || p.MaxAttempts > MaxRetryAttempts {
+		errors = append(errors, fmt.Sprintf("RetryPolicy.MaxAttempts must be between 1 and %d, got %d", MaxRetryAttempts, p.MaxAttempts))
+	}
+
+	if p.InitialBackoff != "" {
+		backoff, err := time.ParseDuration(p.InitialBackoff)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("invalid RetryPolicy.InitialBackoff '%s': %v. Use a Go duration such as '500ms', '2s' or '1m'", p.InitialBackoff, err))
+		} else if backoff < 0 || backoff > MaxRetryBackoff {
+			errors = append(errors, fmt.Sprintf("RetryPolicy.InitialBackoff must be between 0 and %s, got %s", MaxRetryBackoff, p.InitialBackoff))
+		}
+	}
+
+	if p.Multiplier != 0 && p.Multiplier < 1 {
+		errors = append(errors, fmt.Sprintf("RetryPolicy.Multiplier must be at least 1, got %g", p.Multiplier))
+	}
+
+	validClasses := map[ErrorClass]bool{
+		ErrorClassTimeout: true, ErrorClassConnection: true, ErrorClassServerError: true,
+		ErrorClassClientError: true, ErrorClassOther: true,
+	}
+	for _, class := range p.RetryOn {
+		if !validClasses[class] {
+			errors = append(errors, fmt.Sprintf("invalid RetryPolicy.RetryOn class: %s (must be 'Timeout', 'Connection', 'ServerError', 'ClientError' or 'Other')", class))
+		}
+	}
+
+	return errors
+}
+
+// maxAttempts returns the number of attempts per machine; a nil policy tries once
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// retryable reports whether an error of the given class is retried
+func (p *RetryPolicy) retryable(class ErrorClass) bool {
+	retryOn := defaultRetryOn
+	if p != nil && len(p.RetryOn) > 0 {
+		retryOn = p.RetryOn
+	}
+	for _, c := range retryOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait after the given failed attempt (1-based)
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := defaultRetryBackoff
+	if p.InitialBackoff != "" {
+		if parsed, err := time.ParseDuration(p.InitialBackoff); err == nil {
+			backoff = parsed
+		}
+	}
+
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = defaultRetryMultiplier
+	}
+
+	wait := float64(backoff)
+	for i := 1; i < attempt; i++ {
+		wait *= multiplier
+		if wait >= float64(MaxRetryBackoff) {
+			return MaxRetryBackoff
+		}
+	}
+
+	return time.Duration(wait)
+}
@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	extendprovider "multifish/providers/extend"
+)
+
+// newRetryTestJob returns a PatchProfile job on one machine with the given retry policy
+func newRetryTestJob(retry *RetryPolicy) *Job {
+	return &Job{
+		ID:       "retry-job",
+		Machines: []string{"machine1"},
+		Action:   ActionPatchProfile,
+		Payload: []ExecutePatchProfilePayload{
+			{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Performance"}},
+		},
+		RetryPolicy: retry,
+	}
+}
+
+// failingActionExecutor fails PatchProfile with the given errors, then succeeds
+func failingActionExecutor(failures ...error) (*MockActionExecutor, *int) {
+	calls := 0
+	return &MockActionExecutor{
+		ExecutePatchProfileFunc: func(machine interface{}, payloads Payload) error {
+			calls++
+			if calls <= len(failures) {
+				return failures[calls-1]
+			}
+			return nil
+		},
+	}, &calls
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorClass
+	}{
+		{"Deadline", context.DeadlineExceeded, ErrorClassTimeout},
+		{"Wrapped deadline", fmt.Errorf("failed to patch: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{"Network timeout", &net.DNSError{IsTimeout: true}, ErrorClassTimeout},
+		{"Connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorClassConnection},
+		{"Internal server error", NewStatusError(http.StatusInternalServerError, errors.New("boom")), ErrorClassServerError},
+		{"Wrapped service unavailable", fmt.Errorf("patch: %w", NewStatusError(http.StatusServiceUnavailable, errors.New("busy"))), ErrorClassServerError},
+		{"Too many requests", NewStatusError(http.StatusTooManyRequests, errors.New("slow down")), ErrorClassServerError},
+		{"Gateway timeout", NewStatusError(http.StatusGatewayTimeout, errors.New("timeout")), ErrorClassTimeout},
+		{"Bad request", NewStatusError(http.StatusBadRequest, errors.New("bad profile")), ErrorClassClientError},
+		{"Not found", NewStatusError(http.StatusNotFound, errors.New("no manager")), ErrorClassClientError},
+		{"Plain error", errors.New("machine not found"), ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        RetryPolicy
+		expectedValid bool
+	}{
+		{"Minimal", RetryPolicy{MaxAttempts: 3}, true},
+		{"Full", RetryPolicy{MaxAttempts: 5, InitialBackoff: "500ms", Multiplier: 1.5, RetryOn: []ErrorClass{ErrorClassTimeout, ErrorClassOther}}, true},
+		{"Zero attempts", RetryPolicy{MaxAttempts: 0}, false},
+		{"Too many attempts", RetryPolicy{MaxAttempts: MaxRetryAttempts + 1}, false},
+		{"Invalid backoff", RetryPolicy{MaxAttempts: 3, InitialBackoff: "soon"}, false},
+		{"Negative backoff", RetryPolicy{MaxAttempts: 3, InitialBackoff: "-1s"}, false},
+		{"Backoff too long", RetryPolicy{MaxAttempts: 3, InitialBackoff: "2h"}, false},
+		{"Shrinking backoff", RetryPolicy{MaxAttempts: 3, Multiplier: 0.5}, false},
+		{"Unknown class", RetryPolicy{MaxAttempts: 3, RetryOn: []ErrorClass{"Flaky"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.policy.Validate()
+			assert.Equal(t, tt.expectedValid, len(errs) == 0, "errors: %v", errs)
+		})
+	}
+
+	// Invalid policies fail job validation as action errors
+	request := newTestJobRequest()
+	request.RetryPolicy = &RetryPolicy{MaxAttempts: 0}
+	result := request.Validate()
+	assert.False(t, result.Valid)
+	assert.False(t, result.ActionValid)
+	assert.NotEmpty(t, result.ActionErrors)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 5, InitialBackoff: "2s", Multiplier: 3}
+	assert.Equal(t, 2*time.Second, policy.backoff(1))
+	assert.Equal(t, 6*time.Second, policy.backoff(2))
+	assert.Equal(t, 18*time.Second, policy.backoff(3))
+
+	defaults := &RetryPolicy{MaxAttempts: 3}
+	assert.Equal(t, time.Second, defaults.backoff(1))
+	assert.Equal(t, 2*time.Second, defaults.backoff(2))
+
+	capped := &RetryPolicy{MaxAttempts: 10, InitialBackoff: "1h", Multiplier: 10}
+	assert.Equal(t, MaxRetryBackoff, capped.backoff(4))
+
+	var none *RetryPolicy
+	assert.Equal(t, 1, none.maxAttempts())
+}
+
+func TestPlatformExecutor_RetriesTransientErrors(t *testing.T) {
+	actionExecutor, calls := failingActionExecutor(
+		NewStatusError(http.StatusServiceUnavailable, errors.New("busy")),
+		context.DeadlineExceeded,
+	)
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actionExecutor)
+
+	history := executor.ExecuteJob(context.Background(), newRetryTestJob(&RetryPolicy{MaxAttempts: 3, InitialBackoff: "1ms"}))
+
+	require.Len(t, history.Results, 1)
+	result := history.Results[0]
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, *calls)
+	require.Len(t, result.Attempts, 3)
+	assert.Equal(t, ErrorClassServerError, result.Attempts[0].ErrorClass)
+	assert.True(t, result.Attempts[0].Retryable)
+	assert.Equal(t, ErrorClassTimeout, result.Attempts[1].ErrorClass)
+	assert.True(t, result.Attempts[2].Success)
+	assert.Equal(t, 3, result.Attempts[2].Attempt)
+	assert.Contains(t, result.Message, "after 3 attempts")
+}
+
+func TestPlatformExecutor_DoesNotRetryClientErrors(t *testing.T) {
+	actionExecutor, calls := failingActionExecutor(
+		NewStatusError(http.StatusBadRequest, errors.New("profile value 'Turbo' is not allowed")),
+	)
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actionExecutor)
+
+	history := executor.ExecuteJob(context.Background(), newRetryTestJob(&RetryPolicy{MaxAttempts: 3, InitialBackoff: "1ms"}))
+
+	result := history.Results[0]
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, *calls)
+	require.Len(t, result.Attempts, 1)
+	assert.Equal(t, ErrorClassClientError, result.Attempts[0].ErrorClass)
+	assert.False(t, result.Attempts[0].Retryable)
+}
+
+func TestPlatformExecutor_RetriesExhausted(t *testing.T) {
+	timeout := NewStatusError(http.StatusGatewayTimeout, errors.New("timeout"))
+	actionExecutor, calls := failingActionExecutor(timeout, timeout, timeout, timeout)
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actionExecutor)
+
+	history := executor.ExecuteJob(context.Background(), newRetryTestJob(&RetryPolicy{MaxAttempts: 2, InitialBackoff: "1ms"}))
+
+	result := history.Results[0]
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, *calls)
+	assert.Len(t, result.Attempts, 2)
+	assert.Contains(t, result.Message, "after 2 attempts")
+
+	// Without a policy each machine is tried once
+	actionExecutor, calls = failingActionExecutor(timeout)
+	executor = NewPlatformExecutor(&MockJobPlatformManager{}, actionExecutor)
+	history = executor.ExecuteJob(context.Background(), newRetryTestJob(nil))
+	assert.False(t, history.Results[0].Success)
+	assert.Equal(t, 1, *calls)
+	assert.Len(t, history.Results[0].Attempts, 1)
+}
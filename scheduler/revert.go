@@ -0,0 +1,266 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	extendprovider "multifish/providers/extend"
+)
+
+// revertableActions are the actions whose changes RevertAfter can restore
+var revertableActions = []ActionType{ActionPatchProfile, ActionPatchFanController, ActionPatchFanZone, ActionPatchPidController}
+
+// PendingRevert holds the values captured before a run of a RevertAfter job,
+// restored on each machine once RevertTime is reached
+type PendingRevert struct {
+	RevertTime time.Time       `json:"RevertTime"`
+	Executions []string        `json:"Executions"` // IDs of the executions whose changes are restored
+	Machines   []MachineRevert `json:"Machines"`
+}
+
+// MachineRevert is the list of steps that restores the previous values of a machine
+type MachineRevert struct {
+	MachineID string    `json:"MachineId"`
+	Steps     []JobStep `json:"Steps"`
+}
+
+// ParseRevertAfter parses the RevertAfter of a job: either a duration after
+// the run finished, e.g. "9h", or a time of day in the schedule's time zone,
+// e.g. "18:00:00". Exactly one of the returned duration and clock is set.
+func ParseRevertAfter(value string) (time.Duration, time.Time, error) {
+	if clock, err := time.Parse("15:04:05", value); err == nil {
+		return 0, clock, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid RevertAfter '%s': use a Go duration such as '30m' or '9h', or a time of day such as '18:00:00'", value)
+	}
+	if duration < time.Second {
+		return 0, time.Time{}, fmt.Errorf("RevertAfter must be at least 1s, got %s", value)
+	}
+	return duration, time.Time{}, nil
+}
+
+// validateRevert validates RevertAfter and checks that every action of the job
+// can be reverted
+func (j *JobCreateRequest) validateRevert() []string {
+	if j.RevertAfter == "" {
+		return nil
+	}
+
+	var errors []string
+	if _, _, err := ParseRevertAfter(j.RevertAfter); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	actions := []ActionType{j.Action}
+	if len(j.Steps) > 0 {
+		actions = actions[:0]
+		for _, step := range j.Steps {
+			actions = append(actions, step.Action)
+		}
+	}
+	for _, action := range actions {
+		if !isRevertableAction(action) {
+			errors = append(errors, fmt.Sprintf("RevertAfter cannot restore %s changes. Supported actions are: %v", action, revertableActions))
+		}
+	}
+
+	return errors
+}
+
+func isRevertableAction(action ActionType) bool {
+	for _, revertable := range revertableActions {
+		if action == revertable {
+			return true
+		}
+	}
+	return false
+}
+
+// CapturePrevious reads the current values of everything the action is about
+// to patch on the machine and returns them as a payload of the same action.
+// Only the fields set in the action's payload are captured.
+func (dae *DefaultActionExecutor) CapturePrevious(ctx context.Context, machine interface{}, action ActionType, payload Payload) (Payload, error) {
+	fanSettings := dae.fanSettingsReader(ctx, machine)
+
+	switch action {
+	case ActionPatchProfile:
+		payloads, ok := payload.([]ExecutePatchProfilePayload)
+		if !ok {
+			return nil, fmt.Errorf("capture failed: invalid payload type for PatchProfile action: expected []ExecutePatchProfilePayload, got %T", payload)
+		}
+		previous := make([]ExecutePatchProfilePayload, 0, len(payloads))
+		for _, mp := range payloads {
+			fan, err := fanSettings(mp.ManagerID)
+			if err != nil {
+				return nil, err
+			}
+			previous = append(previous, ExecutePatchProfilePayload{
+				ManagerID: mp.ManagerID,
+				Payload:   extendprovider.PatchProfileType{Profile: fan.Profile},
+			})
+		}
+		return previous, nil
+
+	case ActionPatchFanController:
+		payloads, ok := payload.([]ExecutePatchFanControllerPayload)
+		if !ok {
+			return nil, fmt.Errorf("capture failed: invalid payload type for PatchFanController action: expected []ExecutePatchFanControllerPayload, got %T", payload)
+		}
+		previous := make([]ExecutePatchFanControllerPayload, 0, len(payloads))
+		for _, fp := range payloads {
+			fan, err := fanSettings(fp.ManagerID)
+			if err != nil {
+				return nil, err
+			}
+			var current *extendprovider.FanController
+			if fan.FanControllers != nil {
+				current = fan.FanControllers.Items[fp.FanControllerID]
+			}
+			if current == nil {
+				return nil, fmt.Errorf("fan controller '%s' not found on manager '%s', its previous values cannot be captured", fp.FanControllerID, fp.ManagerID)
+			}
+			entry := ExecutePatchFanControllerPayload{ManagerID: fp.ManagerID, FanControllerID: fp.FanControllerID}
+			if err := previousValues(current, fp.Payload, &entry.Payload); err != nil {
+				return nil, err
+			}
+			previous = append(previous, entry)
+		}
+		return previous, nil
+
+	case ActionPatchFanZone:
+		payloads, ok := payload.([]ExecutePatchFanZonePayload)
+		if !ok {
+			return nil, fmt.Errorf("capture failed: invalid payload type for PatchFanZone action: expected []ExecutePatchFanZonePayload, got %T", payload)
+		}
+		previous := make([]ExecutePatchFanZonePayload, 0, len(payloads))
+		for _, fz := range payloads {
+			fan, err := fanSettings(fz.ManagerID)
+			if err != nil {
+				return nil, err
+			}
+			var current extendprovider.FanZone
+			found := false
+			if fan.FanZones != nil {
+				current, found = fan.FanZones.Items[fz.FanZoneID]
+			}
+			if !found {
+				return nil, fmt.Errorf("fan zone '%s' not found on manager '%s', its previous values cannot be captured", fz.FanZoneID, fz.ManagerID)
+			}
+			entry := ExecutePatchFanZonePayload{ManagerID: fz.ManagerID, FanZoneID: fz.FanZoneID}
+			if err := previousValues(current, fz.Payload, &entry.Payload); err != nil {
+				return nil, err
+			}
+			previous = append(previous, entry)
+		}
+		return previous, nil
+
+	case ActionPatchPidController:
+		payloads, ok := payload.([]ExecutePatchPidControllerPayload)
+		if !ok {
+			return nil, fmt.Errorf("capture failed: invalid payload type for PatchPidController action: expected []ExecutePatchPidControllerPayload, got %T", payload)
+		}
+		previous := make([]ExecutePatchPidControllerPayload, 0, len(payloads))
+		for _, pc := range payloads {
+			fan, err := fanSettings(pc.ManagerID)
+			if err != nil {
+				return nil, err
+			}
+			var current *extendprovider.PidController
+			if fan.PidControllers != nil {
+				current = fan.PidControllers.Items[pc.PidControllerID]
+			}
+			if current == nil {
+				return nil, fmt.Errorf("PID controller '%s' not found on manager '%s', its previous values cannot be captured", pc.PidControllerID, pc.ManagerID)
+			}
+			entry := ExecutePatchPidControllerPayload{ManagerID: pc.ManagerID, PidControllerID: pc.PidControllerID}
+			if err := previousValues(current, pc.Payload, &entry.Payload); err != nil {
+				return nil, err
+			}
+			previous = append(previous, entry)
+		}
+		return previous, nil
+
+	default:
+		return nil, fmt.Errorf("%s changes cannot be reverted. Supported actions are: %v", action, revertableActions)
+	}
+}
+
+// previousValues copies the fields of current that patch sets into restore,
+// matching them by their JSON names
+func previousValues(current interface{}, patch interface{}, restore interface{}) error {
+	patched, err := jsonFields(patch)
+	if err != nil {
+		return fmt.Errorf("failed to read patch fields: %w", err)
+	}
+	values, err := jsonFields(current)
+	if err != nil {
+		return fmt.Errorf("failed to read current values: %w", err)
+	}
+
+	previous := make(map[string]json.RawMessage, len(patched))
+	for field := range patched {
+		if value, exists := values[field]; exists {
+			previous[field] = value
+		}
+	}
+
+	data, err := json.Marshal(previous)
+	if err != nil {
+		return fmt.Errorf("failed to capture previous values: %w", err)
+	}
+	return json.Unmarshal(data, restore)
+}
+
+// executeAction executes an action on a machine. For a RevertAfter job the
+// values the action changes are captured first and returned as the step that
+// restores them; a machine whose values cannot be captured is not patched.
+// For a Verify job the applied values are read back after patching.
+func (pe *PlatformExecutor) executeAction(ctx context.Context, job *Job, machineID string, action ActionType, payload Payload, name string) (MachineExecutionResult, *JobStep) {
+	if job.RevertAfter == "" {
+		result := pe.executeMachine(ctx, job.ID, machineID, action, payload, job.RetryPolicy)
+		if job.Verify && result.Success {
+			pe.verifyAction(ctx, job, machineID, action, payload, &result)
+		}
+		return result, nil
+	}
+
+	previous, err := pe.capturePrevious(ctx, machineID, action, payload)
+	if err != nil {
+		now := time.Now()
+		return MachineExecutionResult{
+			MachineID: machineID,
+			Success:   false,
+			Status:    JobStatusFailed,
+			Message:   fmt.Sprintf("Not executed, the values %s changes could not be captured for RevertAfter", action),
+			Error:     err.Error(),
+			StartTime: now,
+			EndTime:   now,
+			Duration:  "0s",
+		}, nil
+	}
+
+	result := pe.executeMachine(ctx, job.ID, machineID, action, payload, job.RetryPolicy)
+	if job.Verify && result.Success {
+		pe.verifyAction(ctx, job, machineID, action, payload, &result)
+	}
+	return result, &JobStep{Name: "Revert " + name, Action: action, Payload: previous}
+}
+
+// capturePrevious looks up the machine and captures the values the action changes
+func (pe *PlatformExecutor) capturePrevious(ctx context.Context, machineID string, action ActionType, payload Payload) (Payload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	machine, err := pe.platformMgr.GetMachine(machineID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine: %w", err)
+	}
+
+	return pe.actionExecutor.CapturePrevious(ctx, machine, action, payload)
+}
@@ -0,0 +1,409 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	extendprovider "multifish/providers/extend"
+	redfishprovider "multifish/providers/redfish"
+)
+
+// fakeFanMachine serves the fan settings of a single manager to the
+// DefaultActionExecutor and counts how often they are read
+type fakeFanMachine struct {
+	fan            *extendprovider.OpenBmcFan
+	identification string
+	reads          int
+}
+
+func (f *fakeFanMachine) GetManagerByService(ctx context.Context, machine interface{}, managerID string) (interface{}, error) {
+	if managerID != "bmc" {
+		return nil, errors.New("manager not found")
+	}
+	return managerID, nil
+}
+
+func (f *fakeFanMachine) PatchManager(ctx context.Context, manager interface{}, patch interface{}) error {
+	return nil
+}
+
+func (f *fakeFanMachine) PatchProfile(ctx context.Context, manager interface{}, patch extendprovider.PatchProfileType) error {
+	return nil
+}
+
+func (f *fakeFanMachine) PatchFanController(ctx context.Context, manager interface{}, fanControllerID string, patch *extendprovider.PatchFanControllerType) error {
+	return nil
+}
+
+func (f *fakeFanMachine) PatchFanZone(ctx context.Context, manager interface{}, fanZoneID string, patch *extendprovider.PatchFanZoneType) error {
+	return nil
+}
+
+func (f *fakeFanMachine) PatchPidController(ctx context.Context, manager interface{}, pidControllerID string, patch *extendprovider.PatchPidControllerType) error {
+	return nil
+}
+
+func (f *fakeFanMachine) GetFanSettings(ctx context.Context, manager interface{}) (*extendprovider.OpenBmcFan, error) {
+	f.reads++
+	return f.fan, nil
+}
+
+func (f *fakeFanMachine) GetManagerSettings(ctx context.Context, manager interface{}) (*redfishprovider.PatchManagerType, error) {
+	return &redfishprovider.PatchManagerType{ServiceIdentification: f.identification}, nil
+}
+
+func newFakeFanMachine() *fakeFanMachine {
+	return &fakeFanMachine{
+		fan: &extendprovider.OpenBmcFan{
+			Profile: "Balanced",
+			FanZones: &extendprovider.FanZones{
+				Items: map[string]extendprovider.FanZone{
+					"Zone1": {FailSafePercent: 40, MinThermalOutput: 25},
+				},
+			},
+		},
+	}
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
+
+func TestParseRevertAfter(t *testing.T) {
+	duration, clock, err := ParseRevertAfter("9h")
+	require.NoError(t, err)
+	assert.Equal(t, 9*time.Hour, duration)
+	assert.True(t, clock.IsZero())
+
+	duration, clock, err = ParseRevertAfter("18:30:00")
+	require.NoError(t, err)
+	assert.Zero(t, duration)
+	assert.Equal(t, 18, clock.Hour())
+	assert.Equal(t, 30, clock.Minute())
+
+	for _, invalid := range []string{"soon", "500ms", "-1h", "25:00:00"} {
+		_, _, err := ParseRevertAfter(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestJobCreateRequest_ValidateRevert(t *testing.T) {
+	request := newTestJobRequest()
+	request.RevertAfter = "2h"
+	result := request.Validate()
+	assert.True(t, result.Valid, "errors: %v", result.ActionErrors)
+
+	request.RevertAfter = "later"
+	result = request.Validate()
+	assert.False(t, result.ActionValid)
+	require.Len(t, result.ActionErrors, 1)
+	assert.Contains(t, result.ActionErrors[0], "invalid RevertAfter")
+
+	// Manager patches cannot be captured and restored
+	workflow := newTestJobRequest()
+	workflow.Action = ""
+	workflow.Payload = nil
+	workflow.RevertAfter = "2h"
+	workflow.Steps = []JobStep{profileStep("Custom"), {Action: ActionPatchManager}}
+	assert.Contains(t, workflow.validateRevert(), "RevertAfter cannot restore PatchManager changes. Supported actions are: [PatchProfile PatchFanController PatchFanZone PatchPidController]")
+}
+
+func TestDefaultActionExecutor_CapturePrevious(t *testing.T) {
+	machine := newFakeFanMachine()
+	executor := NewDefaultActionExecutor(machine)
+	ctx := context.Background()
+
+	previous, err := executor.CapturePrevious(ctx, "machine1", ActionPatchProfile, []ExecutePatchProfilePayload{
+		{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Performance"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ExecutePatchProfilePayload{
+		{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Balanced"}},
+	}, previous)
+
+	// Only the fields set by the patch are captured, settings are read once per manager
+	machine.reads = 0
+	previous, err = executor.CapturePrevious(ctx, "machine1", ActionPatchFanZone, []ExecutePatchFanZonePayload{
+		{ManagerID: "bmc", FanZoneID: "Zone1", Payload: extendprovider.PatchFanZoneType{FailSafePercent: float64Ptr(80)}},
+		{ManagerID: "bmc", FanZoneID: "Zone1", Payload: extendprovider.PatchFanZoneType{MinThermalOutput: float64Ptr(50)}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, machine.reads)
+	zones := previous.([]ExecutePatchFanZonePayload)
+	require.Len(t, zones, 2)
+	assert.Equal(t, float64Ptr(40), zones[0].Payload.FailSafePercent)
+	assert.Nil(t, zones[0].Payload.MinThermalOutput)
+	assert.Nil(t, zones[1].Payload.FailSafePercent)
+	assert.Equal(t, float64Ptr(25), zones[1].Payload.MinThermalOutput)
+
+	_, err = executor.CapturePrevious(ctx, "machine1", ActionPatchFanZone, []ExecutePatchFanZonePayload{
+		{ManagerID: "bmc", FanZoneID: "Zone9", Payload: extendprovider.PatchFanZoneType{FailSafePercent: float64Ptr(80)}},
+	})
+	assert.ErrorContains(t, err, "fan zone 'Zone9' not found on manager 'bmc'")
+
+	_, err = executor.CapturePrevious(ctx, "machine1", ActionPatchManager, []ExecutePatchManagerPayload{})
+	assert.ErrorContains(t, err, "PatchManager changes cannot be reverted")
+}
+
+func TestPlatformExecutor_CapturesRevertSteps(t *testing.T) {
+	var patched []string
+	actions := &MockActionExecutor{
+		ExecutePatchProfileFunc: func(machine interface{}, payloads Payload) error {
+			patched = append(patched, payloads.([]ExecutePatchProfilePayload)[0].Payload.Profile)
+			return nil
+		},
+		CapturePreviousFunc: func(machine interface{}, action ActionType, payload Payload) (Payload, error) {
+			if action == ActionPatchProfile {
+				return []ExecutePatchProfilePayload{{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Balanced"}}}, nil
+			}
+			return payload, nil
+		},
+	}
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actions)
+
+	job := newWorkflowTestJob(profileStep("Custom"), fanZoneStep())
+	job.RevertAfter = "1h"
+
+	history := executor.ExecuteJob(context.Background(), job)
+	require.Len(t, history.Results, 1)
+	result := history.Results[0]
+	assert.True(t, result.Success)
+	assert.Equal(t, []string{"Custom"}, patched)
+
+	// Steps are undone in reverse order
+	require.Len(t, result.Revert, 2)
+	assert.Equal(t, ActionPatchFanZone, result.Revert[0].Action)
+	assert.Equal(t, ActionPatchProfile, result.Revert[1].Action)
+	assert.Equal(t, "Balanced", result.Revert[1].Payload.([]ExecutePatchProfilePayload)[0].Payload.Profile)
+
+	// Jobs without RevertAfter capture nothing
+	job.RevertAfter = ""
+	history = executor.ExecuteJob(context.Background(), job)
+	assert.Empty(t, history.Results[0].Revert)
+}
+
+func TestPlatformExecutor_SkipsPatchWhenCaptureFails(t *testing.T) {
+	patched := false
+	actions := &MockActionExecutor{
+		ExecutePatchProfileFunc: func(machine interface{}, payloads Payload) error {
+			patched = true
+			return nil
+		},
+		CapturePreviousFunc: func(machine interface{}, action ActionType, payload Payload) (Payload, error) {
+			return nil, errors.New("fan settings unavailable")
+		},
+	}
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actions)
+
+	job := newWorkflowTestJob()
+	job.Steps = nil
+	job.Action = ActionPatchProfile
+	job.Payload = profileStep("Custom").Payload
+	job.RevertAfter = "1h"
+
+	history := executor.ExecuteJob(context.Background(), job)
+	require.Len(t, history.Results, 1)
+	assert.False(t, history.Results[0].Success)
+	assert.Equal(t, JobStatusFailed, history.Results[0].Status)
+	assert.Contains(t, history.Results[0].Error, "fan settings unavailable")
+	assert.Empty(t, history.Results[0].Revert)
+	assert.False(t, patched, "a machine whose values cannot be captured is not patched")
+}
+
+// profileRecorder patches and captures profiles of machines in memory.
+// Patches of machines marked down fail.
+type profileRecorder struct {
+	mu       sync.Mutex
+	profiles map[string]string
+	down     map[string]bool
+}
+
+func (p *profileRecorder) actions() *MockActionExecutor {
+	return &MockActionExecutor{
+		ExecutePatchProfileFunc: func(machine interface{}, payloads Payload) error {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if p.down[machine.(string)] {
+				return NewStatusError(503, errors.New("BMC unavailable"))
+			}
+			p.profiles[machine.(string)] = payloads.([]ExecutePatchProfilePayload)[0].Payload.Profile
+			return nil
+		},
+		CapturePreviousFunc: func(machine interface{}, action ActionType, payload Payload) (Payload, error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			return []ExecutePatchProfilePayload{{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: p.profiles[machine.(string)]}}}, nil
+		},
+	}
+}
+
+func (p *profileRecorder) profile(machineID string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.profiles[machineID]
+}
+
+func (p *profileRecorder) setDown(machineID string, down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down == nil {
+		p.down = make(map[string]bool)
+	}
+	p.down[machineID] = down
+}
+
+func TestJobService_RevertsWhenWindowCloses(t *testing.T) {
+	recorder := &profileRecorder{profiles: map[string]string{"machine1": "Balanced", "machine2": "Quiet"}}
+	platform := &MockJobPlatformManager{GetMachineFunc: func(machineID string) (interface{}, error) { return machineID, nil }}
+	service := NewJobService(&MockJobValidator{}, NewPlatformExecutor(platform, recorder.actions()))
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Machines = []string{"machine1", "machine2"}
+	request.Payload = profileStep("Performance").Payload
+	request.RevertAfter = "1h"
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	runJobOnce(service, job)
+	assert.Equal(t, "Performance", recorder.profile("machine1"))
+
+	service.mu.RLock()
+	require.NotNil(t, job.PendingRevert)
+	first := job.PendingRevert.Executions[0]
+	assert.WithinDuration(t, time.Now().Add(time.Hour), job.PendingRevert.RevertTime, time.Minute)
+	assert.Len(t, job.PendingRevert.Machines, 2)
+	service.mu.RUnlock()
+
+	// A second run inside the window keeps the values from before the first one
+	recorder.mu.Lock()
+	recorder.profiles["machine1"] = "Custom"
+	recorder.mu.Unlock()
+	runJobOnce(service, job)
+	service.mu.Lock()
+	reverted := job.PendingRevert.Executions
+	require.Len(t, reverted, 2)
+	assert.Equal(t, first, reverted[0])
+	job.PendingRevert.RevertTime = time.Now().Add(-time.Second)
+	service.reverts.setAt(job, &job.PendingRevert.RevertTime)
+	service.mu.Unlock()
+
+	service.checkAndExecuteJobs()
+
+	executions := waitForExecutions(t, service, job.ID, 3)
+	revert := executions[2]
+	assert.Equal(t, JobStatusCompleted, revert.Status)
+	assert.Equal(t, reverted, revert.RevertOf)
+	assert.Equal(t, "Balanced", recorder.profile("machine1"))
+	assert.Equal(t, "Quiet", recorder.profile("machine2"))
+
+	service.mu.RLock()
+	assert.Nil(t, job.PendingRevert)
+	service.mu.RUnlock()
+}
+
+func TestJobService_RevertKeepsFailedMachinesPending(t *testing.T) {
+	recorder := &profileRecorder{profiles: map[string]string{"machine1": "Balanced", "machine2": "Quiet"}}
+	platform := &MockJobPlatformManager{GetMachineFunc: func(machineID string) (interface{}, error) { return machineID, nil }}
+	service := NewJobService(&MockJobValidator{}, NewPlatformExecutor(platform, recorder.actions()))
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Machines = []string{"machine1", "machine2"}
+	request.Payload = profileStep("Performance").Payload
+	request.RevertAfter = "1h"
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+	runJobOnce(service, job)
+
+	dueNow := func() {
+		service.mu.Lock()
+		job.PendingRevert.RevertTime = time.Now().Add(-time.Second)
+		service.reverts.setAt(job, &job.PendingRevert.RevertTime)
+		service.mu.Unlock()
+		service.checkAndExecuteJobs()
+	}
+
+	// machine2's BMC is down when the window closes
+	recorder.setDown("machine2", true)
+	dueNow()
+	executions := waitForExecutions(t, service, job.ID, 2)
+	assert.Equal(t, JobStatusFailed, executions[1].Status)
+	assert.Equal(t, "Balanced", recorder.profile("machine1"))
+	assert.Equal(t, "Performance", recorder.profile("machine2"))
+
+	service.mu.RLock()
+	require.NotNil(t, job.PendingRevert, "the values of the failed machine are kept")
+	require.Len(t, job.PendingRevert.Machines, 1)
+	assert.Equal(t, "machine2", job.PendingRevert.Machines[0].MachineID)
+	assert.WithinDuration(t, time.Now().Add(revertRetryDelay), job.PendingRevert.RevertTime, 5*time.Second)
+	service.mu.RUnlock()
+
+	// The retry restores it once the BMC is back
+	recorder.setDown("machine2", false)
+	dueNow()
+	executions = waitForExecutions(t, service, job.ID, 3)
+	assert.Equal(t, JobStatusCompleted, executions[2].Status)
+	assert.Equal(t, "Quiet", recorder.profile("machine2"))
+
+	service.mu.RLock()
+	assert.Nil(t, job.PendingRevert)
+	service.mu.RUnlock()
+}
+
+func TestJobService_DeleteRefusedWhileRevertPending(t *testing.T) {
+	recorder := &profileRecorder{profiles: map[string]string{"machine1": "Balanced"}}
+	platform := &MockJobPlatformManager{GetMachineFunc: func(machineID string) (interface{}, error) { return machineID, nil }}
+	service := NewJobService(&MockJobValidator{}, NewPlatformExecutor(platform, recorder.actions()))
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Payload = profileStep("Performance").Payload
+	request.RevertAfter = "1h"
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+	runJobOnce(service, job)
+
+	// The captured values would be lost with the job
+	assert.ErrorIs(t, service.DeleteJob(job.ID), ErrJobHasPendingRevert)
+	_, err = service.GetJob(job.ID)
+	require.NoError(t, err)
+	service.mu.RLock()
+	require.NotNil(t, job.PendingRevert)
+	assert.Len(t, job.PendingRevert.Machines, 1)
+	service.mu.RUnlock()
+
+	require.NoError(t, service.DeleteJobDiscardingRevert(job.ID))
+	_, err = service.GetJob(job.ID)
+	assert.Error(t, err)
+	assert.Equal(t, "Performance", recorder.profile("machine1"))
+}
+
+func TestJobService_RevertTimeOfDay(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	job := &Job{ID: "revert-job", RevertAfter: "18:00:00", Schedule: Schedule{TimeZone: "UTC"}}
+
+	morning := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC), service.revertTime(job, morning))
+
+	evening := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 3, 18, 0, 0, 0, time.UTC), service.revertTime(job, evening))
+
+	job.RevertAfter = "30m"
+	assert.Equal(t, morning.Add(30*time.Minute), service.revertTime(job, morning))
+
+	// A revert time skipped by spring-forward runs right after the jump
+	berlin := loadTestLocation(t, "Europe/Berlin")
+	job = &Job{ID: "revert-job", RevertAfter: "02:30:00", Schedule: Schedule{TimeZone: "Europe/Berlin"}}
+	night := time.Date(2026, 3, 29, 1, 0, 0, 0, berlin)
+	expected := time.Date(2026, 3, 29, 3, 30, 0, 0, berlin)
+	assert.True(t, expected.Equal(service.revertTime(job, night)), "expected %s, got %s", expected, service.revertTime(job, night))
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxRolloutPause bounds Rollout.PauseBetweenBatches
+const MaxRolloutPause = time.Hour
+
+// Rollout spreads a job execution over batches of machines instead of running
+// every machine at once. The optional canary batch runs first and any failure
+// in it aborts the rollout; afterwards the failure thresholds decide. Machines
+// of batches that never ran are reported as Skipped.
+type Rollout struct {
+	BatchSize               int    `json:"BatchSize,omitempty"`               // Machines per batch
+	BatchPercent            int    `json:"BatchPercent,omitempty"`            // Machines per batch as a percentage of all machines (1-100), rounded up
+	CanarySize              int    `json:"CanarySize,omitempty"`              // Machines in the canary batch that runs first (0 = no canary)
+	PauseBetweenBatches     string `json:"PauseBetweenBatches,omitempty"`     // Wait after each batch, e.g. "30s"
+	FailureThreshold        int    `json:"FailureThreshold,omitempty"`        // Abort once this many machines failed (0 = never)
+	FailureThresholdPercent int    `json:"FailureThresholdPercent,omitempty"` // Abort once this percentage of all machines failed (0 = never)
+}
+
+// Validate validates the rollout and returns all problems found
+func (r *Rollout) Validate() []string {
+	var errors []string
+
+	if r.BatchSize == 0 && r.BatchPercent == 0 && r.CanarySize == 0 {
+		errors = append(errors, "Rollout requires BatchSize, BatchPercent or CanarySize")
+	}
+	if r.BatchSize != 0 && r.BatchPercent != 0 {
+		errors = append(errors, "Rollout.BatchSize and Rollout.BatchPercent are mutually exclusive, set only one")
+	}
+	if r.BatchSize < 0 {
+		errors = append(errors, fmt.Sprintf("Rollout.BatchSize must not be negative, got %d", r.BatchSize))
+	}
+	if r.BatchPercent < 0 || r.BatchPercent > 100 {
+		errors = append(errors, fmt.Sprintf("Rollout.BatchPercent must be between 1 and 100, got %d", r.BatchPercent))
+	}
+	if r.CanarySize < 0 {
+		errors = append(errors, fmt.Sprintf("Rollout.CanarySize must not be negative, got %d", r.CanarySize))
+	}
+
+	if r.PauseBetweenBatches != "" {
+		pause, err := time.ParseDuration(r.PauseBetweenBatches)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("invalid Rollout.PauseBetweenBatches '%s': %v. Use a Go duration such as '30s' or '5m'", r.PauseBetweenBatches, err))
+		} else if pause < 0 || pause > MaxRolloutPause {
+			errors = append(errors, fmt.Sprintf("Rollout.PauseBetweenBatches must be between 0 and %s, got %s", MaxRolloutPause, r.PauseBetweenBatches))
+		}
+	}
+
+	if r.FailureThreshold < 0 {
+		errors = append(errors, fmt.Sprintf("Rollout.FailureThreshold must not be negative, got %d", r.FailureThreshold))
+	}
+	if r.FailureThresholdPercent < 0 || r.FailureThresholdPercent > 100 {
+		errors = append(errors, fmt.Sprintf("Rollout.FailureThresholdPercent must be between 1 and 100, got %d", r.FailureThresholdPercent))
+	}
+
+	return errors
+}
+
+// batches splits machine indexes 0..count-1 into rollout batches. A nil
+// rollout runs every machine in a single batch.
+func (r *Rollout) batches(count int) [][]int {
+	var batches [][]int
+	next := 0
+
+	take := func(size int) {
+		if size < 1 || next+size > count {
+			size = count - next
+		}
+		batch := make([]int, size)
+		for i := range batch {
+			batch[i] = next + i
+		}
+		batches = append(batches, batch)
+		next += size
+	}
+
+	if r != nil && r.CanarySize > 0 && count > 0 {
+		take(r.CanarySize)
+	}
+
+	size := 0
+	if r != nil {
+		size = r.BatchSize
+		if r.BatchPercent > 0 {
+			size = (count*r.BatchPercent + 99) / 100
+		}
+	}
+	for next < count {
+		take(size)
+	}
+
+	return batches
+}
+
+// hasCanary reports whether the first batch is a canary batch
+func (r *Rollout) hasCanary() bool {
+	return r != nil && r.CanarySize > 0
+}
+
+// pause returns the wait between two batches
+func (r *Rollout) pause() time.Duration {
+	if r == nil || r.PauseBetweenBatches == "" {
+		return 0
+	}
+	pause, err := time.ParseDuration(r.PauseBetweenBatches)
+	if err != nil {
+		return 0
+	}
+	return pause
+}
+
+// thresholdReached reports whether failed machines out of count abort the rollout
+func (r *Rollout) thresholdReached(failed, count int) bool {
+	if r == nil || failed == 0 {
+		return false
+	}
+	if r.FailureThreshold > 0 && failed >= r.FailureThreshold {
+		return true
+	}
+	return r.FailureThresholdPercent > 0 && failed*100 >= r.FailureThresholdPercent*count
+}
@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	extendprovider "multifish/providers/extend"
+)
+
+// newRolloutTestJob returns a PatchProfile job on count machines named server-1..server-N
+func newRolloutTestJob(count int, rollout *Rollout) *Job {
+	machines := make([]string, count)
+	for i := range machines {
+		machines[i] = fmt.Sprintf("server-%d", i+1)
+	}
+	return &Job{
+		ID:       "rollout-job",
+		Machines: machines,
+		Action:   ActionPatchProfile,
+		Payload: []ExecutePatchProfilePayload{
+			{ManagerID: "bmc", Payload: extendprovider.PatchProfileType{Profile: "Performance"}},
+		},
+		Rollout: rollout,
+	}
+}
+
+// newRolloutTestExecutor fails the given machines and records the machines executed
+func newRolloutTestExecutor(failing ...string) (*PlatformExecutor, func() []string) {
+	var mu sync.Mutex
+	var executed []string
+
+	platformMgr := &MockJobPlatformManager{
+		GetMachineFunc: func(machineID string) (interface{}, error) {
+			return machineID, nil
+		},
+	}
+	actionExecutor := &MockActionExecutor{
+		ExecutePatchProfileFunc: func(machine interface{}, payloads Payload) error {
+			mu.Lock()
+			executed = append(executed, machine.(string))
+			mu.Unlock()
+			for _, machineID := range failing {
+				if machine == machineID {
+					return errors.New("profile rejected")
+				}
+			}
+			return nil
+		},
+	}
+
+	return NewPlatformExecutor(platformMgr, actionExecutor), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), executed...)
+	}
+}
+
+func TestRollout_Batches(t *testing.T) {
+	tests := []struct {
+		name     string
+		rollout  *Rollout
+		count    int
+		expected [][]int
+	}{
+		{"No rollout", nil, 3, [][]int{{0, 1, 2}}},
+		{"Batch size", &Rollout{BatchSize: 2}, 5, [][]int{{0, 1}, {2, 3}, {4}}},
+		{"Batch percent rounds up", &Rollout{BatchPercent: 30}, 5, [][]int{{0, 1}, {2, 3}, {4}}},
+		{"Canary then rest", &Rollout{CanarySize: 1}, 4, [][]int{{0}, {1, 2, 3}}},
+		{"Canary then batches", &Rollout{CanarySize: 1, BatchSize: 2}, 6, [][]int{{0}, {1, 2}, {3, 4}, {5}}},
+		{"Canary larger than fleet", &Rollout{CanarySize: 5}, 2, [][]int{{0, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.rollout.batches(tt.count))
+		})
+	}
+}
+
+func TestRollout_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		rollout       Rollout
+		expectedValid bool
+	}{
+		{"Batch size", Rollout{BatchSize: 10}, true},
+		{"Canary with thresholds", Rollout{CanarySize: 1, BatchPercent: 25, PauseBetweenBatches: "30s", FailureThreshold: 2, FailureThresholdPercent: 10}, true},
+		{"Empty", Rollout{}, false},
+		{"Size and percent", Rollout{BatchSize: 2, BatchPercent: 50}, false},
+		{"Percent too large", Rollout{BatchPercent: 150}, false},
+		{"Negative canary", Rollout{CanarySize: -1, BatchSize: 2}, false},
+		{"Invalid pause", Rollout{BatchSize: 2, PauseBetweenBatches: "a while"}, false},
+		{"Pause too long", Rollout{BatchSize: 2, PauseBetweenBatches: "2h"}, false},
+		{"Negative threshold", Rollout{BatchSize: 2, FailureThreshold: -1}, false},
+		{"Threshold percent too large", Rollout{BatchSize: 2, FailureThresholdPercent: 101}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.rollout.Validate()
+			assert.Equal(t, tt.expectedValid, len(errs) == 0, "errors: %v", errs)
+		})
+	}
+
+	// Invalid rollouts fail job validation as action errors
+	request := newTestJobRequest()
+	request.Rollout = &Rollout{}
+	result := request.Validate()
+	assert.False(t, result.Valid)
+	assert.False(t, result.ActionValid)
+}
+
+func TestPlatformExecutor_RolloutCompletesAllBatches(t *testing.T) {
+	executor, executed := newRolloutTestExecutor()
+
+	history := executor.ExecuteJob(context.Background(), newRolloutTestJob(5, &Rollout{CanarySize: 1, BatchSize: 2}))
+
+	assert.Len(t, executed(), 5)
+	assert.Empty(t, history.Message)
+	assert.Equal(t, []int{1, 2, 2, 3, 3}, resultBatches(history))
+	assert.Equal(t, JobStatusCompleted, executionStatus(history.Results))
+}
+
+func TestPlatformExecutor_RolloutCanaryFailureSkipsRemainingBatches(t *testing.T) {
+	executor, executed := newRolloutTestExecutor("server-1")
+
+	history := executor.ExecuteJob(context.Background(), newRolloutTestJob(4, &Rollout{CanarySize: 1, BatchSize: 2}))
+
+	assert.Equal(t, []string{"server-1"}, executed())
+	assert.Contains(t, history.Message, "Rollout aborted after batch 1 of 3")
+	assert.Contains(t, history.Message, "canary")
+
+	assert.Equal(t, JobStatusFailed, history.Results[0].Status)
+	for _, result := range history.Results[1:] {
+		assert.Equal(t, JobStatusSkipped, result.Status, result.MachineID)
+		assert.False(t, result.Success)
+		assert.Contains(t, result.Message, "rollout aborted")
+	}
+	assert.Equal(t, JobStatusFailed, executionStatus(history.Results))
+}
+
+func TestPlatformExecutor_RolloutFailureThreshold(t *testing.T) {
+	// One failure per batch of two, the threshold of two is reached after batch 2
+	executor, executed := newRolloutTestExecutor("server-1", "server-3", "server-5")
+
+	history := executor.ExecuteJob(context.Background(), newRolloutTestJob(6, &Rollout{BatchSize: 2, FailureThreshold: 2}))
+
+	assert.Len(t, executed(), 4)
+	assert.Contains(t, history.Message, "Rollout aborted after batch 2 of 3")
+	assert.Equal(t, JobStatusSkipped, history.Results[4].Status)
+	assert.Equal(t, JobStatusSkipped, history.Results[5].Status)
+
+	// Without a threshold a failing batch does not stop the rollout
+	executor, executed = newRolloutTestExecutor("server-1", "server-3", "server-5")
+	history = executor.ExecuteJob(context.Background(), newRolloutTestJob(6, &Rollout{BatchSize: 2}))
+	assert.Len(t, executed(), 6)
+	assert.Empty(t, history.Message)
+
+	// 50% of six machines
+	executor, executed = newRolloutTestExecutor("server-1", "server-2", "server-3")
+	history = executor.ExecuteJob(context.Background(), newRolloutTestJob(6, &Rollout{BatchPercent: 50, FailureThresholdPercent: 50}))
+	assert.Len(t, executed(), 3)
+	assert.Contains(t, history.Message, "3 of 6 machines failed")
+}
+
+func TestPlatformExecutor_RolloutCancelledBetweenBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	platformMgr := &MockJobPlatformManager{}
+	actionExecutor := &MockActionExecutor{
+		ExecutePatchProfileFunc: func(machine interface{}, payloads Payload) error {
+			cancel()
+			return nil
+		},
+	}
+	executor := NewPlatformExecutor(platformMgr, actionExecutor)
+
+	history := executor.ExecuteJob(ctx, newRolloutTestJob(3, &Rollout{BatchSize: 1, PauseBetweenBatches: "1h"}))
+
+	assert.True(t, history.Results[0].Success)
+	assert.Equal(t, JobStatusCancelled, history.Results[1].Status)
+	assert.Equal(t, JobStatusCancelled, history.Results[2].Status)
+	assert.Equal(t, JobStatusCancelled, executionStatus(history.Results))
+}
+
+// resultBatches returns the rollout batch of every machine result
+func resultBatches(history *ExecutionHistory) []int {
+	batches := make([]int, len(history.Results))
+	for i, result := range history.Results {
+		batches[i] = result.Batch
+	}
+	return batches
+}
+
+func TestJobService_CreateJobWithRollout(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Rollout = &Rollout{CanarySize: 1, BatchPercent: 20}
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+	require.NotNil(t, job.Rollout)
+	assert.Equal(t, 20, job.Rollout.BatchPercent)
+}
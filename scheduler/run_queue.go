@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+)
+
+// MisfirePolicy decides what happens to a scheduled run that waited in the run
+// queue for longer than the misfire threshold
+type MisfirePolicy string
+
+const (
+	MisfirePolicyRun      MisfirePolicy = "Run"      // Execute it, and catch up on the runs that fell due meanwhile
+	MisfirePolicyCoalesce MisfirePolicy = "Coalesce" // Execute it once, runs that fell due meanwhile are folded into it
+	MisfirePolicyDrop     MisfirePolicy = "Drop"     // Do not execute it, wait for the next scheduled run
+)
+
+// ValidMisfirePolicies lists the accepted misfire policies
+var ValidMisfirePolicies = []string{string(MisfirePolicyRun), string(MisfirePolicyCoalesce), string(MisfirePolicyDrop)}
+
+const (
+	// DefaultMisfirePolicy keeps late runs but does not replay missed ones
+	DefaultMisfirePolicy = MisfirePolicyCoalesce
+	// DefaultMisfireThreshold is how late a run may start before it counts as misfired
+	DefaultMisfireThreshold = time.Minute
+
+	// MinJobPriority and MaxJobPriority bound Job.Priority, higher runs first
+	MinJobPriority = 0
+	MaxJobPriority = 100
+)
+
+// ValidateMisfirePolicy returns an error for an unknown misfire policy
+func ValidateMisfirePolicy(policy MisfirePolicy) error {
+	for _, valid := range ValidMisfirePolicies {
+		if string(policy) == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid MisfirePolicy: %s (must be 'Run', 'Coalesce' or 'Drop')", policy)
+}
+
+// QueueStats describes the run queue
+type QueueStats struct {
+	Depth       int           // Runs waiting for a worker
+	OldestWait  time.Duration // How long the oldest queued run has been waiting
+	AverageWait time.Duration // Average time dispatched runs spent in the queue
+	MaxWait     time.Duration // Longest time a dispatched run spent in the queue
+	Dispatched  int           // Runs handed to a worker since the service started
+	Misfires    int           // Scheduled runs that waited longer than the misfire threshold
+}
+
+// queuedRun is a job execution waiting for a worker
+type queuedRun struct {
+	job          *Job
+	priority     int
+	seq          uint64    // Enqueue order, FIFO within a priority
+	dueTime      time.Time // When the run was scheduled, zero for manual and triggered runs
+	enqueuedTime time.Time
+	manual       bool
+	triggeredBy  string   // Parent job whose execution triggered the run
+	machines     []string // Machines of a triggered run, nil runs all machines of the job
+	index        int
+}
+
+// scheduled reports whether the run was started by the job's own schedule
+func (r *queuedRun) scheduled() bool {
+	return !r.manual && r.triggeredBy == ""
+}
+
+// runHeap orders queued runs by priority (highest first), then by enqueue order
+type runHeap []*queuedRun
+
+func (h runHeap) Len() int { return len(h) }
+
+func (h runHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h runHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *runHeap) Push(x interface{}) {
+	run := x.(*queuedRun)
+	run.index = len(*h)
+	*h = append(*h, run)
+}
+
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	run := old[n-1]
+	old[n-1] = nil
+	run.index = -1
+	*h = old[:n-1]
+	return run
+}
+
+// runQueue holds at most one pending run per job (guarded by JobService.mu)
+type runQueue struct {
+	runs  runHeap
+	byJob map[string]*queuedRun
+	seq   uint64
+
+	dispatched int
+	totalWait  time.Duration
+	maxWait    time.Duration
+	misfires   int
+}
+
+func newRunQueue() *runQueue {
+	return &runQueue{byJob: make(map[string]*queuedRun)}
+}
+
+// push queues a run of the job, it returns false if the job is already queued
+func (q *runQueue) push(job *Job, dueTime time.Time, manual bool, now time.Time) bool {
+	if _, queued := q.byJob[job.ID]; queued {
+		return false
+	}
+	q.enqueue(&queuedRun{job: job, dueTime: dueTime, manual: manual}, now)
+	return true
+}
+
+// pushTriggered queues a run of a dependent job on the given machines, it
+// returns false if the job is already queued
+func (q *runQueue) pushTriggered(job *Job, parentID string, machines []string, now time.Time) bool {
+	if _, queued := q.byJob[job.ID]; queued {
+		return false
+	}
+	q.enqueue(&queuedRun{job: job, triggeredBy: parentID, machines: machines}, now)
+	return true
+}
+
+func (q *runQueue) enqueue(run *queuedRun, now time.Time) {
+	q.seq++
+	run.priority = run.job.Priority
+	run.seq = q.seq
+	run.enqueuedTime = now
+	heap.Push(&q.runs, run)
+	q.byJob[run.job.ID] = run
+}
+
+// pop removes the next run to dispatch and records its wait
+func (q *runQueue) pop(now time.Time) *queuedRun {
+	run := heap.Pop(&q.runs).(*queuedRun)
+	delete(q.byJob, run.job.ID)
+
+	wait := now.Sub(run.enqueuedTime)
+	q.dispatched++
+	q.totalWait += wait
+	if wait > q.maxWait {
+		q.maxWait = wait
+	}
+	return run
+}
+
+// remove discards the queued run of a job, if any
+func (q *runQueue) remove(jobID string) {
+	if run, queued := q.byJob[jobID]; queued {
+		heap.Remove(&q.runs, run.index)
+		delete(q.byJob, jobID)
+	}
+}
+
+func (q *runQueue) contains(jobID string) bool {
+	_, queued := q.byJob[jobID]
+	return queued
+}
+
+func (q *runQueue) len() int {
+	return len(q.runs)
+}
+
+func (q *runQueue) stats(now time.Time) QueueStats {
+	stats := QueueStats{
+		Depth:      len(q.runs),
+		MaxWait:    q.maxWait,
+		Dispatched: q.dispatched,
+		Misfires:   q.misfires,
+	}
+	if q.dispatched > 0 {
+		stats.AverageWait = q.totalWait / time.Duration(q.dispatched)
+	}
+	for _, run := range q.runs {
+		if wait := now.Sub(run.enqueuedTime); wait > stats.OldestWait {
+			stats.OldestWait = wait
+		}
+	}
+	return stats
+}
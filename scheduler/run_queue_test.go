@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunQueue_PriorityThenFIFO(t *testing.T) {
+	queue := newRunQueue()
+	now := time.Now()
+
+	for _, job := range []*Job{
+		{ID: "low-1", Priority: 0},
+		{ID: "high-1", Priority: 50},
+		{ID: "low-2", Priority: 0},
+		{ID: "high-2", Priority: 50},
+		{ID: "urgent", Priority: 100},
+	} {
+		require.True(t, queue.push(job, now, false, now))
+	}
+
+	// A job is queued at most once
+	assert.False(t, queue.push(&Job{ID: "low-1"}, now, false, now))
+	assert.Equal(t, 5, queue.len())
+
+	var order []string
+	for queue.len() > 0 {
+		order = append(order, queue.pop(now).job.ID)
+	}
+	assert.Equal(t, []string{"urgent", "high-1", "high-2", "low-1", "low-2"}, order)
+}
+
+func TestRunQueue_RemoveAndStats(t *testing.T) {
+	queue := newRunQueue()
+	start := time.Now()
+
+	queue.push(&Job{ID: "a"}, start, false, start)
+	queue.push(&Job{ID: "b"}, start, false, start.Add(time.Second))
+	queue.push(&Job{ID: "c"}, start, false, start.Add(2*time.Second))
+
+	queue.remove("b")
+	queue.remove("missing")
+	assert.False(t, queue.contains("b"))
+	assert.Equal(t, 2, queue.len())
+
+	stats := queue.stats(start.Add(4 * time.Second))
+	assert.Equal(t, 2, stats.Depth)
+	assert.Equal(t, 4*time.Second, stats.OldestWait)
+	assert.Zero(t, stats.Dispatched)
+
+	assert.Equal(t, "a", queue.pop(start.Add(4*time.Second)).job.ID)
+	assert.Equal(t, "c", queue.pop(start.Add(4*time.Second)).job.ID)
+
+	stats = queue.stats(start.Add(5 * time.Second))
+	assert.Zero(t, stats.Depth)
+	assert.Zero(t, stats.OldestWait)
+	assert.Equal(t, 2, stats.Dispatched)
+	assert.Equal(t, 3*time.Second, stats.AverageWait)
+	assert.Equal(t, 4*time.Second, stats.MaxWait)
+}
+
+func TestJobService_QueuesDueJobsWhenPoolFull(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	executor := &MockJobExecutor{ExecuteJobFunc: func(job *Job) *ExecutionHistory {
+		mu.Lock()
+		order = append(order, job.ID)
+		mu.Unlock()
+		return &ExecutionHistory{JobID: job.ID, ExecutionTime: time.Now(), Results: []MachineExecutionResult{{MachineID: "machine1", Success: true}}}
+	}}
+	service := NewJobService(&MockJobValidator{}, executor)
+	defer service.Stop()
+	require.NoError(t, service.SetWorkerPoolSize(1))
+
+	low, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+	request := newTestJobRequest()
+	request.Priority = 10
+	high, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	// Occupy the only worker, due jobs wait in the queue instead of being skipped
+	service.workerPool <- struct{}{}
+	makeJobDue(service, low)
+	makeJobDue(service, high)
+	service.checkAndExecuteJobs()
+
+	stats := service.GetQueueStats()
+	assert.Equal(t, 2, stats.Depth)
+
+	// Queued jobs are not queued twice on the next tick
+	service.checkAndExecuteJobs()
+	assert.Equal(t, 2, service.GetQueueStats().Depth)
+
+	// Releasing the worker dispatches the queue, highest priority first
+	<-service.workerPool
+	service.mu.Lock()
+	service.dispatchQueue(time.Now())
+	service.mu.Unlock()
+
+	waitForExecutions(t, service, low.ID, 1)
+	waitForExecutions(t, service, high.ID, 1)
+
+	mu.Lock()
+	assert.Equal(t, []string{high.ID, low.ID}, order)
+	mu.Unlock()
+
+	stats = service.GetQueueStats()
+	assert.Zero(t, stats.Depth)
+	assert.Equal(t, 2, stats.Dispatched)
+}
+
+func TestJobService_QueuedRunRemovedOnPause(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+	require.NoError(t, service.SetWorkerPoolSize(1))
+
+	job, _, err := service.CreateJob(newTestJobRequest())
+	require.NoError(t, err)
+
+	service.workerPool <- struct{}{}
+	makeJobDue(service, job)
+	service.checkAndExecuteJobs()
+	assert.Equal(t, 1, service.GetQueueStats().Depth)
+
+	require.NoError(t, service.PauseJob(job.ID))
+	assert.Zero(t, service.GetQueueStats().Depth)
+	<-service.workerPool
+}
+
+// newMisfireTestJob creates an interval job whose run fell due five minutes ago
+func newMisfireTestJob(t *testing.T, service *JobService, policy MisfirePolicy) *Job {
+	request := newTestJobRequest()
+	request.Schedule = Schedule{Type: ScheduleTypeInterval, Interval: "1m"}
+	request.MisfirePolicy = policy
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	service.mu.Lock()
+	anchor := time.Now().Add(-10 * time.Minute)
+	due := time.Now().Add(-5 * time.Minute)
+	job.Schedule.StartTime = &anchor
+	job.NextRunTime = &due
+	service.schedule.set(job)
+	service.mu.Unlock()
+	return job
+}
+
+func TestJobService_MisfirePolicies(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+	require.NoError(t, service.SetMisfirePolicy(MisfirePolicyCoalesce, time.Minute))
+
+	dropped := newMisfireTestJob(t, service, MisfirePolicyDrop)
+	caughtUp := newMisfireTestJob(t, service, MisfirePolicyRun)
+	coalesced := newMisfireTestJob(t, service, "")
+
+	service.checkAndExecuteJobs()
+
+	// Drop records the run as skipped and moves on to the next future run
+	executions := waitForExecutions(t, service, dropped.ID, 1)
+	assert.Equal(t, JobStatusSkipped, executions[0].Status)
+	assert.Contains(t, executions[0].Message, "misfired")
+
+	// Run executes and schedules the runs that fell due meanwhile
+	waitForExecutions(t, service, caughtUp.ID, 1)
+	// Coalesce (the service default) executes once and skips to the future
+	waitForExecutions(t, service, coalesced.ID, 1)
+
+	require.Eventually(t, func() bool {
+		service.mu.RLock()
+		defer service.mu.RUnlock()
+		return caughtUp.Status == JobStatusPending && coalesced.Status == JobStatusPending
+	}, 5*time.Second, 10*time.Millisecond)
+
+	service.mu.RLock()
+	now := time.Now()
+	require.NotNil(t, dropped.NextRunTime)
+	assert.True(t, dropped.NextRunTime.After(now))
+	assert.Zero(t, dropped.ExecutionCount)
+	require.NotNil(t, caughtUp.NextRunTime)
+	assert.True(t, caughtUp.NextRunTime.Before(now), "catch-up run should already be due")
+	require.NotNil(t, coalesced.NextRunTime)
+	assert.True(t, coalesced.NextRunTime.After(now))
+	service.mu.RUnlock()
+
+	assert.Equal(t, 3, service.GetQueueStats().Misfires)
+}
+
+func TestJobService_SetMisfirePolicy(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	policy, threshold := service.GetMisfirePolicy()
+	assert.Equal(t, DefaultMisfirePolicy, policy)
+	assert.Equal(t, DefaultMisfireThreshold, threshold)
+
+	require.NoError(t, service.SetMisfirePolicy(MisfirePolicyDrop, 0))
+	policy, threshold = service.GetMisfirePolicy()
+	assert.Equal(t, MisfirePolicyDrop, policy)
+	assert.Zero(t, threshold)
+
+	assert.Error(t, service.SetMisfirePolicy("Skip", time.Minute))
+	assert.Error(t, service.SetMisfirePolicy(MisfirePolicyRun, -time.Second))
+}
+
+func TestJobCreateRequest_ValidateQueueing(t *testing.T) {
+	request := newTestJobRequest()
+	request.Priority = MaxJobPriority + 1
+	result := request.Validate()
+	assert.False(t, result.Valid)
+	assert.NotEmpty(t, result.ScheduleErrors)
+
+	request = newTestJobRequest()
+	request.MisfirePolicy = "Later"
+	assert.False(t, request.Validate().Valid)
+
+	request = newTestJobRequest()
+	request.Priority = MaxJobPriority
+	request.MisfirePolicy = MisfirePolicyDrop
+	assert.True(t, request.Validate().Valid)
+}
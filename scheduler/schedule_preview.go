@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultPreviewCount is the number of run times a schedule preview returns
+	DefaultPreviewCount = 10
+	// MaxPreviewCount bounds the run times of a schedule preview
+	MaxPreviewCount = 100
+)
+
+// SchedulePreview lists the next run times of a schedule
+type SchedulePreview struct {
+	Schedule      Schedule    `json:"Schedule"`
+	TimeZone      string      `json:"TimeZone"`      // Zone the run times are computed in
+	ReferenceTime time.Time   `json:"ReferenceTime"` // Run times are after this time
+	RunTimes      []time.Time `json:"RunTimes"`      // Fewer than requested when the schedule ends
+}
+
+// PreviewSchedule returns the next count run times of schedule after
+// reference, computed by the scheduler as for a job created at reference. A
+// non-empty timeZone replaces the schedule's TimeZone, a zero reference uses
+// the service clock and a count of 0 returns DefaultPreviewCount run times.
+// The schedule is validated like a job's; an invalid schedule returns the
+// validation response and an error.
+func (js *JobService) PreviewSchedule(schedule Schedule, timeZone string, reference time.Time, count int) (*SchedulePreview, *JobValidationResponse, error) {
+	if timeZone != "" {
+		schedule.TimeZone = timeZone
+	}
+	if reference.IsZero() {
+		reference = js.clock.Now()
+	}
+	if count == 0 {
+		count = DefaultPreviewCount
+	}
+
+	req := &JobCreateRequest{Schedule: schedule}
+	validationResp := &JobValidationResponse{
+		Valid:         true,
+		ScheduleValid: true,
+		ActionValid:   true,
+		PayloadValid:  true,
+	}
+	errors := req.validateSchedule(reference)
+	if count < 0 || count > MaxPreviewCount {
+		errors = append(errors, fmt.Sprintf("Count must be between 1 and %d, got %d", MaxPreviewCount, count))
+	}
+	if len(errors) > 0 {
+		validationResp.Valid = false
+		validationResp.ScheduleValid = false
+		validationResp.ScheduleErrors = errors
+		validationResp.Message = "Schedule validation failed"
+		return nil, validationResp, fmt.Errorf("schedule validation failed")
+	}
+	validationResp.Message = "Schedule validation successful"
+
+	// Validated above, so the location loads
+	loc, _ := schedule.Location()
+	job := &Job{
+		ID:          "(preview)",
+		Schedule:    schedule,
+		Status:      JobStatusPending,
+		CreatedTime: reference,
+	}
+
+	preview := &SchedulePreview{
+		Schedule:      schedule,
+		TimeZone:      loc.String(),
+		ReferenceTime: reference,
+		RunTimes:      []time.Time{},
+	}
+
+	// Interval jobs stop after MaxExecutions runs
+	remaining := -1
+	if schedule.Type == ScheduleTypeInterval && schedule.MaxExecutions > 0 {
+		remaining = schedule.MaxExecutions
+	}
+
+	next := js.calculateNextRunTimeFrom(job, reference)
+	for len(preview.RunTimes) < count && remaining != 0 && !next.IsZero() {
+		preview.RunTimes = append(preview.RunTimes, next)
+		if schedule.Type == ScheduleTypeOnce {
+			break
+		}
+		remaining--
+
+		following := js.calculateNextRunTimeFrom(job, next.Add(time.Nanosecond))
+		if !following.After(next) {
+			break
+		}
+		next = following
+	}
+
+	return preview, validationResp, nil
+}
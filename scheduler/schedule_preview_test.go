@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock is a Clock that only moves when the test sets it
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func TestJobService_PreviewSchedule(t *testing.T) {
+	// Monday 2026-03-02 07:00 UTC
+	clock := &testClock{now: time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)}
+	service := NewJobServiceWithClock(&MockJobValidator{}, &MockJobExecutor{}, nil, clock)
+	defer service.Stop()
+
+	weekly := Schedule{
+		Type:     ScheduleTypeContinuous,
+		Time:     "08:00:00",
+		Period:   &Period{DaysOfWeek: []DayOfWeek{Monday, Friday}},
+		TimeZone: "UTC",
+	}
+	preview, _, err := service.PreviewSchedule(weekly, "", time.Time{}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", preview.TimeZone)
+	assert.True(t, clock.Now().Equal(preview.ReferenceTime), "the service clock is the default reference")
+	require.Len(t, preview.RunTimes, 3)
+	assert.True(t, time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC).Equal(preview.RunTimes[0]))
+	assert.True(t, time.Date(2026, 3, 6, 8, 0, 0, 0, time.UTC).Equal(preview.RunTimes[1]))
+	assert.True(t, time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC).Equal(preview.RunTimes[2]))
+
+	// The time zone override keeps the wall-clock time across the DST change on 2026-03-08
+	preview, _, err = service.PreviewSchedule(weekly, "America/New_York", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", preview.TimeZone)
+	assert.True(t, time.Date(2026, 3, 6, 13, 0, 0, 0, time.UTC).Equal(preview.RunTimes[0]), "08:00 EST")
+	assert.True(t, time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC).Equal(preview.RunTimes[1]), "08:00 EDT")
+
+	// Interval schedules stop after MaxExecutions, Once schedules run once
+	start := clock.Now().Add(time.Hour)
+	preview, _, err = service.PreviewSchedule(Schedule{Type: ScheduleTypeInterval, Interval: "15m", StartTime: &start, MaxExecutions: 2}, "", time.Time{}, 0)
+	require.NoError(t, err)
+	require.Len(t, preview.RunTimes, 2)
+	assert.True(t, start.Equal(preview.RunTimes[0]))
+	assert.True(t, start.Add(15*time.Minute).Equal(preview.RunTimes[1]))
+
+	preview, _, err = service.PreviewSchedule(Schedule{Type: ScheduleTypeOnce, StartTime: &start}, "", time.Time{}, 0)
+	require.NoError(t, err)
+	require.Len(t, preview.RunTimes, 1)
+	assert.True(t, start.Equal(preview.RunTimes[0]))
+
+	// The schedule is validated against the reference time
+	_, result, err := service.PreviewSchedule(Schedule{Type: ScheduleTypeOnce, StartTime: &start}, "", start.Add(time.Minute), 0)
+	require.Error(t, err)
+	assert.False(t, result.ScheduleValid)
+	assert.Contains(t, result.ScheduleErrors[0], "is in the past")
+
+	_, result, err = service.PreviewSchedule(weekly, "Mars/Olympus", time.Time{}, MaxPreviewCount+1)
+	require.Error(t, err)
+	assert.Len(t, result.ScheduleErrors, 2)
+}
+
+func TestJobService_SchedulesByClock(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)}
+	executed := make(chan string, 1)
+	executor := &MockJobExecutor{
+		ExecuteJobFunc: func(job *Job) *ExecutionHistory {
+			executed <- job.ID
+			return &ExecutionHistory{JobID: job.ID, ExecutionTime: clock.Now()}
+		},
+	}
+	service := NewJobServiceWithClock(&MockJobValidator{}, executor, nil, clock)
+	defer service.Stop()
+
+	request := newTestJobRequest()
+	request.Schedule.TimeZone = "UTC"
+	job, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+	assert.True(t, clock.Now().Equal(job.CreatedTime))
+	assert.True(t, time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC).Equal(*job.NextRunTime))
+
+	// Nothing is due until the clock reaches the run time
+	service.checkAndExecuteJobs()
+	select {
+	case <-executed:
+		t.Fatal("job ran before its run time")
+	default:
+	}
+
+	clock.Set(time.Date(2026, 3, 2, 8, 0, 1, 0, time.UTC))
+	service.checkAndExecuteJobs()
+	select {
+	case jobID := <-executed:
+		assert.Equal(t, job.ID, jobID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not run once the clock reached its run time")
+	}
+
+	require.Eventually(t, func() bool {
+		stored, err := service.GetJob(job.ID)
+		return err == nil && stored.LastRunTime != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	stored, _ := service.GetJob(job.ID)
+	assert.True(t, time.Date(2026, 3, 6, 8, 0, 0, 0, time.UTC).Equal(*stored.NextRunTime))
+}
@@ -0,0 +1,365 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"multifish/utility"
+)
+
+const (
+	// DefaultConflictWindow is how close two runs of different jobs on the same
+	// resource must be to count as a conflict
+	DefaultConflictWindow = time.Minute
+	// DefaultConflictHorizon is how far ahead RejectConflicts checks a new job
+	DefaultConflictHorizon = 7 * 24 * time.Hour
+	// MaxScheduleRange bounds the range of a schedule listing and the conflict horizon
+	MaxScheduleRange = 31 * 24 * time.Hour
+	// MaxScheduledRunsPerJob bounds the runs listed for one job, so a short
+	// Interval job cannot flood a listing
+	MaxScheduledRunsPerJob = 1000
+	// MaxScheduleConflicts bounds the conflicts listed for one range
+	MaxScheduleConflicts = 1000
+	// maxConflictErrors bounds the conflicts reported when a job is rejected
+	maxConflictErrors = 10
+)
+
+// ErrInvalidScheduleRange is returned for a schedule listing with an invalid
+// range or conflict window
+var ErrInvalidScheduleRange = errors.New("invalid schedule range")
+
+// ScheduledRun is one upcoming run of a job
+type ScheduledRun struct {
+	JobID            string    `json:"JobId"`
+	JobName          string    `json:"JobName,omitempty"`
+	RunTime          time.Time `json:"RunTime"`
+	Action           string    `json:"Action"` // Action of the job, or "Workflow" for jobs with Steps
+	Machines         []string  `json:"Machines"`
+	Resources        []string  `json:"Resources"`                  // Machines, or "<machine>/Managers/<manager>", the run changes
+	BlackoutCalendar string    `json:"BlackoutCalendar,omitempty"` // Calendar the run falls into, it is skipped or deferred
+}
+
+// ScheduleConflict is a pair of runs of different jobs that change the same
+// resource within the conflict window
+type ScheduleConflict struct {
+	Resource  string         `json:"Resource"`
+	MachineID string         `json:"MachineId"`
+	ManagerID string         `json:"ManagerId,omitempty"`
+	Runs      []ScheduledRun `json:"Runs"`  // The two runs, earliest first
+	Apart     string         `json:"Apart"` // Time between the two runs
+}
+
+// ScheduleView lists the runs of all jobs in a time range and their conflicts
+type ScheduleView struct {
+	From               time.Time          `json:"From"`
+	To                 time.Time          `json:"To"`
+	ConflictWindow     time.Duration      `json:"-"`
+	Runs               []ScheduledRun     `json:"Runs"`
+	Conflicts          []ScheduleConflict `json:"Conflicts"`
+	TruncatedJobs      []string           `json:"TruncatedJobs,omitempty"` // Jobs with more than MaxScheduledRunsPerJob runs in the range
+	ConflictsTruncated bool               `json:"ConflictsTruncated,omitempty"`
+}
+
+// GetSchedule expands the schedules of all active jobs into their runs between
+// from and to, and flags runs of different jobs that change the same machine
+// or manager within window of each other. Paused and cancelled jobs, and jobs
+// that only run when their parents finish, have no scheduled runs.
+func (js *JobService) GetSchedule(from, to time.Time, window time.Duration) (*ScheduleView, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: 'to' (%s) must be after 'from' (%s)", ErrInvalidScheduleRange, to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	if to.Sub(from) > MaxScheduleRange {
+		return nil, fmt.Errorf("%w: the range must not exceed %s, got %s. Request a shorter range", ErrInvalidScheduleRange, MaxScheduleRange, to.Sub(from))
+	}
+	if window < 0 {
+		return nil, fmt.Errorf("%w: the conflict window must not be negative, got %s", ErrInvalidScheduleRange, window)
+	}
+
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	view := &ScheduleView{
+		From:           from,
+		To:             to,
+		ConflictWindow: window,
+		Runs:           []ScheduledRun{},
+	}
+	for _, job := range js.jobs {
+		runs, truncated := js.expandSchedule(job, from, to)
+		view.Runs = append(view.Runs, runs...)
+		if truncated {
+			view.TruncatedJobs = append(view.TruncatedJobs, job.ID)
+		}
+	}
+	sortScheduledRuns(view.Runs)
+	sort.Strings(view.TruncatedJobs)
+
+	view.Conflicts, view.ConflictsTruncated = findScheduleConflicts(view.Runs, window, "")
+	return view, nil
+}
+
+// GetConflictSettings returns the conflict window and how far ahead
+// RejectConflicts checks a job
+func (js *JobService) GetConflictSettings() (time.Duration, time.Duration) {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+	return js.conflictWindow, js.conflictHorizon
+}
+
+// SetConflictSettings updates the conflict window and how far ahead
+// RejectConflicts checks a job
+func (js *JobService) SetConflictSettings(window time.Duration, horizon time.Duration) error {
+	log := utility.GetLogger()
+
+	if window < 0 {
+		return fmt.Errorf("job service configuration failed: conflict window must not be negative, got %s. Configure conflict_window_seconds in config file", window)
+	}
+	if horizon <= 0 || horizon > MaxScheduleRange {
+		return fmt.Errorf("job service configuration failed: conflict horizon must be between 1s and %s, got %s. Configure conflict_horizon_days in config file", MaxScheduleRange, horizon)
+	}
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.conflictWindow = window
+	js.conflictHorizon = horizon
+
+	log.Info().
+		Dur("conflictWindow", window).
+		Dur("conflictHorizon", horizon).
+		Msg("Conflict detection settings updated")
+	return nil
+}
+
+// expandSchedule returns the runs of a job between from and to, at most
+// MaxScheduledRunsPerJob; truncated reports that more runs were left out
+// (caller holds js.mu)
+func (js *JobService) expandSchedule(job *Job, from, to time.Time) (runs []ScheduledRun, truncated bool) {
+	if job.Status == JobStatusCancelled || job.Status == JobStatusPaused || job.NextRunTime == nil {
+		return nil, false
+	}
+
+	// Interval jobs stop after MaxExecutions runs
+	remaining := -1
+	interval, _ := time.ParseDuration(job.Schedule.Interval)
+	if job.Schedule.Type == ScheduleTypeInterval && job.Schedule.MaxExecutions > 0 {
+		remaining = max(job.Schedule.MaxExecutions-job.ExecutionCount, 0)
+	}
+
+	next := *job.NextRunTime
+	if next.Before(from) && job.Schedule.Type != ScheduleTypeOnce {
+		// Jump to the range instead of stepping through the runs before it
+		jumped := js.calculateNextRunTimeFrom(job, from)
+		if remaining > 0 && interval > 0 && !jumped.IsZero() {
+			remaining = max(remaining-int(jumped.Sub(next)/interval), 0)
+		}
+		next = jumped
+	}
+
+	resources := jobResources(job)
+	for remaining != 0 && !next.IsZero() && !next.After(to) {
+		if !next.Before(from) {
+			if len(runs) == MaxScheduledRunsPerJob {
+				return runs, true
+			}
+			run := ScheduledRun{
+				JobID:     job.ID,
+				JobName:   job.Name,
+				RunTime:   next,
+				Action:    jobActionName(job),
+				Machines:  job.Machines,
+				Resources: resources,
+			}
+			if calendarID, _, blocked := js.activeBlackout(job, next); blocked {
+				run.BlackoutCalendar = calendarID
+			}
+			runs = append(runs, run)
+		}
+
+		if job.Schedule.Type == ScheduleTypeOnce {
+			break
+		}
+		remaining--
+
+		following := js.calculateNextRunTimeFrom(job, next.Add(time.Nanosecond))
+		if !following.After(next) {
+			break
+		}
+		next = following
+	}
+
+	return runs, false
+}
+
+// jobResources returns the resources a job changes: every manager its payloads
+// reference on each of its machines, or the machines themselves
+func jobResources(job *Job) []string {
+	managerIDs := job.lockManagerIDs()
+	seen := make(map[string]bool)
+	var resources []string
+	for _, machineID := range job.Machines {
+		if len(managerIDs) == 0 {
+			resources = append(resources, machineID)
+			continue
+		}
+		for _, managerID := range managerIDs {
+			resource := fmt.Sprintf("%s/Managers/%s", machineID, managerID)
+			if !seen[resource] {
+				seen[resource] = true
+				resources = append(resources, resource)
+			}
+		}
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// jobActionName names the action of a job in a schedule listing
+func jobActionName(job *Job) string {
+	if len(job.Steps) > 0 {
+		return "Workflow"
+	}
+	return string(job.Action)
+}
+
+// sortScheduledRuns orders runs by time, then by job ID
+func sortScheduledRuns(runs []ScheduledRun) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		if !runs[i].RunTime.Equal(runs[j].RunTime) {
+			return runs[i].RunTime.Before(runs[j].RunTime)
+		}
+		return runs[i].JobID < runs[j].JobID
+	})
+}
+
+// findScheduleConflicts returns the pairs of runs of different jobs that change
+// the same resource within window of each other, at most MaxScheduleConflicts.
+// runs must be sorted by time. Runs falling into a blackout are not checked.
+// When jobID is set only conflicts involving that job are returned.
+func findScheduleConflicts(runs []ScheduledRun, window time.Duration, jobID string) ([]ScheduleConflict, bool) {
+	byResource := make(map[string][]int)
+	for i, run := range runs {
+		if run.BlackoutCalendar != "" {
+			continue
+		}
+		for _, resource := range run.Resources {
+			byResource[resource] = append(byResource[resource], i)
+		}
+	}
+
+	resources := make([]string, 0, len(byResource))
+	for resource := range byResource {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	conflicts := []ScheduleConflict{}
+	for _, resource := range resources {
+		machineID, managerID, _ := strings.Cut(resource, "/Managers/")
+		indexes := byResource[resource]
+		for a := 0; a < len(indexes); a++ {
+			first := runs[indexes[a]]
+			for b := a + 1; b < len(indexes); b++ {
+				second := runs[indexes[b]]
+				apart := second.RunTime.Sub(first.RunTime)
+				if apart > window {
+					break
+				}
+				if first.JobID == second.JobID || (jobID != "" && first.JobID != jobID && second.JobID != jobID) {
+					continue
+				}
+				if len(conflicts) == MaxScheduleConflicts {
+					return conflicts, true
+				}
+				conflicts = append(conflicts, ScheduleConflict{
+					Resource:  resource,
+					MachineID: machineID,
+					ManagerID: managerID,
+					Runs:      []ScheduledRun{first, second},
+					Apart:     apart.String(),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Runs[0].RunTime.Before(conflicts[j].Runs[0].RunTime)
+	})
+	return conflicts, false
+}
+
+// validateConflicts expands the schedule a job request would have over the
+// conflict horizon and returns an error for each run that conflicts with
+// another job. jobID is empty for a new job (caller holds js.mu).
+func (js *JobService) validateConflicts(jobID string, req *JobCreateRequest) []string {
+	if len(req.DependsOn) > 0 {
+		// Dependent jobs have no schedule of their own
+		return nil
+	}
+
+	now := js.clock.Now()
+	candidate := &Job{
+		ID:             jobID,
+		Name:           req.Name,
+		Machines:       req.Machines,
+		Action:         req.Action,
+		Payload:        req.Payload,
+		Steps:          req.Steps,
+		Schedule:       req.Schedule,
+		Calendars:      req.Calendars,
+		BlackoutPolicy: req.BlackoutPolicy,
+		Status:         JobStatusPending,
+		CreatedTime:    now,
+	}
+	if existing, exists := js.jobs[jobID]; exists {
+		candidate.CreatedTime = existing.CreatedTime
+		candidate.ExecutionCount = existing.ExecutionCount
+	}
+	if candidate.ID == "" {
+		candidate.ID = "(new job)"
+	}
+	nextRun := js.calculateNextRunTimeFrom(candidate, now)
+	if nextRun.IsZero() {
+		return nil
+	}
+	candidate.NextRunTime = &nextRun
+
+	to := now.Add(js.conflictHorizon)
+	runs, _ := js.expandSchedule(candidate, now, to)
+	if len(runs) == 0 {
+		return nil
+	}
+	for id, job := range js.jobs {
+		if id == jobID {
+			continue
+		}
+		other, _ := js.expandSchedule(job, now, to)
+		runs = append(runs, other...)
+	}
+	sortScheduledRuns(runs)
+
+	conflicts, truncated := findScheduleConflicts(runs, js.conflictWindow, candidate.ID)
+	var errors []string
+	for _, conflict := range conflicts {
+		if len(errors) == maxConflictErrors {
+			break
+		}
+		own, other := conflict.Runs[0], conflict.Runs[1]
+		if own.JobID != candidate.ID {
+			own, other = other, own
+		}
+		errors = append(errors, fmt.Sprintf("run at %s conflicts with job '%s' at %s on '%s': %s apart, within the conflict window of %s",
+			own.RunTime.Format(time.RFC3339), other.JobID, other.RunTime.Format(time.RFC3339), conflict.Resource, conflict.Apart, js.conflictWindow))
+	}
+	if more := len(conflicts) - len(errors); more > 0 {
+		qualifier := ""
+		if truncated {
+			qualifier = "at least "
+		}
+		errors = append(errors, fmt.Sprintf("%s%d more conflicts. Use GET /JobService/Schedule to list them, move the schedule, or leave out RejectConflicts", qualifier, more))
+	}
+	return errors
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	extendprovider "multifish/providers/extend"
+)
+
+// newIntervalJobRequest returns a PatchProfile request on machine1/bmc that
+// runs every interval starting at start
+func newIntervalJobRequest(start time.Time, interval string, maxExecutions int) *JobCreateRequest {
+	request := newTestJobRequest()
+	request.Schedule = Schedule{
+		Type:          ScheduleTypeInterval,
+		Interval:      interval,
+		StartTime:     &start,
+		MaxExecutions: maxExecutions,
+	}
+	return request
+}
+
+func TestJobService_GetSchedule(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	now := time.Now().Truncate(time.Second)
+	start := now.Add(time.Hour)
+	hourly, _, err := service.CreateJob(newIntervalJobRequest(start, "1h", 3))
+	require.NoError(t, err)
+
+	// Runs 30 seconds after the first run of the hourly job, on the same manager
+	request := newIntervalJobRequest(start.Add(30*time.Second), "24h", 0)
+	request.Name = "Daily"
+	daily, _, err := service.CreateJob(request)
+	require.NoError(t, err)
+
+	view, err := service.GetSchedule(now, now.Add(12*time.Hour), time.Minute)
+	require.NoError(t, err)
+	require.Len(t, view.Runs, 4, "MaxExecutions limits the hourly job to 3 runs")
+	assert.Equal(t, hourly.ID, view.Runs[0].JobID)
+	assert.True(t, start.Equal(view.Runs[0].RunTime))
+	assert.Equal(t, daily.ID, view.Runs[1].JobID)
+	assert.Equal(t, []string{"machine1/Managers/bmc"}, view.Runs[1].Resources)
+	assert.Equal(t, string(ActionPatchProfile), view.Runs[1].Action)
+
+	require.Len(t, view.Conflicts, 1, "runs of the same job never conflict")
+	conflict := view.Conflicts[0]
+	assert.Equal(t, "machine1/Managers/bmc", conflict.Resource)
+	assert.Equal(t, "machine1", conflict.MachineID)
+	assert.Equal(t, "bmc", conflict.ManagerID)
+	assert.Equal(t, "30s", conflict.Apart)
+	assert.Equal(t, []string{hourly.ID, daily.ID}, []string{conflict.Runs[0].JobID, conflict.Runs[1].JobID})
+
+	// A narrower window has no conflicts
+	view, err = service.GetSchedule(now, now.Add(12*time.Hour), 10*time.Second)
+	require.NoError(t, err)
+	assert.Empty(t, view.Conflicts)
+
+	// Runs are listed from the start of the range, paused jobs have none
+	require.NoError(t, service.PauseJob(daily.ID))
+	view, err = service.GetSchedule(start.Add(90*time.Minute), now.Add(12*time.Hour), time.Minute)
+	require.NoError(t, err)
+	require.Len(t, view.Runs, 1)
+	assert.True(t, start.Add(2*time.Hour).Equal(view.Runs[0].RunTime))
+
+	_, err = service.GetSchedule(now, now, time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidScheduleRange)
+	_, err = service.GetSchedule(now, now.Add(MaxScheduleRange+time.Hour), time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidScheduleRange)
+	_, err = service.GetSchedule(now, now.Add(time.Hour), -time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidScheduleRange)
+}
+
+func TestJobService_RejectConflicts(t *testing.T) {
+	service := NewJobService(&MockJobValidator{}, &MockJobExecutor{})
+	defer service.Stop()
+
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	existing, _, err := service.CreateJob(newIntervalJobRequest(start, "1h", 0))
+	require.NoError(t, err)
+
+	request := newIntervalJobRequest(start.Add(20*time.Second), "24h", 0)
+	request.RejectConflicts = true
+	_, result, err := service.CreateJob(request)
+	require.Error(t, err)
+	assert.False(t, result.Valid)
+	require.NotEmpty(t, result.ScheduleErrors)
+	assert.Contains(t, result.ScheduleErrors[0], "conflicts with job '"+existing.ID+"'")
+	assert.Contains(t, result.ScheduleErrors[0], "on 'machine1/Managers/bmc': 20s apart")
+
+	// Other managers do not conflict
+	request.Payload = []ExecutePatchProfilePayload{{ManagerID: "bmc2", Payload: extendprovider.PatchProfileType{Profile: "Performance"}}}
+	_, _, err = service.CreateJob(request)
+	assert.NoError(t, err)
+
+	// Without RejectConflicts the job is accepted
+	request = newIntervalJobRequest(start.Add(20*time.Second), "24h", 0)
+	_, _, err = service.CreateJob(request)
+	assert.NoError(t, err)
+
+	require.NoError(t, service.SetConflictSettings(0, time.Hour))
+	assert.Error(t, service.SetConflictSettings(-time.Second, time.Hour))
+	assert.Error(t, service.SetConflictSettings(time.Minute, MaxScheduleRange+time.Hour))
+}
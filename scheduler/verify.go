@@ -0,0 +1,319 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	extendprovider "multifish/providers/extend"
+	"multifish/utility"
+)
+
+// DefaultVerifyTolerance is the difference allowed between a requested and an
+// applied number when no tolerance is set
+const DefaultVerifyTolerance = 0.01
+
+// VerificationError reports the fields a BMC accepted in a PATCH but did not
+// apply as requested
+type VerificationError struct {
+	Mismatches []string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("applied values differ from the requested values: %s", strings.Join(e.Mismatches, "; "))
+}
+
+// verifyTolerance returns the tolerance numbers of the job are verified with
+func (j *Job) verifyTolerance() float64 {
+	if j.VerifyTolerance == nil {
+		return DefaultVerifyTolerance
+	}
+	return *j.VerifyTolerance
+}
+
+// validateVerify validates the verification settings of a job
+func (j *JobCreateRequest) validateVerify() []string {
+	if j.VerifyTolerance == nil {
+		return nil
+	}
+
+	var errors []string
+	if !j.Verify {
+		errors = append(errors, "VerifyTolerance requires Verify to be true")
+	}
+	if *j.VerifyTolerance < 0 || math.IsNaN(*j.VerifyTolerance) {
+		errors = append(errors, fmt.Sprintf("VerifyTolerance must be 0 or greater, got %v", *j.VerifyTolerance))
+	}
+	return errors
+}
+
+// fanSettingsReader returns a function reading the fan settings of the
+// machine's managers, each manager fetched once
+func (dae *DefaultActionExecutor) fanSettingsReader(ctx context.Context, machine interface{}) func(managerID string) (*extendprovider.OpenBmcFan, error) {
+	settings := make(map[string]*extendprovider.OpenBmcFan)
+	return func(managerID string) (*extendprovider.OpenBmcFan, error) {
+		if fan, read := settings[managerID]; read {
+			return fan, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		manager, err := dae.machineExecutor.GetManagerByService(ctx, machine, managerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve manager service for manager '%s': %w. Verify machine connectivity and Redfish service availability", managerID, err)
+		}
+		fan, err := dae.machineExecutor.GetFanSettings(ctx, manager)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the fan settings of manager '%s': %w", managerID, err)
+		}
+		settings[managerID] = fan
+		return fan, nil
+	}
+}
+
+// VerifyApplied re-fetches the managers an action patched and compares the
+// applied values with the requested ones. Numbers may differ by tolerance.
+// Values that differ are reported as a *VerificationError; other errors mean
+// the values could not be read back.
+func (dae *DefaultActionExecutor) VerifyApplied(ctx context.Context, machine interface{}, action ActionType, payload Payload, tolerance float64) error {
+	log := utility.GetLogger()
+
+	fanSettings := dae.fanSettingsReader(ctx, machine)
+	var mismatches []string
+	compare := func(resource string, requested interface{}, applied interface{}) error {
+		found, err := appliedMismatches(requested, applied, tolerance)
+		if err != nil {
+			return err
+		}
+		for _, mismatch := range found {
+			mismatches = append(mismatches, fmt.Sprintf("%s %s", resource, mismatch))
+		}
+		return nil
+	}
+
+	switch action {
+	case ActionPatchManager:
+		payloads, ok := payload.([]ExecutePatchManagerPayload)
+		if !ok {
+			return fmt.Errorf("verification failed: invalid payload type for PatchManager action: expected []ExecutePatchManagerPayload, got %T", payload)
+		}
+		for _, mp := range payloads {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			manager, err := dae.machineExecutor.GetManagerByService(ctx, machine, mp.ManagerID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve manager service for manager '%s': %w. Verify machine connectivity and Redfish service availability", mp.ManagerID, err)
+			}
+			settings, err := dae.machineExecutor.GetManagerSettings(ctx, manager)
+			if err != nil {
+				return fmt.Errorf("failed to read the settings of manager '%s': %w", mp.ManagerID, err)
+			}
+			if err := compare(fmt.Sprintf("Manager '%s'", mp.ManagerID), mp.Payload, settings); err != nil {
+				return err
+			}
+		}
+
+	case ActionPatchProfile:
+		payloads, ok := payload.([]ExecutePatchProfilePayload)
+		if !ok {
+			return fmt.Errorf("verification failed: invalid payload type for PatchProfile action: expected []ExecutePatchProfilePayload, got %T", payload)
+		}
+		for _, mp := range payloads {
+			fan, err := fanSettings(mp.ManagerID)
+			if err != nil {
+				return err
+			}
+			if err := compare(fmt.Sprintf("Manager '%s'", mp.ManagerID), mp.Payload, fan); err != nil {
+				return err
+			}
+		}
+
+	case ActionPatchFanController:
+		payloads, ok := payload.([]ExecutePatchFanControllerPayload)
+		if !ok {
+			return fmt.Errorf("verification failed: invalid payload type for PatchFanController action: expected []ExecutePatchFanControllerPayload, got %T", payload)
+		}
+		for _, fp := range payloads {
+			fan, err := fanSettings(fp.ManagerID)
+			if err != nil {
+				return err
+			}
+			var applied *extendprovider.FanController
+			if fan.FanControllers != nil {
+				applied = fan.FanControllers.Items[fp.FanControllerID]
+			}
+			if applied == nil {
+				return fmt.Errorf("fan controller '%s' not found on manager '%s' after patching", fp.FanControllerID, fp.ManagerID)
+			}
+			if err := compare(fmt.Sprintf("FanController '%s'", fp.FanControllerID), fp.Payload, applied); err != nil {
+				return err
+			}
+		}
+
+	case ActionPatchFanZone:
+		payloads, ok := payload.([]ExecutePatchFanZonePayload)
+		if !ok {
+			return fmt.Errorf("verification failed: invalid payload type for PatchFanZone action: expected []ExecutePatchFanZonePayload, got %T", payload)
+		}
+		for _, fz := range payloads {
+			fan, err := fanSettings(fz.ManagerID)
+			if err != nil {
+				return err
+			}
+			var applied extendprovider.FanZone
+			found := false
+			if fan.FanZones != nil {
+				applied, found = fan.FanZones.Items[fz.FanZoneID]
+			}
+			if !found {
+				return fmt.Errorf("fan zone '%s' not found on manager '%s' after patching", fz.FanZoneID, fz.ManagerID)
+			}
+			if err := compare(fmt.Sprintf("FanZone '%s'", fz.FanZoneID), fz.Payload, applied); err != nil {
+				return err
+			}
+		}
+
+	case ActionPatchPidController:
+		payloads, ok := payload.([]ExecutePatchPidControllerPayload)
+		if !ok {
+			return fmt.Errorf("verification failed: invalid payload type for PatchPidController action: expected []ExecutePatchPidControllerPayload, got %T", payload)
+		}
+		for _, pc := range payloads {
+			fan, err := fanSettings(pc.ManagerID)
+			if err != nil {
+				return err
+			}
+			var applied *extendprovider.PidController
+			if fan.PidControllers != nil {
+				applied = fan.PidControllers.Items[pc.PidControllerID]
+			}
+			if applied == nil {
+				return fmt.Errorf("PID controller '%s' not found on manager '%s' after patching", pc.PidControllerID, pc.ManagerID)
+			}
+			if err := compare(fmt.Sprintf("PidController '%s'", pc.PidControllerID), pc.Payload, applied); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("verification failed: unsupported action '%s'", action)
+	}
+
+	if len(mismatches) > 0 {
+		log.Warn().
+			Str("action", string(action)).
+			Strs("mismatches", mismatches).
+			Msg("Applied values differ from the requested values")
+		return &VerificationError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// appliedMismatches compares the fields set in requested with the fields of
+// applied that have the same JSON name. Numbers match when they differ by no
+// more than tolerance, other values must be equal.
+func appliedMismatches(requested interface{}, applied interface{}, tolerance float64) ([]string, error) {
+	want, err := jsonFields(requested)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read requested values: %w", err)
+	}
+	got, err := jsonFields(applied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied values: %w", err)
+	}
+
+	names := make([]string, 0, len(want))
+	for name := range want {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var mismatches []string
+	for _, name := range names {
+		var wantValue, gotValue interface{}
+		if err := json.Unmarshal(want[name], &wantValue); err != nil {
+			return nil, fmt.Errorf("failed to read requested value of %s: %w", name, err)
+		}
+		raw, reported := got[name]
+		if !reported {
+			mismatches = append(mismatches, fmt.Sprintf("%s: requested %s, not reported by the BMC", name, want[name]))
+			continue
+		}
+		if err := json.Unmarshal(raw, &gotValue); err != nil {
+			return nil, fmt.Errorf("failed to read applied value of %s: %w", name, err)
+		}
+
+		wantNumber, wantIsNumber := wantValue.(float64)
+		gotNumber, gotIsNumber := gotValue.(float64)
+		if wantIsNumber && gotIsNumber {
+			if math.Abs(wantNumber-gotNumber) <= tolerance {
+				continue
+			}
+		} else if reflect.DeepEqual(wantValue, gotValue) {
+			continue
+		}
+		mismatches = append(mismatches, fmt.Sprintf("%s: requested %s, applied %s", name, want[name], raw))
+	}
+	return mismatches, nil
+}
+
+// jsonFields returns the fields of a value by their JSON names
+func jsonFields(value interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// verifyAction reads back the values an action applied to a machine and marks
+// the result VerificationFailed when they differ from the requested values or
+// cannot be read
+func (pe *PlatformExecutor) verifyAction(ctx context.Context, job *Job, machineID string, action ActionType, payload Payload, result *MachineExecutionResult) {
+	log := utility.GetLogger()
+
+	err := ctx.Err()
+	if err == nil {
+		var machine interface{}
+		machine, err = pe.platformMgr.GetMachine(machineID)
+		if err != nil {
+			err = fmt.Errorf("failed to get machine: %w", err)
+		} else {
+			err = pe.actionExecutor.VerifyApplied(ctx, machine, action, payload, job.verifyTolerance())
+		}
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime).String()
+	if err == nil {
+		result.Message = fmt.Sprintf("%s, applied values verified", result.Message)
+		return
+	}
+
+	result.Success = false
+	result.Status = JobStatusVerificationFailed
+	result.Error = err.Error()
+	result.Message = fmt.Sprintf("%s was accepted but the applied values could not be verified", action)
+	var mismatch *VerificationError
+	if errors.As(err, &mismatch) {
+		result.Message = fmt.Sprintf("%s was accepted but the applied values differ from the requested values", action)
+	}
+
+	log.Warn().
+		Err(err).
+		Str("jobID", job.ID).
+		Str("machineID", machineID).
+		Str("action", string(action)).
+		Msg("Verification of applied values failed")
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	extendprovider "multifish/providers/extend"
+	redfishprovider "multifish/providers/redfish"
+)
+
+func TestAppliedMismatches(t *testing.T) {
+	applied := extendprovider.FanZone{FailSafePercent: 79.996, MinThermalOutput: 25}
+
+	mismatches, err := appliedMismatches(extendprovider.PatchFanZoneType{FailSafePercent: float64Ptr(80)}, applied, DefaultVerifyTolerance)
+	require.NoError(t, err)
+	assert.Empty(t, mismatches, "numbers within the tolerance match")
+
+	mismatches, err = appliedMismatches(extendprovider.PatchFanZoneType{FailSafePercent: float64Ptr(80), MinThermalOutput: float64Ptr(30)}, applied, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"FailSafePercent: requested 80, applied 79.996",
+		"MinThermalOutput: requested 30, applied 25",
+	}, mismatches)
+
+	mismatches, err = appliedMismatches(extendprovider.PatchProfileType{Profile: "Performance"}, extendprovider.OpenBmcFan{Profile: "Balanced"}, DefaultVerifyTolerance)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`Profile: requested "Performance", applied "Balanced"`}, mismatches)
+
+	mismatches, err = appliedMismatches(extendprovider.PatchProfileType{Profile: "Performance"}, struct{}{}, DefaultVerifyTolerance)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`Profile: requested "Performance", not reported by the BMC`}, mismatches)
+}
+
+func TestDefaultActionExecutor_VerifyApplied(t *testing.T) {
+	machine := newFakeFanMachine()
+	machine.identification = "rack-1"
+	executor := NewDefaultActionExecutor(machine)
+	ctx := context.Background()
+
+	err := executor.VerifyApplied(ctx, "machine1", ActionPatchFanZone, []ExecutePatchFanZonePayload{
+		{ManagerID: "bmc", FanZoneID: "Zone1", Payload: extendprovider.PatchFanZoneType{FailSafePercent: float64Ptr(40.5)}},
+	}, 1)
+	assert.NoError(t, err)
+
+	err = executor.VerifyApplied(ctx, "machine1", ActionPatchFanZone, []ExecutePatchFanZonePayload{
+		{ManagerID: "bmc", FanZoneID: "Zone1", Payload: extendprovider.PatchFanZoneType{FailSafePercent: float64Ptr(80)}},
+	}, DefaultVerifyTolerance)
+	var mismatch *VerificationError
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{"FanZone 'Zone1' FailSafePercent: requested 80, applied 40"}, mismatch.Mismatches)
+
+	err = executor.VerifyApplied(ctx, "machine1", ActionPatchManager, []ExecutePatchManagerPayload{
+		{ManagerID: "bmc", Payload: redfishprovider.PatchManagerType{ServiceIdentification: "rack-2"}},
+	}, DefaultVerifyTolerance)
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{`Manager 'bmc' ServiceIdentification: requested "rack-2", applied "rack-1"`}, mismatch.Mismatches)
+
+	// Values that cannot be read back are not a mismatch
+	err = executor.VerifyApplied(ctx, "machine1", ActionPatchProfile, []ExecutePatchProfilePayload{
+		{ManagerID: "bmc2", Payload: extendprovider.PatchProfileType{Profile: "Balanced"}},
+	}, DefaultVerifyTolerance)
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &mismatch))
+}
+
+func TestPlatformExecutor_VerifiesAppliedValues(t *testing.T) {
+	var tolerances []float64
+	actions := &MockActionExecutor{
+		VerifyAppliedFunc: func(machine interface{}, action ActionType, payload Payload, tolerance float64) error {
+			tolerances = append(tolerances, tolerance)
+			return &VerificationError{Mismatches: []string{"Manager 'bmc' Profile: requested \"Custom\", applied \"Balanced\""}}
+		},
+	}
+	executor := NewPlatformExecutor(&MockJobPlatformManager{}, actions)
+
+	job := newWorkflowTestJob(profileStep("Custom"))
+	job.Steps = nil
+	job.Action = ActionPatchProfile
+	job.Payload = profileStep("Custom").Payload
+
+	// Without Verify the 2xx of the BMC is trusted
+	history := executor.ExecuteJob(context.Background(), job)
+	assert.True(t, history.Results[0].Success)
+	assert.Empty(t, tolerances)
+
+	job.Verify = true
+	history = executor.ExecuteJob(context.Background(), job)
+	result := history.Results[0]
+	assert.False(t, result.Success)
+	assert.Equal(t, JobStatusVerificationFailed, result.Status)
+	assert.Contains(t, result.Message, "applied values differ")
+	assert.Contains(t, result.Error, "requested \"Custom\", applied \"Balanced\"")
+	assert.Equal(t, JobStatusVerificationFailed, executionStatus(history.Results))
+
+	job.VerifyTolerance = float64Ptr(0.5)
+	actions.VerifyAppliedFunc = func(machine interface{}, action ActionType, payload Payload, tolerance float64) error {
+		tolerances = append(tolerances, tolerance)
+		return nil
+	}
+	history = executor.ExecuteJob(context.Background(), job)
+	assert.True(t, history.Results[0].Success)
+	assert.Contains(t, history.Results[0].Message, "applied values verified")
+	assert.Equal(t, []float64{DefaultVerifyTolerance, 0.5}, tolerances)
+}
+
+func TestExecutionStatus_VerificationFailed(t *testing.T) {
+	verificationFailed := MachineExecutionResult{Status: JobStatusVerificationFailed}
+	completed := MachineExecutionResult{Success: true, Status: JobStatusCompleted}
+	failed := MachineExecutionResult{Status: JobStatusFailed}
+
+	assert.Equal(t, JobStatusVerificationFailed, executionStatus([]MachineExecutionResult{completed, verificationFailed}))
+	assert.Equal(t, JobStatusFailed, executionStatus([]MachineExecutionResult{verificationFailed, failed}))
+	assert.Equal(t, JobStatusFailed, executionStatus([]MachineExecutionResult{failed, verificationFailed}))
+}
+
+func TestJobCreateRequest_ValidateVerify(t *testing.T) {
+	request := newTestJobRequest()
+	request.Verify = true
+	request.VerifyTolerance = float64Ptr(0.5)
+	result := request.Validate()
+	assert.True(t, result.Valid, "errors: %v", result.ActionErrors)
+
+	request.Verify = false
+	request.VerifyTolerance = float64Ptr(-1)
+	result = request.Validate()
+	assert.False(t, result.ActionValid)
+	assert.Equal(t, []string{"VerifyTolerance requires Verify to be true", "VerifyTolerance must be 0 or greater, got -1"}, result.ActionErrors)
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"multifish/utility"
+)
+
+// Backend names accepted by the storage_backend configuration option
+const (
+	BackendMemory = "memory"
+	BackendFile   = "file"
+)
+
+// ValidBackends lists all supported storage backends
+var ValidBackends = []string{BackendMemory, BackendFile}
+
+// FileStore is an embedded document store that keeps one JSON file per record.
+// Records are grouped into collections, each collection is a sub-directory of the
+// data directory. Writes are atomic (write to temp file, then rename) so a crash
+// never leaves a half-written record behind.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a file store rooted at dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	log := utility.GetLogger()
+
+	if dir == "" {
+		return nil, fmt.Errorf("storage initialization failed: data directory path cannot be empty. Configure 'data_dir' in config file")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Error().Err(err).Str("dir", dir).Msg("Failed to create data directory")
+		return nil, fmt.Errorf("failed to create data directory '%s': %w. Check filesystem permissions and available disk space", dir, err)
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Dir returns the root directory of the store
+func (fs *FileStore) Dir() string {
+	return fs.dir
+}
+
+// recordPath returns the file path of a record, escaping the key so that IDs
+// containing path separators cannot escape the collection directory
+func (fs *FileStore) recordPath(collection, key string) string {
+	return filepath.Join(fs.dir, collection, url.PathEscape(key)+".json")
+}
+
+// Put stores v as JSON under collection/key, replacing any existing record
+func (fs *FileStore) Put(collection, key string, v interface{}) error {
+	if key == "" {
+		return fmt.Errorf("storage write failed: record key cannot be empty (collection: %s)", collection)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize record '%s' in collection '%s': %w", key, collection, err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	collectionDir := filepath.Join(fs.dir, collection)
+	if err := os.MkdirAll(collectionDir, 0700); err != nil {
+		return fmt.Errorf("failed to create collection directory '%s': %w. Check filesystem permissions", collectionDir, err)
+	}
+
+	path := fs.recordPath(collection, key)
+	tmp, err := os.CreateTemp(collectionDir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file in '%s': %w. Check disk space and permissions", collectionDir, err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write record '%s' in collection '%s': %w. Check disk space", key, collection, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to flush record '%s' in collection '%s': %w", key, collection, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to close record '%s' in collection '%s': %w", key, collection, err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to commit record '%s' in collection '%s': %w", key, collection, err)
+	}
+
+	return nil
+}
+
+// Get loads the record stored under collection/key into v
+func (fs *FileStore) Get(collection, key string, v interface{}) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := os.ReadFile(fs.recordPath(collection, key))
+	if err != nil {
+		return fmt.Errorf("failed to read record '%s' in collection '%s': %w", key, collection, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse record '%s' in collection '%s': %w", key, collection, err)
+	}
+
+	return nil
+}
+
+// Delete removes the record stored under collection/key.
+// Deleting a record that does not exist is not an error.
+func (fs *FileStore) Delete(collection, key string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := os.Remove(fs.recordPath(collection, key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete record '%s' in collection '%s': %w. Check filesystem permissions", key, collection, err)
+	}
+
+	return nil
+}
+
+// List returns the raw JSON of every record in a collection, keyed by record key.
+// A missing collection is treated as empty.
+func (fs *FileStore) List(collection string) (map[string]json.RawMessage, error) {
+	log := utility.GetLogger()
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	records := make(map[string]json.RawMessage)
+
+	collectionDir := filepath.Join(fs.dir, collection)
+	entries, err := os.ReadDir(collectionDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, fmt.Errorf("failed to read collection directory '%s': %w", collectionDir, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		key, err := url.PathUnescape(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			log.Warn().Str("file", name).Msg("Skipping record with invalid file name")
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(collectionDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record '%s' in collection '%s': %w", key, collection, err)
+		}
+
+		if !json.Valid(data) {
+			log.Warn().Str("collection", collection).Str("key", key).Msg("Skipping corrupted record")
+			continue
+		}
+
+		records[key] = json.RawMessage(data)
+	}
+
+	return records, nil
+}
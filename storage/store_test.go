@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRecord struct {
+	ID    string `json:"Id"`
+	Value int    `json:"Value"`
+}
+
+func TestNewFileStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, store.Dir())
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
+
+func TestNewFileStore_EmptyDir(t *testing.T) {
+	_, err := NewFileStore("")
+	assert.Error(t, err)
+}
+
+func TestFileStore_PutGet(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("records", "rec-1", testRecord{ID: "rec-1", Value: 42}))
+
+	var got testRecord
+	require.NoError(t, store.Get("records", "rec-1", &got))
+	assert.Equal(t, testRecord{ID: "rec-1", Value: 42}, got)
+
+	// Overwrite existing record
+	require.NoError(t, store.Put("records", "rec-1", testRecord{ID: "rec-1", Value: 7}))
+	require.NoError(t, store.Get("records", "rec-1", &got))
+	assert.Equal(t, 7, got.Value)
+}
+
+func TestFileStore_PutEmptyKey(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	assert.Error(t, store.Put("records", "", testRecord{}))
+}
+
+func TestFileStore_KeyEscaping(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	key := "../rack/1"
+	require.NoError(t, store.Put("records", key, testRecord{ID: key}))
+
+	// The record must stay inside the collection directory
+	entries, err := os.ReadDir(filepath.Join(dir, "records"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	records, err := store.List("records")
+	require.NoError(t, err)
+	assert.Contains(t, records, key)
+}
+
+func TestFileStore_Delete(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("records", "rec-1", testRecord{ID: "rec-1"}))
+	require.NoError(t, store.Delete("records", "rec-1"))
+
+	var got testRecord
+	assert.Error(t, store.Get("records", "rec-1", &got))
+
+	// Deleting a missing record is not an error
+	assert.NoError(t, store.Delete("records", "rec-1"))
+}
+
+func TestFileStore_List(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	// Missing collection is empty
+	records, err := store.List("records")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	require.NoError(t, store.Put("records", "rec-1", testRecord{ID: "rec-1"}))
+	require.NoError(t, store.Put("records", "rec-2", testRecord{ID: "rec-2"}))
+	require.NoError(t, store.Put("other", "rec-3", testRecord{ID: "rec-3"}))
+
+	// Corrupted and foreign files are skipped
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "records", "broken.json"), []byte("{not json"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "records", "notes.txt"), []byte("hello"), 0600))
+
+	records, err = store.List("records")
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Contains(t, records, "rec-1")
+	assert.Contains(t, records, "rec-2")
+}